@@ -0,0 +1,179 @@
+package gochess
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// BitBoard is a standard 8x8 board backed by bitboards.
+//
+// Besides the square by square access of the Board, it keeps one 64-bit set
+// per colored piece and per color, so move generators can work with whole
+// sets of squares at once. Bit i of a set corresponds to the square with
+// BitIndex i: bit 0 is a1, bit 7 is h1 and bit 63 is h8.
+type BitBoard struct {
+	// pieces holds one set per colored piece, indexed by the Piece value.
+	pieces [Black | King + 1]uint64
+	// colors holds one set per color, indexed by colorIndex.
+	colors [2]uint64
+	// mailbox holds the piece of every square, indexed by BitIndex.
+	mailbox [64]Piece
+}
+
+// NewBitBoard creates a new bitboard.
+//
+// It receives an optional 8x8 2D array of pieces using the same layout as
+// NewBoard: the first row is the eighth rank and the first column is the
+// a-file. If no 2D array is provided, the board will be initialized with
+// empty squares.
+//
+// It returns ErrInvalidSquare if the squares are not 8x8 or ErrInvalidPiece
+// if any of the pieces is not valid.
+func NewBitBoard(squares ...[]Piece) (*BitBoard, error) {
+	b := &BitBoard{}
+	if len(squares) == 0 {
+		return b, nil
+	}
+
+	if len(squares) != 8 {
+		return nil, fmt.Errorf("bitboard: %w: rows count %d is not equal to width 8",
+			ErrInvalidSquare, len(squares))
+	}
+
+	for y, row := range squares {
+		if len(row) != 8 {
+			return nil, fmt.Errorf("bitboard: %w: row %d has %d columns, expected 8",
+				ErrInvalidSquare, y, len(row))
+		}
+
+		for x, p := range row {
+			if err := b.SetSquare(Coor(x, y), p); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// DefaultChessBitBoard returns a bitboard with the default chess position.
+func DefaultChessBitBoard() *BitBoard {
+	b, _ := NewBitBoard(DefaultChessBoard().squares...)
+	return b
+}
+
+// BitIndex returns the bit index of a Coordinate of an 8x8 board.
+// For example, a1 (0, 7) is 0 and h8 (7, 0) is 63.
+func BitIndex(c Coordinate) int {
+	return (7-c.Y)*8 + c.X
+}
+
+// BitCoordinate returns the Coordinate of a bit index of an 8x8 board.
+// It is the inverse of BitIndex.
+func BitCoordinate(i int) Coordinate {
+	return Coor(i%8, 7-i/8)
+}
+
+// Width returns the width of the board. It is always 8.
+func (b *BitBoard) Width() int {
+	return 8
+}
+
+// Square returns the piece at the given Coordinate.
+//
+// It returns ErrInvalidCoordinate if the Coordinate is out of bounds.
+func (b *BitBoard) Square(c Coordinate) (Piece, error) {
+	if !isValidBitCoordinate(c) {
+		return Empty, fmt.Errorf("bitboard: %w: %v", ErrInvalidCoordinate, c)
+	}
+
+	return b.mailbox[BitIndex(c)], nil
+}
+
+// SetSquare sets a piece in a square.
+//
+// It will return ErrInvalidCoordinate if the coordinate is out of bounds or
+// ErrInvalidPiece if the piece is neither Empty nor a colored piece.
+func (b *BitBoard) SetSquare(c Coordinate, p Piece) error {
+	if !isValidBitCoordinate(c) {
+		return fmt.Errorf("bitboard: %w: %v", ErrInvalidCoordinate, c)
+	}
+
+	if _, ok := PieceNames[p]; !ok && p != Empty {
+		return fmt.Errorf("bitboard: %w: %d", ErrInvalidPiece, p)
+	}
+
+	b.Set(BitIndex(c), p)
+	return nil
+}
+
+// Set sets a piece in the square with the given bit index.
+//
+// It is the unchecked version of SetSquare: the index must be in [0, 64)
+// and the piece must be Empty or a colored piece.
+func (b *BitBoard) Set(i int, p Piece) {
+	mask := uint64(1) << i
+	if old := b.mailbox[i]; old != Empty {
+		b.pieces[old] &^= mask
+		b.colors[colorIndex(old)] &^= mask
+	}
+
+	b.mailbox[i] = p
+	if p != Empty {
+		b.pieces[p] |= mask
+		b.colors[colorIndex(p)] |= mask
+	}
+}
+
+// PieceAt returns the piece in the square with the given bit index.
+// The index must be in [0, 64).
+func (b *BitBoard) PieceAt(i int) Piece {
+	return b.mailbox[i]
+}
+
+// Pieces returns the set of squares occupied by the given colored piece.
+func (b *BitBoard) Pieces(p Piece) uint64 {
+	if p < 0 || int(p) >= len(b.pieces) {
+		return 0
+	}
+
+	return b.pieces[p]
+}
+
+// Color returns the set of squares occupied by pieces of the given color.
+func (b *BitBoard) Color(color Piece) uint64 {
+	switch color {
+	case White:
+		return b.colors[0]
+	case Black:
+		return b.colors[1]
+	}
+
+	return 0
+}
+
+// Occupied returns the set of occupied squares.
+func (b *BitBoard) Occupied() uint64 {
+	return b.colors[0] | b.colors[1]
+}
+
+// Count returns the number of pieces equal to the given colored piece.
+func (b *BitBoard) Count(p Piece) int {
+	return bits.OnesCount64(b.Pieces(p))
+}
+
+// Clone returns a copy of the board.
+func (b *BitBoard) Clone() *BitBoard {
+	cloned := *b
+	return &cloned
+}
+
+// colorIndex returns 0 for white pieces and 1 for black pieces.
+func colorIndex(p Piece) int {
+	return int(p >> 4)
+}
+
+// isValidBitCoordinate returns true if the Coordinate is within an 8x8 board.
+func isValidBitCoordinate(c Coordinate) bool {
+	return c.X >= 0 && c.X < 8 && c.Y >= 0 && c.Y < 8
+}
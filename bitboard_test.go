@@ -0,0 +1,148 @@
+package gochess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBitBoard(t *testing.T) {
+	t.Run("Empty Board", func(t *testing.T) {
+		// Arrange & Act
+		board, err := gochess.NewBitBoard()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, board.Width())
+		assert.Zero(t, board.Occupied())
+	})
+
+	t.Run("Default Board", func(t *testing.T) {
+		// Arrange & Act
+		board := gochess.DefaultChessBitBoard()
+
+		// Assert
+		assert.Equal(t, uint64(0x000000000000FFFF), board.Color(gochess.White))
+		assert.Equal(t, uint64(0xFFFF000000000000), board.Color(gochess.Black))
+		assert.Equal(t, uint64(0x000000000000FF00), board.Pieces(gochess.White|gochess.Pawn))
+		assert.Equal(t, uint64(0x1000000000000000), board.Pieces(gochess.Black|gochess.King))
+		assert.Equal(t, 8, board.Count(gochess.Black|gochess.Pawn))
+
+		piece, err := board.Square(gochess.Coor(3, 7))
+		require.NoError(t, err)
+		assert.Equal(t, gochess.White|gochess.Queen, piece)
+	})
+
+	t.Run("Invalid Rows", func(t *testing.T) {
+		// Arrange
+		squares := [][]gochess.Piece{{gochess.Empty}}
+
+		// Act
+		board, err := gochess.NewBitBoard(squares...)
+
+		// Assert
+		require.ErrorIs(t, err, gochess.ErrInvalidSquare)
+		assert.Nil(t, board)
+	})
+
+	t.Run("Invalid Piece", func(t *testing.T) {
+		// Arrange
+		squares := gochess.DefaultChessBitBoard()
+		rows := make([][]gochess.Piece, 8)
+		for y := range 8 {
+			rows[y] = make([]gochess.Piece, 8)
+			for x := range 8 {
+				rows[y][x], _ = squares.Square(gochess.Coor(x, y))
+			}
+		}
+		rows[4][4] = gochess.Pawn
+
+		// Act
+		board, err := gochess.NewBitBoard(rows...)
+
+		// Assert
+		require.ErrorIs(t, err, gochess.ErrInvalidPiece)
+		assert.Nil(t, board)
+	})
+}
+
+func TestBitBoard_SetSquare(t *testing.T) {
+	t.Run("Replace Piece", func(t *testing.T) {
+		// Arrange
+		board := gochess.DefaultChessBitBoard()
+		e2 := gochess.Coor(4, 6)
+
+		// Act
+		err := board.SetSquare(e2, gochess.Black|gochess.Knight)
+
+		// Assert
+		require.NoError(t, err)
+		bit := uint64(1) << gochess.BitIndex(e2)
+		assert.Zero(t, board.Pieces(gochess.White|gochess.Pawn)&bit)
+		assert.Zero(t, board.Color(gochess.White)&bit)
+		assert.NotZero(t, board.Pieces(gochess.Black|gochess.Knight)&bit)
+		assert.NotZero(t, board.Color(gochess.Black)&bit)
+		assert.Equal(t, gochess.Black|gochess.Knight, board.PieceAt(gochess.BitIndex(e2)))
+	})
+
+	t.Run("Empty Square", func(t *testing.T) {
+		// Arrange
+		board := gochess.DefaultChessBitBoard()
+
+		// Act
+		err := board.SetSquare(gochess.Coor(0, 0), gochess.Empty)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0xFEFF000000000000), board.Color(gochess.Black))
+	})
+
+	t.Run("Invalid Coordinate", func(t *testing.T) {
+		// Arrange
+		board := gochess.DefaultChessBitBoard()
+
+		// Act
+		err := board.SetSquare(gochess.Coor(8, 0), gochess.Empty)
+
+		// Assert
+		require.ErrorIs(t, err, gochess.ErrInvalidCoordinate)
+	})
+
+	t.Run("Invalid Piece", func(t *testing.T) {
+		// Arrange
+		board := gochess.DefaultChessBitBoard()
+
+		// Act
+		err := board.SetSquare(gochess.Coor(0, 0), gochess.White)
+
+		// Assert
+		require.ErrorIs(t, err, gochess.ErrInvalidPiece)
+	})
+}
+
+func TestBitBoard_Clone(t *testing.T) {
+	// Arrange
+	board := gochess.DefaultChessBitBoard()
+
+	// Act
+	cloned := board.Clone()
+	require.NoError(t, cloned.SetSquare(gochess.Coor(4, 6), gochess.Empty))
+
+	// Assert
+	piece, err := board.Square(gochess.Coor(4, 6))
+	require.NoError(t, err)
+	assert.Equal(t, gochess.White|gochess.Pawn, piece)
+	assert.NotEqual(t, board.Occupied(), cloned.Occupied())
+}
+
+func TestBitIndex(t *testing.T) {
+	assert.Equal(t, 0, gochess.BitIndex(gochess.Coor(0, 7)))
+	assert.Equal(t, 7, gochess.BitIndex(gochess.Coor(7, 7)))
+	assert.Equal(t, 63, gochess.BitIndex(gochess.Coor(7, 0)))
+
+	for i := range 64 {
+		assert.Equal(t, i, gochess.BitIndex(gochess.BitCoordinate(i)))
+	}
+}
@@ -4,15 +4,12 @@ import (
 	"cmp"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/RchrdHndrcks/gochess/v2"
 )
 
-var fenAnalysisRegex = regexp.MustCompile("[/0-9]")
-
 // loadPosition is a helper function that loads a board from a FEN string.
 //
 // The function will read the entire FEN string and will return an error if
@@ -44,6 +41,11 @@ func (c *Chess) loadPosition(FEN string) error {
 		}
 
 		for x := range 8 {
+			// The row describes fewer than eight squares.
+			if fenRows[y] == "" {
+				return fmt.Errorf("invalid FEN: %s", FEN)
+			}
+
 			char := string(fenRows[y][0])
 			fenRows[y] = fenRows[y][1:]
 
@@ -90,8 +92,8 @@ func (c *Chess) loadPosition(FEN string) error {
 	// the properties are invalid or the position is invalid
 	// the struct will not be modified.
 	copy := *c
-	b, _ := gochess.NewBoard(8, brd...)
-	c.board = b
+	b, _ := gochess.NewBitBoard(brd...)
+	c.setBoard(newBitBoardAdapter(b))
 
 	// If the FEN is invalid, setProperties will
 	// return an error without modifying the board or the properties.
@@ -116,81 +118,66 @@ func (c *Chess) loadPosition(FEN string) error {
 	return nil
 }
 
+// pieceLetters are the FEN letters of the pieces, indexed by piece.
+var pieceLetters = func() (letters [gochess.Black | gochess.King + 1]byte) {
+	for p, name := range gochess.PieceNames {
+		letters[p] = name[0]
+	}
+
+	return letters
+}()
+
 // calculateFEN returns the FEN string of the current position.
 //
-// If move is not empty, it will only update the specified move.
-// If more than one move is passed, it will update only the first move.
-func (c *Chess) calculateFEN(move ...string) string {
+// It is built in a single buffer from the board and the properties, which
+// is cheap enough to be done after every move.
+func (c *Chess) calculateFEN() string {
 	if c.blackKingPosition == nil || c.whiteKingPosition == nil {
 		return ""
 	}
 
-	ac := cmp.Or(c.availableCastles, "-")
-	ips := cmp.Or(c.enPassantSquare, "-")
-
-	var boardFEN string
-	if len(move) == 0 {
-		boardFEN = c.calculateEntireBoardFEN()
-	} else {
-		m := move[0]
-		origin, _ := AlgebraicToCoordinate(m[:2])
-		target, _ := AlgebraicToCoordinate(m[2:4])
-		boardFEN = c.calculateBoardFEN(origin.Y, target.Y)
-	}
-
-	return boardFEN + fmt.Sprintf(" %s %s %s %d %d", gochess.ColorNames[c.turn], ac, ips, c.halfMoves, c.movesCount)
-}
-
-// calculateBoardFEN returns the FEN string of the board without the properties.
-// If rows is not empty, it will only update the specified rows.
-func (c *Chess) calculateBoardFEN(rows ...int) string {
-	if len(rows) == 0 {
-		return c.calculateEntireBoardFEN()
-	}
-
-	r := strings.Split(strings.Split(c.actualFEN, " ")[0], "/")
-	for _, row := range rows {
-		r[row] = c.calculateRowFEN(row)
-	}
-
-	return strings.Join(r, "/")
+	fen := make([]byte, 0, 90)
+	fen = c.appendBoardFEN(fen)
+	fen = append(fen, ' ')
+	fen = append(fen, gochess.ColorNames[c.turn]...)
+	fen = append(fen, ' ')
+	fen = append(fen, c.castlingFEN(false)...)
+	fen = append(fen, ' ')
+	fen = append(fen, cmp.Or(c.enPassantSquare, "-")...)
+	fen = append(fen, ' ')
+	fen = strconv.AppendInt(fen, int64(c.halfMoves), 10)
+	fen = append(fen, ' ')
+	fen = strconv.AppendUint(fen, c.movesCount, 10)
+	return string(fen)
 }
 
-func (c *Chess) calculateEntireBoardFEN() string {
-	fen := ""
-
-	for y := range c.board.Width() {
-		fen += c.calculateRowFEN(y)
-		if y < 7 {
-			fen += "/"
+// appendBoardFEN appends the piece placement field of the FEN string of the
+// board to fen.
+func (c *Chess) appendBoardFEN(fen []byte) []byte {
+	for y := range 8 {
+		if y > 0 {
+			fen = append(fen, '/')
 		}
-	}
 
-	return fen
-}
+		empty := byte(0)
+		for x := range 8 {
+			piece := c.pieceAt(gochess.Coor(x, y))
+			if piece == gochess.Empty {
+				empty++
+				continue
+			}
 
-func (c *Chess) calculateRowFEN(y int) string {
-	fen := ""
-	empty := 0
-	for x := range c.board.Width() {
-		// Ignore errors since the coordinates are valid.
-		piece, _ := c.board.Square(gochess.Coor(x, y))
+			if empty > 0 {
+				fen = append(fen, '0'+empty)
+				empty = 0
+			}
 
-		if piece == gochess.Empty {
-			empty++
-			continue
+			fen = append(fen, pieceLetters[piece])
 		}
 
 		if empty > 0 {
-			fen += fmt.Sprintf("%d", empty)
-			empty = 0
+			fen = append(fen, '0'+empty)
 		}
-
-		fen += gochess.PieceNames[piece]
-	}
-
-	if empty > 0 {
-		fen += fmt.Sprintf("%d", empty)
 	}
 
 	return fen
@@ -207,9 +194,9 @@ func (c *Chess) setProperties(FEN string) error {
 		return fmt.Errorf("invalid color: %s", props[0])
 	}
 
-	availableCastles := props[1]
-	if err := c.validateCastles(availableCastles); err != nil {
-		return fmt.Errorf("invalid castles: %s", availableCastles)
+	castles, castleKingFiles, err := c.parseCastlingRights(props[1])
+	if err != nil {
+		return fmt.Errorf("invalid castles: %s", props[1])
 	}
 
 	enPassantSquare := props[2]
@@ -228,8 +215,12 @@ func (c *Chess) setProperties(FEN string) error {
 	}
 
 	c.turn = color
-	c.availableCastles = availableCastles
+	c.castles = castles
+	c.castleKingFiles = castleKingFiles
 	c.enPassantSquare = props[2]
+	if c.enPassantSquare == "-" {
+		c.enPassantSquare = ""
+	}
 	c.halfMoves = halfMoves
 	c.movesCount = movesCount
 	return nil
@@ -243,27 +234,24 @@ func (c *Chess) updateMovesCount() {
 }
 
 // updateCastlePossibilities checks if the castles are still available.
+//
+// A castle is no longer available when its king or its rook have left their
+// squares.
 func (c *Chess) updateCastlePossibilities() {
-	toBeRemoved := map[string]bool{}
-
-	k, _ := c.board.Square(gochess.Coor(4, 0))
-	rr, _ := c.board.Square(gochess.Coor(7, 0))
-	lr, _ := c.board.Square(gochess.Coor(0, 0))
-	toBeRemoved["k"] = rr != gochess.Rook|gochess.Black || k != gochess.King|gochess.Black
-	toBeRemoved["q"] = lr != gochess.Rook|gochess.Black || k != gochess.King|gochess.Black
-
-	K, _ := c.board.Square(gochess.Coor(4, 7))
-	rR, _ := c.board.Square(gochess.Coor(7, 7))
-	lR, _ := c.board.Square(gochess.Coor(0, 7))
-	toBeRemoved["K"] = rR != gochess.Rook|gochess.White || K != gochess.King|gochess.White
-	toBeRemoved["Q"] = lR != gochess.Rook|gochess.White || K != gochess.King|gochess.White
-
-	for castle, mustDelete := range toBeRemoved {
-		if !mustDelete {
-			continue
-		}
+	for _, color := range []gochess.Piece{gochess.White, gochess.Black} {
+		y := backRank(color)
+		ci := colorIndex(color)
+		kingMoved := c.pieceAt(gochess.Coor(c.castleKingFiles[ci], y)) != color|gochess.King
+
+		for side, rookFile := range c.castles[ci] {
+			if rookFile == noCastle {
+				continue
+			}
 
-		c.availableCastles = strings.ReplaceAll(c.availableCastles, castle, "")
+			if kingMoved || c.pieceAt(gochess.Coor(rookFile, y)) != color|gochess.Rook {
+				c.castles[ci][side] = noCastle
+			}
+		}
 	}
 }
 
@@ -278,33 +266,10 @@ func (c *Chess) updateHalfMoves() {
 	}
 
 	c.halfMoves++
-	h := c.history[len(c.history)-1]
+	m := c.history[len(c.history)-1].move
 
-	// If the move was promotion, reset the counter.
-	if len(h.move) == 5 {
-		c.halfMoves = 0
-		return
-	}
-
-	// Look for a change in the board.
-	// If we have less pieces than before, a capture was made so we reset the counter.
-	lastFENPiecePart := strings.Split(h.fen, " ")[0]
-
-	lastFENPiecePart = fenAnalysisRegex.ReplaceAllString(lastFENPiecePart, "")
-	fenPiecePart := fenAnalysisRegex.ReplaceAllString(c.calculateBoardFEN(), "")
-
-	if len(lastFENPiecePart) > len(fenPiecePart) {
-		c.halfMoves = 0
-		return
-	}
-
-	// If no capture was made, we check if last move was a pawn move.
-	target := h.move[2:4]
-	coor, _ := AlgebraicToCoordinate(target)
-	p, _ := c.board.Square(coor)
-
-	piece := gochess.PieceType(p)
-	if piece == gochess.Pawn {
+	// If the move was a capture or a pawn move, reset the counter.
+	if m.IsCapture() || gochess.PieceType(m.Piece) == gochess.Pawn {
 		c.halfMoves = 0
 	}
 }
@@ -317,23 +282,12 @@ func (c *Chess) updateEnPassantSquare() {
 	c.enPassantSquare = ""
 
 	lastMove := c.history[len(c.history)-1].move
-	if len(lastMove) != 4 {
-		return
-	}
-
-	dest, _ := AlgebraicToCoordinate(lastMove[2:])
-	p, _ := c.board.Square(dest)
-
-	if gochess.PieceType(p) != gochess.Pawn {
+	if !lastMove.IsDoublePush() {
 		return
 	}
 
-	destRow, _ := strconv.Atoi(lastMove[3:4])
-	orgRow, _ := strconv.Atoi(lastMove[1:2])
-	if destRow == orgRow+2 || destRow == orgRow-2 {
-		c.enPassantSquare = fmt.Sprintf("%s%d", lastMove[2:3], (destRow+orgRow)/2)
-		return
-	}
+	c.enPassantSquare = CoordinateToAlgebraic(
+		gochess.Coor(lastMove.From.X, (lastMove.From.Y+lastMove.To.Y)/2))
 }
 
 // validateEnPassant validates the in passant square.
@@ -357,7 +311,7 @@ func (c Chess) validateEnPassant(square string) error {
 	}
 
 	auxCoor := gochess.Coor(coor.X, yCoor)
-	p, _ := c.board.Square(auxCoor)
+	p := c.pieceAt(auxCoor)
 	if gochess.PieceType(p) != gochess.Pawn {
 		return errors.New("invalid in passant square")
 	}
@@ -409,12 +363,7 @@ func (c Chess) isCheck() bool {
 	}
 
 	kingPosition := c.kingsPosition(c.turn)
-
-	c.toggleColor()
-	defer c.toggleColor()
-	moves := c.availableMoves()
-
-	return destinationMatch(moves, kingPosition)
+	return isSquareAttacked(c.bits, gochess.BitIndex(kingPosition), opponent(c.turn))
 }
 
 // kingsPosition returns the position of the king of the given color.
@@ -425,35 +374,3 @@ func (c Chess) kingsPosition(color gochess.Piece) gochess.Coordinate {
 
 	return *c.blackKingPosition
 }
-
-// pieceFromFEN is a helper function that returns the piece at the given coordinate
-// in the FEN string.
-//
-// It must be called with a valid coordinate and a valid FEN string.
-// If there is no piece at the given coordinate, it returns gochess.Empty.
-func pieceFromFEN(fen string, coord gochess.Coordinate) gochess.Piece {
-	fenRow := strings.Split(strings.Split(fen, " ")[0], "/")[coord.Y]
-	var count int
-	for _, c := range fenRow {
-		n, err := strconv.Atoi(string(c))
-		if err == nil {
-			count += n
-			if count > coord.X {
-				return gochess.Empty
-			}
-
-			continue
-		}
-
-		if count == coord.X {
-			if p, ok := gochess.Pieces[string(c)]; ok {
-				return p
-			}
-			return gochess.Empty
-		}
-
-		count++
-	}
-
-	return gochess.Empty
-}
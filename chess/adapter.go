@@ -23,3 +23,24 @@ func (b *boardAdapter) Clone() Board {
 		Board: b.Board.Clone(),
 	}
 }
+
+// bitBoardAdapter is an adapter for gochess.BitBoard that implements the
+// Board and Cloner interfaces.
+//
+// Chess uses the wrapped gochess.BitBoard directly for the move generation.
+type bitBoardAdapter struct {
+	*gochess.BitBoard
+}
+
+func newBitBoardAdapter(board *gochess.BitBoard) *bitBoardAdapter {
+	return &bitBoardAdapter{
+		BitBoard: board,
+	}
+}
+
+// Clone implements the Cloner interface.
+func (b *bitBoardAdapter) Clone() Board {
+	return &bitBoardAdapter{
+		BitBoard: b.BitBoard.Clone(),
+	}
+}
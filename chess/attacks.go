@@ -0,0 +1,154 @@
+package chess
+
+import (
+	"math/bits"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+// Pin is a piece pinned to the king of its color by an enemy sliding piece.
+type Pin struct {
+	// Square is the square of the pinned piece.
+	Square gochess.Coordinate
+	// Pinner is the square of the enemy piece that pins it.
+	Pinner gochess.Coordinate
+	// Ray is the set of squares from the king, excluded, to the pinner,
+	// included, in the gochess.BitIndex layout. The pinned piece can only
+	// move inside it.
+	Ray uint64
+}
+
+// IsSquareAttacked returns true if any piece of the given color attacks the
+// square. Pieces attack the squares they could capture on, so the squares
+// in front of the pawns are not attacked by them.
+//
+// It returns false if the square is outside the board.
+func (c *Chess) IsSquareAttacked(square gochess.Coordinate, by gochess.Piece) bool {
+	return c.Attackers(square, by) != 0
+}
+
+// Attackers returns the set of squares of the pieces of the given color that
+// attack the square, using the gochess.BitIndex layout: bit 0 is a1 and bit 63
+// is h8.
+//
+// It returns an empty set if the square is outside the board.
+func (c *Chess) Attackers(square gochess.Coordinate, by gochess.Piece) uint64 {
+	if !onBoard(square.X, square.Y) {
+		return 0
+	}
+
+	return attackersOf(c.bits, gochess.BitIndex(square), by, c.bits.Occupied())
+}
+
+// Checkers returns the set of squares of the pieces that give check to the
+// king of the current turn, using the gochess.BitIndex layout. The set is
+// empty when the king is not in check.
+func (c *Chess) Checkers() uint64 {
+	king := c.bits.Pieces(c.turn | gochess.King)
+	if king == 0 {
+		return 0
+	}
+
+	return attackersOf(c.bits, bits.TrailingZeros64(king), opponent(c.turn), c.bits.Occupied())
+}
+
+// PinnedPieces returns the pieces of the given color that are pinned to
+// their king, with the squares they can move to without leaving it in
+// check. Pieces of both colors can be pinned.
+func (c *Chess) PinnedPieces(color gochess.Piece) []Pin {
+	return pins(c.bits, color, nil)
+}
+
+// AttackMap returns the number of pieces of the given color that attack every
+// square, indexed by gochess.BitIndex. A square defended by several pieces of
+// its own color counts all of them, so the hanging pieces of a color are the
+// ones attacked by the opponent and not defended.
+func (c *Chess) AttackMap(color gochess.Piece) [64]int {
+	var attacks [64]int
+	b := c.bits
+	occupied := b.Occupied()
+
+	for set := b.Pieces(color | gochess.Pawn); set != 0; {
+		countAttacks(&attacks, pawnAttacks[colorIndex(color)][popSquare(&set)])
+	}
+
+	for _, pieceType := range []gochess.Piece{gochess.Knight, gochess.Bishop, gochess.Rook, gochess.Queen, gochess.King} {
+		for set := b.Pieces(color | pieceType); set != 0; {
+			countAttacks(&attacks, pieceAttacks(pieceType, popSquare(&set), occupied))
+		}
+	}
+
+	return attacks
+}
+
+// countAttacks adds one attack to every square of the set.
+func countAttacks(attacks *[64]int, set uint64) {
+	for set != 0 {
+		attacks[popSquare(&set)]++
+	}
+}
+
+// pieceAttacks returns the squares attacked by a piece that is not a pawn.
+func pieceAttacks(pieceType gochess.Piece, sq int, occupied uint64) uint64 {
+	switch pieceType {
+	case gochess.Knight:
+		return knightAttacks[sq]
+	case gochess.Bishop:
+		return bishopAttacks(sq, occupied)
+	case gochess.Rook:
+		return rookAttacks(sq, occupied)
+	case gochess.Queen:
+		return bishopAttacks(sq, occupied) | rookAttacks(sq, occupied)
+	case gochess.King:
+		return kingAttacks[sq]
+	default:
+		return 0
+	}
+}
+
+// pins appends the pieces of the given color pinned to their king to dst.
+// A king can't have more than eight pinned pieces.
+//
+// A piece is pinned when it is the only piece between its king and an enemy
+// rook or queen in the same rank or file, or an enemy bishop or queen in the
+// same diagonal.
+func pins(b *gochess.BitBoard, color gochess.Piece, dst []Pin) []Pin {
+	king := b.Pieces(color | gochess.King)
+	if king == 0 {
+		return dst
+	}
+
+	sq := bits.TrailingZeros64(king)
+	occupied := b.Occupied()
+	own := b.Color(color)
+	enemy := opponent(color)
+	queens := b.Pieces(enemy | gochess.Queen)
+
+	for dir := range rays {
+		sliders := b.Pieces(enemy|gochess.Rook) | queens
+		if dir == northEast || dir == northWest || dir == southEast || dir == southWest {
+			sliders = b.Pieces(enemy|gochess.Bishop) | queens
+		}
+
+		// The first piece of the ray must be an own piece and the second one
+		// an enemy slider of the direction.
+		blocker := rayAttacks(dir, sq, occupied) & occupied & own
+		if blocker == 0 {
+			continue
+		}
+
+		ray := rayAttacks(dir, sq, occupied&^blocker)
+		pinner := ray & occupied &^ blocker & sliders
+		if pinner == 0 {
+			continue
+		}
+
+		dst = append(dst, Pin{
+			Square: gochess.BitCoordinate(bits.TrailingZeros64(blocker)),
+			Pinner: gochess.BitCoordinate(bits.TrailingZeros64(pinner)),
+			Ray:    ray,
+		})
+	}
+
+	return dst
+}
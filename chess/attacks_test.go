@@ -0,0 +1,158 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coor returns the coordinate of a square in algebraic notation.
+func coor(t *testing.T, square string) gochess.Coordinate {
+	t.Helper()
+
+	c, err := chess.AlgebraicToCoordinate(square)
+	require.NoError(t, err)
+	return c
+}
+
+// squareSet returns the set of the squares in the gochess.BitIndex layout.
+func squareSet(t *testing.T, squares ...string) uint64 {
+	t.Helper()
+
+	var set uint64
+	for _, s := range squares {
+		set |= 1 << gochess.BitIndex(coor(t, s))
+	}
+
+	return set
+}
+
+func TestIsSquareAttacked(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("4k3/8/8/3p4/8/8/8/4K2R w - - 0 1"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		square string
+		by     gochess.Piece
+		want   bool
+	}{
+		{"e4", gochess.Black, true},
+		{"c4", gochess.Black, true},
+		{"d4", gochess.Black, false},
+		{"h8", gochess.White, true},
+		{"f1", gochess.White, true},
+		{"d1", gochess.White, true},
+		{"c1", gochess.White, false},
+		{"d7", gochess.Black, true},
+		{"d7", gochess.White, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, c.IsSquareAttacked(coor(t, tt.square), tt.by), tt.square)
+	}
+
+	t.Run("Outside the board", func(t *testing.T) {
+		assert.False(t, c.IsSquareAttacked(gochess.Coor(8, 0), gochess.White))
+		assert.Zero(t, c.Attackers(gochess.Coor(-1, 3), gochess.White))
+	})
+}
+
+func TestAttackers(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("1b2k3/8/8/8/8/3N1N2/8/4RK2 b - - 0 1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, squareSet(t, "d3", "f3", "e1"), c.Attackers(coor(t, "e5"), gochess.White))
+	assert.Equal(t, squareSet(t, "b8"), c.Attackers(coor(t, "e5"), gochess.Black))
+	assert.Zero(t, c.Attackers(coor(t, "a1"), gochess.Black))
+
+	// Pieces attack the squares of their own pieces too.
+	assert.Equal(t, squareSet(t, "e1"), c.Attackers(coor(t, "f1"), gochess.White))
+}
+
+func TestCheckers(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []string
+	}{
+		{"No check", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", nil},
+		{"Check", "1b2k3/8/8/8/8/3N1N2/8/4RK2 b - - 0 1", []string{"e1"}},
+		{"Double check", "4k3/8/8/1B6/8/8/8/4RK2 b - - 0 1", []string{"b5", "e1"}},
+		{"Pawn check", "4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", []string{"d2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := chess.New(chess.WithFEN(tt.fen))
+			require.NoError(t, err)
+
+			assert.Equal(t, squareSet(t, tt.want...), c.Checkers())
+			assert.Equal(t, len(tt.want) > 0, c.IsCheck())
+		})
+	}
+}
+
+func TestPinnedPieces(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("4k3/4r3/8/b7/7b/6P1/3NRP2/4KB1q w - - 0 1"))
+	require.NoError(t, err)
+
+	want := []chess.Pin{
+		{Square: coor(t, "e2"), Pinner: coor(t, "e7"), Ray: squareSet(t, "e2", "e3", "e4", "e5", "e6", "e7")},
+		{Square: coor(t, "f1"), Pinner: coor(t, "h1"), Ray: squareSet(t, "f1", "g1", "h1")},
+		{Square: coor(t, "d2"), Pinner: coor(t, "a5"), Ray: squareSet(t, "d2", "c3", "b4", "a5")},
+	}
+
+	assert.ElementsMatch(t, want, c.PinnedPieces(gochess.White))
+
+	// The pinned rooks pin each other.
+	assert.Equal(t, []chess.Pin{
+		{Square: coor(t, "e7"), Pinner: coor(t, "e2"), Ray: squareSet(t, "e7", "e6", "e5", "e4", "e3", "e2")},
+	}, c.PinnedPieces(gochess.Black))
+
+	t.Run("Pinned pieces only move along the ray", func(t *testing.T) {
+		for _, m := range c.Moves() {
+			for _, p := range want {
+				if m.From == p.Square {
+					assert.NotZero(t, p.Ray&(1<<gochess.BitIndex(m.To)), m.String())
+				}
+			}
+		}
+	})
+}
+
+func TestAttackMap(t *testing.T) {
+	t.Run("Starting position", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		attacks := c.AttackMap(gochess.White)
+		tests := map[string]int{"a1": 0, "d2": 4, "e2": 4, "d3": 2, "f3": 3, "c3": 3, "e4": 0}
+		for square, want := range tests {
+			assert.Equal(t, want, attacks[gochess.BitIndex(coor(t, square))], square)
+		}
+
+		assert.Equal(t, 3, c.AttackMap(gochess.Black)[gochess.BitIndex(coor(t, "f6"))])
+	})
+
+	t.Run("Hanging pieces", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("4k3/8/2p5/1n1n4/8/8/8/3RK3 w - - 0 1"))
+		require.NoError(t, err)
+
+		white, black := c.AttackMap(gochess.White), c.AttackMap(gochess.Black)
+
+		d5 := gochess.BitIndex(coor(t, "d5"))
+		assert.Equal(t, 1, white[d5])
+		assert.Equal(t, 1, black[d5])
+
+		b5 := gochess.BitIndex(coor(t, "b5"))
+		assert.Equal(t, 0, white[b5])
+		assert.Equal(t, 1, black[b5])
+
+		d1 := gochess.BitIndex(coor(t, "d1"))
+		assert.Equal(t, 1, white[d1])
+		assert.Equal(t, 0, black[d1])
+	})
+}
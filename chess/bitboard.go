@@ -0,0 +1,163 @@
+package chess
+
+import (
+	"math/bits"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+// Directions of the sliding rays. The first four directions increase the
+// bit index of the squares and the last four decrease it.
+const (
+	north = iota
+	east
+	northEast
+	northWest
+	south
+	west
+	southWest
+	southEast
+)
+
+var (
+	// rays holds, for every direction and square, the set of squares from
+	// that square to the edge of the board, excluding the square itself.
+	rays [8][64]uint64
+	// knightAttacks holds the squares attacked by a knight on every square.
+	knightAttacks [64]uint64
+	// kingAttacks holds the squares attacked by a king on every square.
+	kingAttacks [64]uint64
+	// pawnAttacks holds the squares attacked by a pawn on every square,
+	// indexed by the color index of the pawn.
+	pawnAttacks [2][64]uint64
+)
+
+func init() {
+	directions := [8][2]int{
+		north: {0, 1}, east: {1, 0}, northEast: {1, 1}, northWest: {-1, 1},
+		south: {0, -1}, west: {-1, 0}, southWest: {-1, -1}, southEast: {1, -1},
+	}
+
+	knightOffsets := [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingOffsets := [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+	for sq := range 64 {
+		file, rank := sq%8, sq/8
+
+		for dir, d := range directions {
+			for f, r := file+d[0], rank+d[1]; onBoard(f, r); f, r = f+d[0], r+d[1] {
+				rays[dir][sq] |= squareBit(r*8 + f)
+			}
+		}
+
+		for _, o := range knightOffsets {
+			if f, r := file+o[0], rank+o[1]; onBoard(f, r) {
+				knightAttacks[sq] |= squareBit(r*8 + f)
+			}
+		}
+
+		for _, o := range kingOffsets {
+			if f, r := file+o[0], rank+o[1]; onBoard(f, r) {
+				kingAttacks[sq] |= squareBit(r*8 + f)
+			}
+		}
+
+		for _, df := range []int{-1, 1} {
+			if f, r := file+df, rank+1; onBoard(f, r) {
+				pawnAttacks[0][sq] |= squareBit(r*8 + f)
+			}
+			if f, r := file+df, rank-1; onBoard(f, r) {
+				pawnAttacks[1][sq] |= squareBit(r*8 + f)
+			}
+		}
+	}
+}
+
+// onBoard returns true if the file and rank are inside an 8x8 board.
+func onBoard(file, rank int) bool {
+	return file >= 0 && file < 8 && rank >= 0 && rank < 8
+}
+
+// squareBit returns the set containing only the square with the given index.
+func squareBit(sq int) uint64 {
+	return uint64(1) << sq
+}
+
+// popSquare returns the index of the lowest square of the set and removes
+// it from the set. The set must not be empty.
+func popSquare(set *uint64) int {
+	sq := bits.TrailingZeros64(*set)
+	*set &= *set - 1
+	return sq
+}
+
+// rayAttacks returns the squares attacked from a square in a direction,
+// stopping at the first occupied square, which is included.
+func rayAttacks(dir, sq int, occupied uint64) uint64 {
+	attacks := rays[dir][sq]
+	blockers := attacks & occupied
+	if blockers == 0 {
+		return attacks
+	}
+
+	var blocker int
+	if dir < south {
+		blocker = bits.TrailingZeros64(blockers)
+	} else {
+		blocker = 63 - bits.LeadingZeros64(blockers)
+	}
+
+	return attacks ^ rays[dir][blocker]
+}
+
+// rookAttacks returns the squares attacked by a rook on a square.
+func rookAttacks(sq int, occupied uint64) uint64 {
+	return rayAttacks(north, sq, occupied) | rayAttacks(east, sq, occupied) |
+		rayAttacks(south, sq, occupied) | rayAttacks(west, sq, occupied)
+}
+
+// bishopAttacks returns the squares attacked by a bishop on a square.
+func bishopAttacks(sq int, occupied uint64) uint64 {
+	return rayAttacks(northEast, sq, occupied) | rayAttacks(northWest, sq, occupied) |
+		rayAttacks(southEast, sq, occupied) | rayAttacks(southWest, sq, occupied)
+}
+
+// between returns the squares between two squares in the same rank, file
+// or diagonal, excluding both of them. It returns an empty set if the
+// squares are not aligned.
+func between(from, to int) uint64 {
+	for dir := range rays {
+		if rays[dir][from]&squareBit(to) != 0 {
+			return rays[dir][from] &^ rays[dir][to] &^ squareBit(to)
+		}
+	}
+
+	return 0
+}
+
+// colorIndex returns 0 for white and 1 for black.
+func colorIndex(color gochess.Piece) int {
+	return int(color >> 4)
+}
+
+// opponent returns the opposite color.
+func opponent(color gochess.Piece) gochess.Piece {
+	return color ^ (gochess.White | gochess.Black)
+}
+
+// attackersOf returns the set of pieces of the given color that attack a
+// square, considering the given occupancy for the sliding pieces.
+func attackersOf(b *gochess.BitBoard, sq int, by gochess.Piece, occupied uint64) uint64 {
+	queens := b.Pieces(by | gochess.Queen)
+	return pawnAttacks[colorIndex(opponent(by))][sq]&b.Pieces(by|gochess.Pawn) |
+		knightAttacks[sq]&b.Pieces(by|gochess.Knight) |
+		kingAttacks[sq]&b.Pieces(by|gochess.King) |
+		bishopAttacks(sq, occupied)&(b.Pieces(by|gochess.Bishop)|queens) |
+		rookAttacks(sq, occupied)&(b.Pieces(by|gochess.Rook)|queens)
+}
+
+// isSquareAttacked returns true if any piece of the given color attacks
+// the square.
+func isSquareAttacked(b *gochess.BitBoard, sq int, by gochess.Piece) bool {
+	return attackersOf(b, sq, by, b.Occupied()) != 0
+}
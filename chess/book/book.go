@@ -0,0 +1,312 @@
+// Package book reads and writes opening books in the Polyglot format.
+//
+// A Polyglot book is a file of 16-byte big-endian entries sorted by key:
+// the Zobrist hash of a position (chess.Chess.Hash), a move, its weight and
+// a learn value. Castling moves are encoded as the king capturing its own
+// rook (e.g. "e1h1"), and are translated to the UCI notation of the library
+// (e.g. "e1g1") when they are read.
+package book
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// EntrySize is the size of an entry of a Polyglot book in bytes.
+const EntrySize = 16
+
+// ErrNotFound is returned when a position has no legal moves in the book.
+var ErrNotFound = errors.New("position not found in book")
+
+// Entry is an entry of a Polyglot book.
+type Entry struct {
+	// Key is the Zobrist hash of the position.
+	Key uint64
+	// Move is the move in the Polyglot encoding: the target file and rank
+	// in bits 0-5, the origin file and rank in bits 6-11 and the promotion
+	// piece (1 knight, 2 bishop, 3 rook, 4 queen) in bits 12-14.
+	Move uint16
+	// Weight is the weight of the move among the moves of the position.
+	Weight uint16
+	// Learn is the learning data of the move.
+	Learn uint32
+}
+
+// Candidate is a book move of a position.
+type Candidate struct {
+	// Move is the legal move.
+	Move chess.Move
+	// Weight is the weight of the move among the moves of the position.
+	Weight uint16
+	// Learn is the learning data of the move.
+	Learn uint32
+}
+
+// Book is a Polyglot opening book.
+//
+// The entries are read on demand with a binary search by key, so the book
+// is not loaded in memory. A Book is safe for concurrent use if its reader
+// is, which is the case of the books opened with Open.
+type Book struct {
+	r      io.ReaderAt
+	n      int
+	closer io.Closer
+}
+
+// Open opens the Polyglot book file with the given path.
+//
+// The book must be closed when it is no longer used.
+func Open(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open book: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open book: %w", err)
+	}
+
+	b, err := NewBook(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	b.closer = f
+	return b, nil
+}
+
+// NewBook returns the Polyglot book of size bytes read from r.
+//
+// It returns an error if the size is not a multiple of EntrySize.
+func NewBook(r io.ReaderAt, size int64) (*Book, error) {
+	if size%EntrySize != 0 {
+		return nil, fmt.Errorf("invalid book size: %d bytes is not a multiple of %d", size, EntrySize)
+	}
+
+	return &Book{r: r, n: int(size / EntrySize)}, nil
+}
+
+// Close closes the file of a book opened with Open. It does nothing for the
+// books created with NewBook.
+func (b *Book) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+
+	return b.closer.Close()
+}
+
+// Len returns the number of entries of the book.
+func (b *Book) Len() int {
+	return b.n
+}
+
+// Entries returns the entries of the position with the given key, in the
+// order of the book.
+func (b *Book) Entries(key uint64) ([]Entry, error) {
+	var readErr error
+	first := sort.Search(b.n, func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+
+		e, err := b.entry(i)
+		readErr = err
+		return e.Key >= key
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var entries []Entry
+	for i := first; i < b.n; i++ {
+		e, err := b.entry(i)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Key != key {
+			break
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Moves returns the legal book moves of the current position of the game,
+// sorted by weight from the highest to the lowest.
+//
+// The entries whose moves are not legal in the position, which could come
+// from hash collisions, are ignored. It always returns a non nil slice if
+// the error is nil.
+func (b *Book) Moves(game *chess.Chess) ([]Candidate, error) {
+	entries, err := b.Entries(game.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(entries))
+	for _, e := range entries {
+		m, ok := DecodeMove(game, e.Move)
+		if !ok {
+			continue
+		}
+
+		candidates = append(candidates, Candidate{Move: m, Weight: e.Weight, Learn: e.Learn})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return int(b.Weight) - int(a.Weight)
+	})
+
+	return candidates, nil
+}
+
+// Choose returns a book move of the current position of the game, chosen at
+// random with a probability proportional to its weight. If every move has a
+// zero weight, they are chosen with the same probability.
+//
+// The moves are chosen with rng, which can be seeded to get reproducible
+// choices, or with the global generator of math/rand/v2 if rng is nil.
+// It returns ErrNotFound if the position has no legal moves in the book.
+func (b *Book) Choose(game *chess.Chess, rng *rand.Rand) (chess.Move, error) {
+	candidates, err := b.Moves(game)
+	if err != nil {
+		return chess.Move{}, err
+	}
+
+	if len(candidates) == 0 {
+		return chess.Move{}, ErrNotFound
+	}
+
+	intN := rand.IntN
+	if rng != nil {
+		intN = rng.IntN
+	}
+
+	var total int
+	for _, c := range candidates {
+		total += int(c.Weight)
+	}
+
+	if total == 0 {
+		return candidates[intN(len(candidates))].Move, nil
+	}
+
+	n := intN(total)
+	for _, c := range candidates {
+		n -= int(c.Weight)
+		if n < 0 {
+			return c.Move, nil
+		}
+	}
+
+	return candidates[len(candidates)-1].Move, nil
+}
+
+// entry reads the entry with the given index.
+func (b *Book) entry(i int) (Entry, error) {
+	var buf [EntrySize]byte
+	if _, err := b.r.ReadAt(buf[:], int64(i)*EntrySize); err != nil {
+		return Entry{}, fmt.Errorf("failed to read book entry %d: %w", i, err)
+	}
+
+	return decodeEntry(buf[:]), nil
+}
+
+// decodeEntry decodes an entry from its 16 bytes.
+func decodeEntry(buf []byte) Entry {
+	return Entry{
+		Key:    binary.BigEndian.Uint64(buf[0:8]),
+		Move:   binary.BigEndian.Uint16(buf[8:10]),
+		Weight: binary.BigEndian.Uint16(buf[10:12]),
+		Learn:  binary.BigEndian.Uint32(buf[12:16]),
+	}
+}
+
+// appendEntry appends the 16 bytes of an entry to buf.
+func appendEntry(buf []byte, e Entry) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, e.Key)
+	buf = binary.BigEndian.AppendUint16(buf, e.Move)
+	buf = binary.BigEndian.AppendUint16(buf, e.Weight)
+	return binary.BigEndian.AppendUint32(buf, e.Learn)
+}
+
+// promotionPieces are the promotion pieces by their Polyglot code.
+var promotionPieces = [...]gochess.Piece{gochess.Empty, gochess.Knight, gochess.Bishop, gochess.Rook, gochess.Queen}
+
+// DecodeMove returns the legal move of the current position of the game
+// encoded by a Polyglot move, or false if the move is not legal.
+//
+// Polyglot encodes the castling moves as the king capturing its own rook,
+// which are translated to the castling moves of the game (e.g. "e1h1" is
+// "e1g1" in standard chess).
+func DecodeMove(game *chess.Chess, move uint16) (chess.Move, bool) {
+	// The Polyglot squares are the gochess.BitIndex of the squares.
+	to := gochess.BitCoordinate(int(move & 0x3f))
+	from := gochess.BitCoordinate(int(move>>6) & 0x3f)
+	code := int(move>>12) & 0x7
+	if code >= len(promotionPieces) {
+		return chess.Move{}, false
+	}
+
+	promotion := promotionPieces[code]
+	for _, m := range game.Moves() {
+		if m.From != from || gochess.PieceType(m.Promotion) != promotion {
+			continue
+		}
+
+		if m.To == to || m.IsCastle() && castleRookSquare(game, m) == to {
+			return m, true
+		}
+	}
+
+	return chess.Move{}, false
+}
+
+// EncodeMove returns the Polyglot encoding of a move of the game.
+//
+// Castling moves are encoded as the king capturing its own rook.
+func EncodeMove(game *chess.Chess, m chess.Move) uint16 {
+	to := m.To
+	if m.IsCastle() {
+		to = castleRookSquare(game, m)
+	}
+
+	var code uint16
+	for i, p := range promotionPieces {
+		if i > 0 && p == gochess.PieceType(m.Promotion) {
+			code = uint16(i)
+		}
+	}
+
+	return code<<12 | uint16(gochess.BitIndex(m.From))<<6 | uint16(gochess.BitIndex(to))
+}
+
+// castleRookSquare returns the square of the rook of a castling move of the
+// game. In Chess960 the target of the castling moves is already the rook.
+func castleRookSquare(game *chess.Chess, m chess.Move) gochess.Coordinate {
+	if game.IsChess960() {
+		return m.To
+	}
+
+	if m.To.X > m.From.X {
+		return gochess.Coor(7, m.From.Y)
+	}
+
+	return gochess.Coor(0, m.From.Y)
+}
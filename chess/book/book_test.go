@@ -0,0 +1,290 @@
+package book_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/book"
+)
+
+const games = `[Event "1"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 1-0
+
+[Event "2"]
+[Result "1/2-1/2"]
+
+1. e4 c5 2. Nf3 d6 1/2-1/2
+
+[Event "3"]
+[Result "0-1"]
+
+1. d4 d5 2. c4 e6 0-1
+`
+
+// newBook builds a book from the games and opens it from memory.
+func newBook(t *testing.T, opts ...book.BuilderOption) *book.Book {
+	t.Helper()
+
+	builder := book.NewBuilder(opts...)
+	n, err := builder.AddPGN(strings.NewReader(games))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	var buf bytes.Buffer
+	_, err = builder.WriteTo(&buf)
+	require.NoError(t, err)
+
+	b, err := book.NewBook(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return b
+}
+
+// rawBook returns a book made of the given entries, sorted by key.
+func rawBook(t *testing.T, entries ...book.Entry) *book.Book {
+	t.Helper()
+
+	var buf []byte
+	for _, e := range entries {
+		buf = binary.BigEndian.AppendUint64(buf, e.Key)
+		buf = binary.BigEndian.AppendUint16(buf, e.Move)
+		buf = binary.BigEndian.AppendUint16(buf, e.Weight)
+		buf = binary.BigEndian.AppendUint32(buf, e.Learn)
+	}
+
+	b, err := book.NewBook(bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	return b
+}
+
+func TestNewBook(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := book.NewBook(bytes.NewReader(make([]byte, 20)), 20)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty book", func(t *testing.T) {
+		b, err := book.NewBook(bytes.NewReader(nil), 0)
+		require.NoError(t, err)
+		assert.Equal(t, 0, b.Len())
+
+		game, _ := chess.New()
+		moves, err := b.Moves(game)
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("book file", func(t *testing.T) {
+		builder := book.NewBuilder()
+		_, err := builder.AddPGN(strings.NewReader(games))
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "book.bin")
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		_, err = builder.WriteTo(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		b, err := book.Open(path)
+		require.NoError(t, err)
+		defer b.Close()
+
+		assert.Equal(t, len(builder.Entries()), b.Len())
+
+		game, _ := chess.New()
+		moves, err := b.Moves(game)
+		require.NoError(t, err)
+		require.Len(t, moves, 2)
+		assert.Equal(t, "e2e4", moves[0].Move.UCI())
+		assert.Equal(t, "d2d4", moves[1].Move.UCI())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := book.Open(filepath.Join(t.TempDir(), "missing.bin"))
+		assert.Error(t, err)
+	})
+}
+
+func TestBookEntries(t *testing.T) {
+	b := newBook(t)
+
+	t.Run("starting position", func(t *testing.T) {
+		game, _ := chess.New()
+		entries, err := b.Entries(game.Hash())
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		for _, e := range entries {
+			assert.Equal(t, game.Hash(), e.Key)
+		}
+
+		// 1. e4 scored a win and a draw, 1. d4 a loss.
+		assert.Equal(t, uint16(3), entries[0].Weight)
+		assert.Equal(t, uint16(0), entries[1].Weight)
+	})
+
+	t.Run("unknown position", func(t *testing.T) {
+		entries, err := b.Entries(0x1234)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestBookMoves(t *testing.T) {
+	b := newBook(t)
+
+	t.Run("sorted by weight", func(t *testing.T) {
+		game, _ := chess.New()
+		require.NoError(t, game.MakeMove("e2e4"))
+
+		moves, err := b.Moves(game)
+		require.NoError(t, err)
+		require.Len(t, moves, 2)
+
+		// 1... e5 lost and 1... c5 drew.
+		assert.Equal(t, "c7c5", moves[0].Move.UCI())
+		assert.Equal(t, uint16(1), moves[0].Weight)
+		assert.Equal(t, "e7e5", moves[1].Move.UCI())
+		assert.Equal(t, uint16(0), moves[1].Weight)
+	})
+
+	t.Run("castling moves", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"))
+		require.NoError(t, err)
+
+		// e1h1 and e1a1: the king captures its own rook.
+		b := rawBook(t,
+			book.Entry{Key: game.Hash(), Move: 4<<6 | 7, Weight: 2},
+			book.Entry{Key: game.Hash(), Move: 4<<6 | 0, Weight: 1},
+		)
+
+		moves, err := b.Moves(game)
+		require.NoError(t, err)
+		require.Len(t, moves, 2)
+		assert.Equal(t, "e1g1", moves[0].Move.UCI())
+		assert.Equal(t, "e1c1", moves[1].Move.UCI())
+	})
+
+	t.Run("illegal moves are ignored", func(t *testing.T) {
+		game, _ := chess.New()
+
+		// e2e5 and a promotion from e2e4.
+		b := rawBook(t,
+			book.Entry{Key: game.Hash(), Move: 12<<6 | 36, Weight: 1},
+			book.Entry{Key: game.Hash(), Move: 4<<12 | 12<<6 | 28, Weight: 1},
+		)
+
+		moves, err := b.Moves(game)
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+}
+
+func TestBookChoose(t *testing.T) {
+	b := newBook(t)
+
+	t.Run("weighted choice", func(t *testing.T) {
+		game, _ := chess.New()
+		rng := rand.New(rand.NewPCG(1, 2))
+
+		// 1. d4 has a zero weight, so it is never chosen.
+		for range 20 {
+			m, err := b.Choose(game, rng)
+			require.NoError(t, err)
+			assert.Equal(t, "e2e4", m.UCI())
+		}
+	})
+
+	t.Run("seeded choices are reproducible", func(t *testing.T) {
+		game, _ := chess.New()
+		require.NoError(t, game.MakeMove("e2e4"))
+
+		var first, second []string
+		rng := rand.New(rand.NewPCG(3, 4))
+		for range 10 {
+			m, err := b.Choose(game, rng)
+			require.NoError(t, err)
+			first = append(first, m.UCI())
+		}
+
+		rng = rand.New(rand.NewPCG(3, 4))
+		for range 10 {
+			m, err := b.Choose(game, rng)
+			require.NoError(t, err)
+			second = append(second, m.UCI())
+		}
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("zero weights", func(t *testing.T) {
+		game, _ := chess.New()
+		b := rawBook(t,
+			book.Entry{Key: game.Hash(), Move: 12<<6 | 28},
+			book.Entry{Key: game.Hash(), Move: 11<<6 | 27},
+		)
+
+		seen := map[string]bool{}
+		rng := rand.New(rand.NewPCG(5, 6))
+		for range 50 {
+			m, err := b.Choose(game, rng)
+			require.NoError(t, err)
+			seen[m.UCI()] = true
+		}
+
+		assert.Equal(t, map[string]bool{"e2e4": true, "d2d4": true}, seen)
+	})
+
+	t.Run("position not found", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/8/4K2R w K - 0 1"))
+		require.NoError(t, err)
+
+		_, err = b.Choose(game, nil)
+		assert.ErrorIs(t, err, book.ErrNotFound)
+	})
+}
+
+func TestEncodeMove(t *testing.T) {
+	t.Run("castling moves", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"))
+		require.NoError(t, err)
+
+		m, err := game.ParseMove("e1g1")
+		require.NoError(t, err)
+		assert.Equal(t, uint16(4<<6|7), book.EncodeMove(game, m))
+
+		m, err = game.ParseMove("e1c1")
+		require.NoError(t, err)
+		assert.Equal(t, uint16(4<<6|0), book.EncodeMove(game, m))
+	})
+
+	t.Run("promotions", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1"))
+		require.NoError(t, err)
+
+		for uci, code := range map[string]uint16{"b7b8n": 1, "b7b8b": 2, "b7b8r": 3, "b7b8q": 4} {
+			m, err := game.ParseMove(uci)
+			require.NoError(t, err)
+
+			encoded := book.EncodeMove(game, m)
+			assert.Equal(t, code<<12|49<<6|57, encoded)
+
+			decoded, ok := book.DecodeMove(game, encoded)
+			require.True(t, ok)
+			assert.Equal(t, uci, decoded.UCI())
+		}
+	})
+}
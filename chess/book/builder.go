@@ -0,0 +1,211 @@
+package book
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+)
+
+// BuilderOption is a function that configures a builder.
+type BuilderOption func(*Builder)
+
+// WithMaxPly sets the number of plies of every game added to the book. By
+// default, every move of the games is added.
+func WithMaxPly(n int) BuilderOption {
+	return func(b *Builder) {
+		b.maxPly = n
+	}
+}
+
+// WithMinCount sets the number of games a move must be played in to be
+// written to the book. By default, every move is written.
+func WithMinCount(n int) BuilderOption {
+	return func(b *Builder) {
+		b.minCount = n
+	}
+}
+
+// stats are the statistics of a move in a position.
+type stats struct {
+	// count is the number of games the move was played in.
+	count int
+	// points are the points of the side that played the move: 2 for every
+	// win and 1 for every draw.
+	points int
+}
+
+// Builder builds Polyglot books from games.
+//
+// The weight of every move is the score of the side that played it in the
+// games: 2 points for every win and 1 for every draw. The weights are scaled
+// down when they don't fit in 16 bits.
+type Builder struct {
+	maxPly   int
+	minCount int
+
+	// moves are the statistics of the moves, indexed by the key of the
+	// position and the Polyglot move.
+	moves map[uint64]map[uint16]*stats
+}
+
+// NewBuilder creates a new book builder.
+func NewBuilder(opts ...BuilderOption) *Builder {
+	b := &Builder{minCount: 1, moves: make(map[uint64]map[uint16]*stats)}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// AddGame adds the moves of a game to the book. The result is the PGN result
+// of the game (e.g. "1-0"); the moves of unfinished games are counted
+// without points.
+func (b *Builder) AddGame(game *chess.Chess, result string) error {
+	opts := []chess.Option{chess.WithParallelism(1), chess.WithFEN(game.StartingFEN())}
+	if game.IsChess960() {
+		opts = append(opts, chess.WithChess960())
+	}
+
+	replay, err := chess.New(opts...)
+	if err != nil {
+		return fmt.Errorf("failed to replay game: %w", err)
+	}
+
+	for ply, m := range game.History() {
+		if b.maxPly > 0 && ply >= b.maxPly {
+			break
+		}
+
+		key, move := replay.Hash(), EncodeMove(replay, m)
+		if b.moves[key] == nil {
+			b.moves[key] = make(map[uint16]*stats)
+		}
+
+		s := b.moves[key][move]
+		if s == nil {
+			s = &stats{}
+			b.moves[key][move] = s
+		}
+
+		s.count++
+		s.points += points(result, replay.Turn() == gochess.White)
+
+		if err := replay.PlayMove(m); err != nil {
+			return fmt.Errorf("failed to replay game: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// AddPGN adds the mainlines of the games of a PGN stream to the book and
+// returns the number of games added.
+//
+// The games with syntax errors or illegal moves are skipped.
+func (b *Builder) AddPGN(r io.Reader) (int, error) {
+	reader := pgn.NewReader(r)
+
+	var added int
+	for {
+		g, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return added, nil
+		}
+
+		var syntaxErr *pgn.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			continue
+		}
+
+		if err != nil {
+			return added, fmt.Errorf("failed to read PGN: %w", err)
+		}
+
+		game, err := chess.FromPGNGame(g, nil)
+		if err != nil {
+			continue
+		}
+
+		result := g.Result
+		if result == pgn.ResultOngoing {
+			result = g.Tags.Get("Result")
+		}
+
+		if err := b.AddGame(game, result); err != nil {
+			return added, err
+		}
+
+		added++
+	}
+}
+
+// Entries returns the entries of the book, sorted by key and, for every key,
+// by weight from the highest to the lowest.
+func (b *Builder) Entries() []Entry {
+	var entries []Entry
+	maxPoints := 0
+	for key, moves := range b.moves {
+		for move, s := range moves {
+			if s.count < b.minCount {
+				continue
+			}
+
+			entries = append(entries, Entry{Key: key, Move: move})
+			maxPoints = max(maxPoints, s.points)
+		}
+	}
+
+	for i, e := range entries {
+		weight := b.moves[e.Key][e.Move].points
+		if maxPoints > 0xffff {
+			weight = weight * 0xffff / maxPoints
+		}
+
+		entries[i].Weight = uint16(weight)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(a.Key, b.Key),
+			cmp.Compare(b.Weight, a.Weight),
+			cmp.Compare(a.Move, b.Move),
+		)
+	})
+
+	return entries
+}
+
+// WriteTo writes the book to w in the Polyglot format.
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	entries := b.Entries()
+	buf := make([]byte, 0, len(entries)*EntrySize)
+	for _, e := range entries {
+		buf = appendEntry(buf, e)
+	}
+
+	n, err := w.Write(buf)
+	if err != nil {
+		return int64(n), fmt.Errorf("failed to write book: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// points returns the points of a result for white or black.
+func points(result string, white bool) int {
+	switch {
+	case result == pgn.ResultDraw:
+		return 1
+	case result == pgn.ResultWhiteWins && white, result == pgn.ResultBlackWins && !white:
+		return 2
+	default:
+		return 0
+	}
+}
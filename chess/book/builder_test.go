@@ -0,0 +1,96 @@
+package book_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/book"
+	"github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+)
+
+func TestBuilderAddGame(t *testing.T) {
+	t.Run("points by result", func(t *testing.T) {
+		game, _ := chess.New()
+		require.NoError(t, game.MakeMove("e2e4"))
+		require.NoError(t, game.MakeMove("e7e5"))
+
+		builder := book.NewBuilder()
+		require.NoError(t, builder.AddGame(game, pgn.ResultWhiteWins))
+
+		entries := builder.Entries()
+		require.Len(t, entries, 2)
+
+		start, _ := chess.New()
+		for _, e := range entries {
+			if e.Key == start.Hash() {
+				assert.Equal(t, uint16(2), e.Weight)
+			} else {
+				assert.Equal(t, uint16(0), e.Weight)
+			}
+		}
+	})
+
+	t.Run("the game is not modified", func(t *testing.T) {
+		game, _ := chess.New()
+		require.NoError(t, game.MakeMove("e2e4"))
+		fen := game.FEN()
+
+		require.NoError(t, book.NewBuilder().AddGame(game, pgn.ResultDraw))
+		assert.Equal(t, fen, game.FEN())
+	})
+}
+
+func TestBuilderAddPGN(t *testing.T) {
+	t.Run("entries sorted by key", func(t *testing.T) {
+		builder := book.NewBuilder()
+		_, err := builder.AddPGN(strings.NewReader(games))
+		require.NoError(t, err)
+
+		entries := builder.Entries()
+		assert.Len(t, entries, 11)
+		for i := 1; i < len(entries); i++ {
+			assert.LessOrEqual(t, entries[i-1].Key, entries[i].Key)
+		}
+	})
+
+	t.Run("illegal games are skipped", func(t *testing.T) {
+		builder := book.NewBuilder()
+		n, err := builder.AddPGN(strings.NewReader("[Result \"*\"]\n\n1. e5 *\n\n" + games))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+	})
+}
+
+func TestWithMaxPly(t *testing.T) {
+	builder := book.NewBuilder(book.WithMaxPly(1))
+	_, err := builder.AddPGN(strings.NewReader(games))
+	require.NoError(t, err)
+
+	entries := builder.Entries()
+	require.Len(t, entries, 2)
+
+	game, _ := chess.New()
+	for _, e := range entries {
+		assert.Equal(t, game.Hash(), e.Key)
+	}
+}
+
+func TestWithMinCount(t *testing.T) {
+	builder := book.NewBuilder(book.WithMinCount(2))
+	_, err := builder.AddPGN(strings.NewReader(games))
+	require.NoError(t, err)
+
+	// Only 1. e4 was played twice.
+	entries := builder.Entries()
+	require.Len(t, entries, 1)
+
+	game, _ := chess.New()
+	m, ok := book.DecodeMove(game, entries[0].Move)
+	require.True(t, ok)
+	assert.Equal(t, "e2e4", m.UCI())
+	assert.Equal(t, uint16(3), entries[0].Weight)
+}
@@ -0,0 +1,268 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+const (
+	// kingSide is the index of the kingside castle in castlingRights.
+	kingSide = 0
+	// queenSide is the index of the queenside castle in castlingRights.
+	queenSide = 1
+	// noCastle marks a castle that is not available in castlingRights.
+	noCastle = -1
+)
+
+// castlingRights holds the file of the castling rook of every available
+// castle, indexed by color index and side, or noCastle if the castle is not
+// available.
+type castlingRights [2][2]int
+
+// defaultCastlingRights are the castling rights of the standard initial position.
+var defaultCastlingRights = castlingRights{{7, 0}, {7, 0}}
+
+// noCastlingRights are the castling rights when no castle is available.
+var noCastlingRights = castlingRights{{noCastle, noCastle}, {noCastle, noCastle}}
+
+// backRank returns the row of the board where the pieces of a color start.
+func backRank(color gochess.Piece) int {
+	if color == gochess.Black {
+		return 0
+	}
+
+	return 7
+}
+
+// castleSquares returns the target of the king and the origin and target
+// of the rook of a castle move.
+//
+// In Chess960 the target of a castle move is the square of the castling
+// rook. Otherwise, it is the target of the king and the rook is in the
+// corner of that side.
+func (c Chess) castleSquares(m Move) (kingTarget, rookOrigin, rookTarget gochess.Coordinate) {
+	y := m.From.Y
+	kingside := m.To.X > m.From.X
+
+	rookOrigin = gochess.Coor(0, y)
+	kingTarget, rookTarget = gochess.Coor(2, y), gochess.Coor(3, y)
+	if kingside {
+		rookOrigin = gochess.Coor(7, y)
+		kingTarget, rookTarget = gochess.Coor(6, y), gochess.Coor(5, y)
+	}
+
+	if c.config.Chess960 {
+		rookOrigin = m.To
+	}
+
+	return kingTarget, rookOrigin, rookTarget
+}
+
+// parseCastlingRights parses the castling field of a FEN string.
+//
+// Standard chess only accepts the KQkq notation. In Chess960, both X-FEN
+// (KQkq and the file of the rook when it is not the outermost one) and
+// Shredder-FEN (the files of the rooks) notations are accepted. The king of
+// every color with castling rights must be on its back rank.
+//
+// The board must be already loaded.
+func (c Chess) parseCastlingRights(castles string) (castlingRights, [2]int, error) {
+	rights := noCastlingRights
+	kingFiles := [2]int{4, 4}
+	if castles == "-" {
+		return rights, kingFiles, nil
+	}
+
+	if !c.config.Chess960 {
+		if err := c.validateCastles(castles); err != nil {
+			return rights, kingFiles, err
+		}
+
+		for _, castle := range castles {
+			color := colorIndex(gochess.Pieces[string(castle)] & (gochess.White | gochess.Black))
+			if castle == 'K' || castle == 'k' {
+				rights[color][kingSide] = 7
+			} else {
+				rights[color][queenSide] = 0
+			}
+		}
+
+		return rights, kingFiles, nil
+	}
+
+	seen := map[rune]bool{}
+	for _, castle := range castles {
+		if seen[castle] {
+			return rights, kingFiles, errors.New("invalid castles")
+		}
+		seen[castle] = true
+
+		color := gochess.White
+		if castle >= 'a' && castle <= 'z' {
+			color = gochess.Black
+		}
+
+		kingFile, ok := c.backRankKingFile(color)
+		if !ok {
+			return rights, kingFiles, errors.New("invalid castles: the king is not on its back rank")
+		}
+		kingFiles[colorIndex(color)] = kingFile
+
+		var rookFile int
+		switch lower := castle | 0x20; {
+		case lower == 'k':
+			rookFile = c.outermostRookFile(color, kingFile, 1)
+		case lower == 'q':
+			rookFile = c.outermostRookFile(color, kingFile, -1)
+		case lower >= 'a' && lower <= 'h':
+			rookFile = int(lower - 'a')
+		default:
+			return rights, kingFiles, errors.New("invalid castles")
+		}
+
+		if rookFile < 0 || rookFile == kingFile ||
+			c.pieceAt(gochess.Coor(rookFile, backRank(color))) != color|gochess.Rook {
+			return rights, kingFiles, errors.New("invalid castles: the rook is not on its square")
+		}
+
+		side := kingSide
+		if rookFile < kingFile {
+			side = queenSide
+		}
+
+		if rights[colorIndex(color)][side] != noCastle {
+			return rights, kingFiles, errors.New("invalid castles")
+		}
+		rights[colorIndex(color)][side] = rookFile
+	}
+
+	return rights, kingFiles, nil
+}
+
+// backRankKingFile returns the file of the king of the given color if it
+// is on its back rank.
+func (c Chess) backRankKingFile(color gochess.Piece) (int, bool) {
+	for x := range 8 {
+		if c.pieceAt(gochess.Coor(x, backRank(color))) == color|gochess.King {
+			return x, true
+		}
+	}
+
+	return 0, false
+}
+
+// outermostRookFile returns the file of the outermost rook of the given
+// color on its back rank, looking from the king to the given direction.
+// It returns -1 if there is no rook.
+func (c Chess) outermostRookFile(color gochess.Piece, kingFile, dir int) int {
+	file := -1
+	for x := kingFile + dir; x >= 0 && x < 8; x += dir {
+		if c.pieceAt(gochess.Coor(x, backRank(color))) == color|gochess.Rook {
+			file = x
+		}
+	}
+
+	return file
+}
+
+// castlingFEN returns the castling field of the FEN string.
+//
+// Standard chess uses the KQkq notation. Chess960 uses X-FEN: KQkq when the
+// castling rook is the outermost rook of its side, or the file of the rook
+// otherwise. If shredder is true, the files of the rooks are always used.
+func (c Chess) castlingFEN(shredder bool) string {
+	var sb strings.Builder
+	for _, color := range []gochess.Piece{gochess.White, gochess.Black} {
+		for _, side := range []int{kingSide, queenSide} {
+			rookFile := c.castles[colorIndex(color)][side]
+			if rookFile == noCastle {
+				continue
+			}
+
+			letter := 'k'
+			if side == queenSide {
+				letter = 'q'
+			}
+
+			if shredder {
+				letter = rune('a' + rookFile)
+			} else if c.config.Chess960 {
+				dir := 1
+				if side == queenSide {
+					dir = -1
+				}
+
+				if c.outermostRookFile(color, c.castleKingFiles[colorIndex(color)], dir) != rookFile {
+					letter = rune('a' + rookFile)
+				}
+			}
+
+			if color == gochess.White {
+				letter -= 'a' - 'A'
+			}
+
+			sb.WriteRune(letter)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "-"
+	}
+
+	return sb.String()
+}
+
+// Chess960StartingFEN returns the FEN string of the Chess960 starting
+// position with the given index, using the standard numbering scheme by
+// Reinhard Scharnagl. Index 518 is the standard chess starting position.
+//
+// It returns an error if the index is not in the range [0, 959].
+func Chess960StartingFEN(n int) (string, error) {
+	if n < 0 || n > 959 {
+		return "", fmt.Errorf("invalid Chess960 position index: %d", n)
+	}
+
+	var rank [8]byte
+
+	// The bishops are placed on the light and dark squares respectively.
+	rank[2*(n%4)+1] = 'b'
+	n /= 4
+	rank[2*(n%4)] = 'b'
+	n /= 4
+
+	// The queen is placed on one of the six remaining squares.
+	placeOnEmpty(&rank, n%6, 'q')
+	n /= 6
+
+	// The two knights are placed on the five remaining squares.
+	knights := [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+	placeOnEmpty(&rank, knights[n][1], 'n')
+	placeOnEmpty(&rank, knights[n][0], 'n')
+
+	// The king is placed between the rooks on the three remaining squares.
+	placeOnEmpty(&rank, 0, 'r')
+	placeOnEmpty(&rank, 0, 'k')
+	placeOnEmpty(&rank, 0, 'r')
+
+	black := string(rank[:])
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1",
+		black, strings.ToUpper(black)), nil
+}
+
+// placeOnEmpty places a piece on the nth empty square of the rank.
+func placeOnEmpty(rank *[8]byte, n int, piece byte) {
+	for i := range rank {
+		if rank[i] != 0 {
+			continue
+		}
+
+		if n == 0 {
+			rank[i] = piece
+			return
+		}
+		n--
+	}
+}
@@ -0,0 +1,227 @@
+package chess_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chess960PerftSuite holds well known Chess960 perft results from the Chess
+// Programming Wiki, using the Shredder-FEN castling notation.
+const chess960PerftSuite = `
+bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9 ;D1 21 ;D2 528 ;D3 12189
+2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9 ;D1 21 ;D2 807 ;D3 18002
+b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9 ;D1 20 ;D2 479 ;D3 10471
+`
+
+func TestChess960StartingFEN(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected string
+	}{
+		{
+			name:     "First position",
+			n:        0,
+			expected: "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1",
+		},
+		{
+			name:     "Standard position",
+			n:        518,
+			expected: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		},
+		{
+			name:     "Last position",
+			n:        959,
+			expected: "rkrnnqbb/pppppppp/8/8/8/8/PPPPPPPP/RKRNNQBB w KQkq - 0 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fen, err := chess.Chess960StartingFEN(tt.n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fen)
+		})
+	}
+
+	t.Run("Every position is valid", func(t *testing.T) {
+		seen := map[string]bool{}
+		for n := range 960 {
+			fen, err := chess.Chess960StartingFEN(n)
+			require.NoError(t, err)
+			require.False(t, seen[fen], "position %d is repeated", n)
+			seen[fen] = true
+
+			c, err := chess.New(chess.WithChess960Position(n), chess.WithParallelism(1))
+			require.NoError(t, err, "position %d", n)
+			assert.Equal(t, fen, c.FEN())
+		}
+	})
+
+	t.Run("Invalid index", func(t *testing.T) {
+		_, err := chess.Chess960StartingFEN(-1)
+		assert.Error(t, err)
+
+		_, err = chess.Chess960StartingFEN(960)
+		assert.Error(t, err)
+
+		_, err = chess.New(chess.WithChess960Position(960))
+		assert.Error(t, err)
+	})
+}
+
+func TestChess960_Perft(t *testing.T) {
+	cases, err := chess.ParsePerftSuite(strings.NewReader(chess960PerftSuite))
+	require.NoError(t, err)
+
+	mismatches, err := chess.RunPerftSuite(cases, 0, chess.WithChess960())
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestChess960_Castling(t *testing.T) {
+	t.Run("King to rook notation", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1"))
+		require.NoError(t, err)
+
+		// Act & Assert
+		assert.Contains(t, c.AvailableMoves(), "b1a1")
+		assert.Contains(t, c.AvailableMoves(), "b1h1")
+
+		require.NoError(t, c.MakeMove("b1h1"))
+		assert.Equal(t, "rk5r/8/8/8/8/8/8/R4RK1 b kq - 1 1", c.FEN())
+
+		require.NoError(t, c.MakeMove("b8a8"))
+		assert.Equal(t, "2kr3r/8/8/8/8/8/8/R4RK1 w - - 2 2", c.FEN())
+	})
+
+	t.Run("King already on its target", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("6kr/8/8/8/8/8/8/6KR w Kk - 0 1"))
+		require.NoError(t, err)
+
+		require.NoError(t, c.MakeMove("g1h1"))
+		assert.Equal(t, "6kr/8/8/8/8/8/8/5RK1 b k - 1 1", c.FEN())
+
+		c.UnmakeMove()
+		assert.Equal(t, "6kr/8/8/8/8/8/8/6KR w Kk - 0 1", c.FEN())
+		assert.Contains(t, c.AvailableMoves(), "g1h1")
+	})
+
+	t.Run("Rook and king swap squares", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("4k3/8/8/8/8/8/8/5KR1 w G - 0 1"))
+		require.NoError(t, err)
+
+		require.NoError(t, c.MakeMove("f1g1"))
+		assert.Equal(t, "4k3/8/8/8/8/8/8/5RK1 b - - 1 1", c.FEN())
+	})
+
+	t.Run("Castling from the starting position", func(t *testing.T) {
+		// The king on d1 and the rook on c1 can swap squares.
+		c, err := chess.New(chess.WithChess960Position(944))
+		require.NoError(t, err)
+
+		require.NoError(t, c.MakeMove("d1c1"))
+		assert.Equal(t, "bbrkrnnq/pppppppp/8/8/8/8/PPPPPPPP/BBKRRNNQ b kq - 1 1", c.FEN())
+	})
+
+	t.Run("Path must be empty", func(t *testing.T) {
+		// The king target square g1 is occupied by a knight.
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("4k3/8/8/8/8/8/8/1K4NR w K - 0 1"))
+		require.NoError(t, err)
+
+		assert.NotContains(t, c.AvailableMoves(), "b1h1")
+	})
+
+	t.Run("King path must not be attacked", func(t *testing.T) {
+		// The black rook on e8 attacks e1, a square the king passes through.
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("k3r3/8/8/8/8/8/8/1K5R w K - 0 1"))
+		require.NoError(t, err)
+
+		assert.NotContains(t, c.AvailableMoves(), "b1h1")
+	})
+
+	t.Run("Rook path may be attacked", func(t *testing.T) {
+		// The black rook on b8 attacks b1, a square only the rook passes through.
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("1r2k3/8/8/8/8/8/8/R1K5 w Q - 0 1"))
+		require.NoError(t, err)
+
+		assert.Contains(t, c.AvailableMoves(), "c1a1")
+	})
+
+	t.Run("SAN", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1"))
+		require.NoError(t, err)
+
+		san, err := c.SAN("b1a1")
+		require.NoError(t, err)
+		assert.Equal(t, "O-O-O", san)
+
+		uci, err := c.FromSAN("O-O")
+		require.NoError(t, err)
+		assert.Equal(t, "b1h1", uci)
+	})
+
+	t.Run("Standard chess keeps the king target notation", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"))
+		require.NoError(t, err)
+
+		assert.Contains(t, c.AvailableMoves(), "e1g1")
+		assert.NotContains(t, c.AvailableMoves(), "e1h1")
+	})
+}
+
+func TestChess960_FEN(t *testing.T) {
+	t.Run("X-FEN uses the file of inner rooks", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("k7/8/8/8/8/8/8/1K2R2R w E - 0 1"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "k7/8/8/8/8/8/8/1K2R2R w E - 0 1", c.FEN())
+		assert.Equal(t, "k7/8/8/8/8/8/8/1K2R2R w E - 0 1", c.ShredderFEN())
+		assert.Contains(t, c.AvailableMoves(), "b1e1")
+		assert.NotContains(t, c.AvailableMoves(), "b1h1")
+	})
+
+	t.Run("Shredder-FEN is normalized to X-FEN", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1", c.FEN())
+		assert.Equal(t, "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1", c.ShredderFEN())
+	})
+
+	t.Run("Option order does not matter", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1"), chess.WithChess960())
+		require.NoError(t, err)
+
+		assert.True(t, c.IsChess960())
+		assert.Contains(t, c.AvailableMoves(), "b1h1")
+	})
+
+	t.Run("Invalid castles", func(t *testing.T) {
+		fens := []string{
+			// There is no rook on the c-file.
+			"rk5r/8/8/8/8/8/8/RK5R w C - 0 1",
+			// The king is not on its back rank.
+			"rk5r/8/8/8/8/8/1K6/R6R w A - 0 1",
+			// Two castles on the same side.
+			"k7/8/8/8/8/8/8/1K2R2R w EH - 0 1",
+			// Unknown character.
+			"rk5r/8/8/8/8/8/8/RK5R w X - 0 1",
+		}
+
+		for _, fen := range fens {
+			_, err := chess.New(chess.WithChess960(), chess.WithFEN(fen))
+			assert.Error(t, err, fen)
+		}
+	})
+
+	t.Run("Standard chess rejects Shredder-FEN", func(t *testing.T) {
+		_, err := chess.New(chess.WithFEN("r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,171 @@
+// Package cecp implements the Chess Engine Communication Protocol (version
+// 2), spoken by XBoard, WinBoard and many older GUIs and tournament tools.
+//
+// Server runs the engine side of the protocol on top of a uci.Searcher, so
+// the same searcher can be served with both protocols. Engine is the
+// controller side, which drives an external CECP engine.
+package cecp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mateScore is the base of the mate scores in the thinking output: a mate
+// in n moves is written as mateScore+n, and being mated as -mateScore-n.
+const mateScore = 100000
+
+// Thinking is a line of the thinking output of an engine (e.g.
+// "9 156 1084 48000 Nf3 Nc6 Nc3 Nf6").
+type Thinking struct {
+	// Depth is the depth of the search, in plies.
+	Depth int
+	// Score is the evaluation in centipawns from the point of view of the
+	// engine. Mate scores are written as 100000+n moves.
+	Score int
+	// Time is the time searched.
+	Time time.Duration
+	// Nodes is the number of nodes searched.
+	Nodes uint64
+	// PV is the principal variation, as written by the engine.
+	PV []string
+}
+
+// ParseThinking parses a line of thinking output. The fields after the
+// number of nodes are the principal variation.
+func ParseThinking(line string) (Thinking, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return Thinking{}, fmt.Errorf("invalid thinking output: %q", line)
+	}
+
+	// Some engines mark the depths of incomplete iterations (e.g. "9&").
+	depth, err := strconv.Atoi(strings.TrimRight(fields[0], "&."))
+	if err != nil {
+		return Thinking{}, fmt.Errorf("invalid thinking depth: %q", line)
+	}
+
+	score, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Thinking{}, fmt.Errorf("invalid thinking score: %q", line)
+	}
+
+	centiseconds, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return Thinking{}, fmt.Errorf("invalid thinking time: %q", line)
+	}
+
+	nodes, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return Thinking{}, fmt.Errorf("invalid thinking nodes: %q", line)
+	}
+
+	return Thinking{
+		Depth: depth,
+		Score: score,
+		Time:  time.Duration(centiseconds) * 10 * time.Millisecond,
+		Nodes: nodes,
+		PV:    fields[4:],
+	}, nil
+}
+
+// String returns the thinking as a line of thinking output.
+func (t Thinking) String() string {
+	s := fmt.Sprintf("%d %d %d %d", t.Depth, t.Score, t.Time.Milliseconds()/10, t.Nodes)
+	if len(t.PV) > 0 {
+		s += " " + strings.Join(t.PV, " ")
+	}
+
+	return s
+}
+
+// Level is a time control set by the level command.
+type Level struct {
+	// Moves is the number of moves of each time control, or 0 for the
+	// whole game.
+	Moves int
+	// Base is the time of each time control.
+	Base time.Duration
+	// Increment is the time added after every move.
+	Increment time.Duration
+}
+
+// ParseLevel parses the arguments of a level command (e.g. "40 5 0" or
+// "0 2:30 1"). The base time is in minutes, optionally with seconds, and
+// the increment in seconds.
+func ParseLevel(args []string) (Level, error) {
+	if len(args) != 3 {
+		return Level{}, fmt.Errorf("invalid level: %q", strings.Join(args, " "))
+	}
+
+	moves, err := strconv.Atoi(args[0])
+	if err != nil || moves < 0 {
+		return Level{}, fmt.Errorf("invalid level moves: %q", args[0])
+	}
+
+	minutes, seconds, hasSeconds := strings.Cut(args[1], ":")
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return Level{}, fmt.Errorf("invalid level base time: %q", args[1])
+	}
+	base := time.Duration(m) * time.Minute
+	if hasSeconds {
+		s, err := strconv.Atoi(seconds)
+		if err != nil || s < 0 || s >= 60 {
+			return Level{}, fmt.Errorf("invalid level base time: %q", args[1])
+		}
+		base += time.Duration(s) * time.Second
+	}
+
+	increment, err := strconv.ParseFloat(args[2], 64)
+	if err != nil || increment < 0 {
+		return Level{}, fmt.Errorf("invalid level increment: %q", args[2])
+	}
+
+	return Level{
+		Moves:     moves,
+		Base:      base,
+		Increment: time.Duration(increment * float64(time.Second)),
+	}, nil
+}
+
+// String returns the arguments of the level command of the time control.
+func (l Level) String() string {
+	minutes := int(l.Base / time.Minute)
+	base := strconv.Itoa(minutes)
+	if seconds := int(l.Base % time.Minute / time.Second); seconds > 0 {
+		base += fmt.Sprintf(":%02d", seconds)
+	}
+
+	return fmt.Sprintf("%d %s %s", l.Moves, base, strconv.FormatFloat(l.Increment.Seconds(), 'f', -1, 64))
+}
+
+// ParseFeatures parses the arguments of a feature command (e.g.
+// `setboard=1 myname="Gopher 1.0" done=1`). Quoted values may have spaces.
+func ParseFeatures(args string) (map[string]string, error) {
+	features := make(map[string]string)
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		name, rest, ok := strings.Cut(args, "=")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid feature: %q", args)
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated feature value: %q", args)
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		features[name] = value
+		args = rest
+	}
+
+	return features, nil
+}
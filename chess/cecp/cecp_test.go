@@ -0,0 +1,90 @@
+package cecp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess/cecp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThinking(t *testing.T) {
+	t.Run("Thinking output", func(t *testing.T) {
+		thinking, err := cecp.ParseThinking("9 156 1084 48000 Nf3 Nc6 Nc3 Nf6")
+		require.NoError(t, err)
+
+		assert.Equal(t, cecp.Thinking{
+			Depth: 9,
+			Score: 156,
+			Time:  10840 * time.Millisecond,
+			Nodes: 48000,
+			PV:    []string{"Nf3", "Nc6", "Nc3", "Nf6"},
+		}, thinking)
+		assert.Equal(t, "9 156 1084 48000 Nf3 Nc6 Nc3 Nf6", thinking.String())
+	})
+
+	t.Run("Marked depth", func(t *testing.T) {
+		thinking, err := cecp.ParseThinking("12& -20 5 100")
+		require.NoError(t, err)
+
+		assert.Equal(t, 12, thinking.Depth)
+		assert.Equal(t, -20, thinking.Score)
+		assert.Empty(t, thinking.PV)
+	})
+
+	t.Run("Invalid lines", func(t *testing.T) {
+		for _, line := range []string{"9 156 1084", "x 1 2 3", "1 x 2 3", "1 2 x 3", "1 2 3 x"} {
+			_, err := cecp.ParseThinking(line)
+			assert.Error(t, err, line)
+		}
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	t.Run("Levels", func(t *testing.T) {
+		tests := []struct {
+			args  []string
+			level cecp.Level
+		}{
+			{[]string{"40", "5", "0"}, cecp.Level{Moves: 40, Base: 5 * time.Minute}},
+			{[]string{"0", "2:30", "1"}, cecp.Level{Base: 2*time.Minute + 30*time.Second, Increment: time.Second}},
+			{[]string{"0", "1", "0.5"}, cecp.Level{Base: time.Minute, Increment: 500 * time.Millisecond}},
+		}
+
+		for _, tt := range tests {
+			level, err := cecp.ParseLevel(tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.level, level)
+
+			roundTrip, err := cecp.ParseLevel([]string{tt.args[0], tt.args[1], tt.args[2]})
+			require.NoError(t, err)
+			assert.Equal(t, level, roundTrip)
+		}
+
+		assert.Equal(t, "0 2:30 1", cecp.Level{Base: 150 * time.Second, Increment: time.Second}.String())
+	})
+
+	t.Run("Invalid levels", func(t *testing.T) {
+		for _, args := range [][]string{{"40", "5"}, {"x", "5", "0"}, {"40", "5:75", "0"}, {"40", "5", "-1"}} {
+			_, err := cecp.ParseLevel(args)
+			assert.Error(t, err, args)
+		}
+	})
+}
+
+func TestParseFeatures(t *testing.T) {
+	features, err := cecp.ParseFeatures(`myname="Gopher 1.0" setboard=1 usermove=1 variants="normal,fischerandom" done=1`)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"myname":   "Gopher 1.0",
+		"setboard": "1",
+		"usermove": "1",
+		"variants": "normal,fischerandom",
+		"done":     "1",
+	}, features)
+
+	_, err = cecp.ParseFeatures(`myname="Gopher`)
+	assert.Error(t, err)
+}
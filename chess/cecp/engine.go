@@ -0,0 +1,557 @@
+package cecp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+var (
+	// ErrClosed is returned when the output of the engine ends while
+	// waiting for a response.
+	ErrClosed = errors.New("engine closed")
+	// ErrIllegalMove is returned when the engine rejects a move.
+	ErrIllegalMove = errors.New("illegal move")
+)
+
+const (
+	// featureTimeout is the time an engine has to send its features, unless
+	// it asks for more time with done=0.
+	featureTimeout = 2 * time.Second
+	// quitTimeout is the time a process has to exit after the quit command
+	// before it is killed.
+	quitTimeout = 5 * time.Second
+	// moveNowTimeout is the time an engine has to move after the ? command
+	// before its move is no longer waited for.
+	moveNowTimeout = time.Second
+	// initialFEN is the FEN string of the initial position.
+	initialFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+)
+
+// ResultError is returned when the engine ends the game instead of moving.
+type ResultError struct {
+	// Result is the PGN result claimed by the engine, or "resign".
+	Result string
+	// Comment is the reason given by the engine.
+	Comment string
+}
+
+// Error implements the error interface.
+func (e *ResultError) Error() string {
+	if e.Comment == "" {
+		return "engine ended the game: " + e.Result
+	}
+
+	return fmt.Sprintf("engine ended the game: %s {%s}", e.Result, e.Comment)
+}
+
+// Engine is a controller of a CECP engine.
+//
+// Commands that wait for a response are serialized, while MoveNow can be
+// called from any goroutine while the engine thinks.
+type Engine struct {
+	// Features are the features sent by the engine in the handshake.
+	Features map[string]string
+
+	// mu serializes the commands that read the output of the engine.
+	mu sync.Mutex
+	// writeMu serializes the writes to the engine.
+	writeMu sync.Mutex
+	w       io.Writer
+	closer  io.Closer
+	cmd     *exec.Cmd
+	pings   int
+	// stale is the number of moves of abandoned searches that are still to
+	// be sent by the engine. They are skipped by readLine.
+	stale int
+
+	// lines receives the lines of the engine. It is closed when the output
+	// ends, after setting readErr.
+	lines   chan string
+	readErr error
+	// done is closed to stop reading the lines of the engine.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEngine returns a controller of the engine reached through rw and
+// sends the xboard and protover commands, accepting the features of the
+// engine.
+//
+// If rw is also an io.Closer, Close closes it.
+func NewEngine(ctx context.Context, rw io.ReadWriter) (*Engine, error) {
+	e := newEngine(rw, rw)
+	if c, ok := rw.(io.Closer); ok {
+		e.closer = c
+	}
+
+	if err := e.handshake(ctx); err != nil {
+		_ = e.release()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// StartEngine starts the engine process and sends the xboard and protover
+// commands, accepting the features of the engine. The standard input and
+// output of cmd must not be set.
+//
+// The process is killed if the handshake fails.
+func StartEngine(ctx context.Context, cmd *exec.Cmd) (*Engine, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	e := newEngine(stdout, stdin)
+	e.closer, e.cmd = stdin, cmd
+
+	if err := e.handshake(ctx); err != nil {
+		_ = e.release()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// newEngine returns a controller reading from r and writing to w, and
+// starts reading the lines of r until its end or the release of the
+// controller.
+func newEngine(r io.Reader, w io.Writer) *Engine {
+	e := &Engine{
+		Features: make(map[string]string),
+		w:        w,
+		lines:    make(chan string, 256),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(e.lines)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case e.lines <- strings.TrimSpace(scanner.Text()):
+			case <-e.done:
+				return
+			}
+		}
+
+		e.readErr = scanner.Err()
+	}()
+
+	return e
+}
+
+// release closes the connection to the engine, if it is an io.Closer, and
+// stops reading its lines. A reader that is not an io.Closer is no longer
+// read after its next line.
+func (e *Engine) release() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.done)
+		if e.closer != nil {
+			err = e.closer.Close()
+		}
+	})
+
+	return err
+}
+
+// handshake sends the xboard and protover commands and reads the features
+// until done=1. Engines that send no done=0 feature have featureTimeout to
+// finish, after which they are taken as protocol version 1 engines.
+func (e *Engine) handshake(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("xboard"); err != nil {
+		return err
+	}
+	if err := e.send("protover 2"); err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, featureTimeout)
+	defer cancel()
+
+	for {
+		line, err := e.readLine(waitCtx)
+		if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read features: %w", err)
+		}
+
+		args, ok := strings.CutPrefix(line, "feature ")
+		if !ok {
+			continue
+		}
+
+		features, err := ParseFeatures(args)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range features {
+			e.Features[name] = value
+			if name == "done" {
+				continue
+			}
+			if err := e.send("accepted " + name); err != nil {
+				return err
+			}
+		}
+
+		switch features["done"] {
+		case "1":
+			return nil
+		case "0":
+			waitCtx = ctx
+		}
+	}
+}
+
+// Name returns the name of the engine sent with the myname feature.
+func (e *Engine) Name() string {
+	return e.Features["myname"]
+}
+
+// NewGame sends the new command: the engine sets the starting position and
+// plays black.
+func (e *Engine) NewGame() error {
+	return e.send("new")
+}
+
+// Variant sends the variant command (e.g. "fischerandom").
+func (e *Engine) Variant(variant string) error {
+	return e.send("variant " + variant)
+}
+
+// Force sends the force command: the engine plays neither color and only
+// checks the moves it receives.
+func (e *Engine) Force() error {
+	return e.send("force")
+}
+
+// PlayOther sends the playother command: the engine plays the color that
+// is not on move.
+func (e *Engine) PlayOther() error {
+	return e.send("playother")
+}
+
+// SetBoard sends the setboard command.
+func (e *Engine) SetBoard(fen string) error {
+	return e.send("setboard " + fen)
+}
+
+// UserMove sends a move to the engine. If the engine is playing the color
+// now on move, it starts thinking and its reply is read with WaitMove.
+func (e *Engine) UserMove(move string) error {
+	if e.Features["usermove"] == "1" {
+		return e.send("usermove " + move)
+	}
+
+	return e.send(move)
+}
+
+// Undo sends the undo command, which takes back one ply.
+func (e *Engine) Undo() error {
+	return e.send("undo")
+}
+
+// Remove sends the remove command, which takes back two plies.
+func (e *Engine) Remove() error {
+	return e.send("remove")
+}
+
+// Level sends the level command with the time control.
+func (e *Engine) Level(l Level) error {
+	return e.send("level " + l.String())
+}
+
+// MoveTime sends the st command with the exact time per move.
+func (e *Engine) MoveTime(d time.Duration) error {
+	return e.send("st " + strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+}
+
+// Depth sends the sd command with the maximum depth of the searches.
+func (e *Engine) Depth(depth int) error {
+	return e.send("sd " + strconv.Itoa(depth))
+}
+
+// Time sends the time and otim commands with the clocks of the engine and
+// its opponent.
+func (e *Engine) Time(engine, opponent time.Duration) error {
+	if err := e.send("time " + strconv.FormatInt(engine.Milliseconds()/10, 10)); err != nil {
+		return err
+	}
+
+	return e.send("otim " + strconv.FormatInt(opponent.Milliseconds()/10, 10))
+}
+
+// Post sends the post or nopost command, which turns the thinking output
+// on or off.
+func (e *Engine) Post(post bool) error {
+	if post {
+		return e.send("post")
+	}
+
+	return e.send("nopost")
+}
+
+// Result sends the result command, telling the engine that the game ended.
+func (e *Engine) Result(result, comment string) error {
+	return e.send("result " + result + " {" + comment + "}")
+}
+
+// MoveNow sends the ? command, so the engine moves as soon as possible.
+func (e *Engine) MoveNow() error {
+	return e.send("?")
+}
+
+// Replay sets up the game on the engine in force mode: it sends the new
+// command, the variant, the starting position if it is not the initial one
+// and the moves of the game. The engine does not think after Replay until
+// Go is called.
+func (e *Engine) Replay(ctx context.Context, game *chess.Chess) error {
+	commands := []string{"new", "force"}
+	if game.IsChess960() {
+		commands = append(commands, "variant fischerandom")
+	}
+
+	if fen := game.StartingFEN(); game.IsChess960() || fen != initialFEN {
+		if e.Features["setboard"] != "1" {
+			return errors.New("engine does not support setboard")
+		}
+		commands = append(commands, "setboard "+fen)
+	}
+
+	for _, command := range commands {
+		if err := e.send(command); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range game.History() {
+		if err := e.UserMove(m.UCI()); err != nil {
+			return err
+		}
+	}
+
+	return e.Ping(ctx)
+}
+
+// Ping sends the ping command and waits for pong, so every previous command
+// has been handled by the engine. It returns ErrIllegalMove if the engine
+// rejected a move in the meantime.
+//
+// Engines without the ping feature are not waited for.
+func (e *Engine) Ping(ctx context.Context) error {
+	if e.Features["ping"] != "1" {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pings++
+	pong := "pong " + strconv.Itoa(e.pings)
+	if err := e.send("ping " + strconv.Itoa(e.pings)); err != nil {
+		return err
+	}
+
+	var illegal error
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read pong: %w", err)
+		}
+
+		if line == pong {
+			return illegal
+		}
+
+		if err := illegalMove(line); err != nil && illegal == nil {
+			illegal = err
+		}
+	}
+}
+
+// Go sends the go command, so the engine plays the color on move, and
+// waits for its move.
+func (e *Engine) Go(ctx context.Context, onThinking func(Thinking)) (string, error) {
+	if err := e.send("go"); err != nil {
+		return "", err
+	}
+
+	return e.WaitMove(ctx, onThinking)
+}
+
+// WaitMove waits for the next move of the engine, as written by the engine
+// (usually coordinate notation, or SAN for engines with san=1). onThinking,
+// if not nil, is called with every line of thinking output.
+//
+// It returns ErrIllegalMove if the engine rejected the last move sent, and
+// a *ResultError if the engine resigned or claimed a result. If ctx is done
+// before the engine moves, the ? command is sent and the move is waited for
+// up to moveNowTimeout, after which the error of ctx is returned.
+func (e *Engine) WaitMove(ctx context.Context, onThinking func(Thinking)) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var ctxErr error
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil && ctxErr == nil && ctx.Err() != nil {
+			ctxErr = ctx.Err()
+			if err := e.send("?"); err != nil {
+				return "", err
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), moveNowTimeout)
+			defer cancel()
+			continue
+		}
+		if err != nil && ctxErr != nil && ctx.Err() != nil {
+			// The move will be sent later, and skipped then.
+			e.stale++
+			return "", ctxErr
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read move: %w", err)
+		}
+
+		if err := illegalMove(line); err != nil {
+			return "", err
+		}
+
+		if move, ok := strings.CutPrefix(line, "move "); ok {
+			return strings.TrimSpace(move), ctxErr
+		}
+
+		if err := resultOf(line); err != nil {
+			return "", err
+		}
+
+		if onThinking != nil && line[0] >= '0' && line[0] <= '9' {
+			if t, err := ParseThinking(line); err == nil {
+				onThinking(t)
+			}
+		}
+	}
+}
+
+// illegalMove returns an error wrapping ErrIllegalMove if the line is an
+// illegal move response.
+func illegalMove(line string) error {
+	if !strings.HasPrefix(line, "Illegal move") {
+		return nil
+	}
+
+	_, move, _ := strings.Cut(line, ":")
+	return fmt.Errorf("%w: %s", ErrIllegalMove, strings.TrimSpace(move))
+}
+
+// resultOf returns a *ResultError if the line is a result or a resignation
+// of the engine.
+func resultOf(line string) error {
+	if line == "resign" {
+		return &ResultError{Result: "resign"}
+	}
+
+	result, comment, _ := strings.Cut(line, " ")
+	switch result {
+	case "1-0", "0-1", "1/2-1/2":
+		comment = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(comment), "{"), "}")
+		return &ResultError{Result: result, Comment: comment}
+	}
+
+	return nil
+}
+
+// Close sends the quit command and releases the engine. A process started
+// with StartEngine is killed if it does not exit in time.
+func (e *Engine) Close() error {
+	quitErr := e.send("quit")
+	closeErr := e.release()
+
+	if e.cmd == nil {
+		return errors.Join(quitErr, closeErr)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- e.cmd.Wait()
+	}()
+
+	var waitErr error
+	select {
+	case waitErr = <-exited:
+	case <-time.After(quitTimeout):
+		_ = e.cmd.Process.Kill()
+		<-exited
+		waitErr = fmt.Errorf("engine did not quit in %s and was killed", quitTimeout)
+	}
+
+	return errors.Join(quitErr, closeErr, waitErr)
+}
+
+// send writes a command to the engine.
+func (e *Engine) send(command string) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+
+	if _, err := io.WriteString(e.w, command+"\n"); err != nil {
+		return fmt.Errorf("failed to send %q: %w", command, err)
+	}
+
+	return nil
+}
+
+// readLine returns the next non empty line of the engine. The moves of
+// abandoned searches are skipped, whatever command is waiting.
+func (e *Engine) readLine(ctx context.Context) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case line, ok := <-e.lines:
+			if !ok {
+				if e.readErr != nil {
+					return "", fmt.Errorf("%w: %w", ErrClosed, e.readErr)
+				}
+				return "", ErrClosed
+			}
+			if e.stale > 0 && strings.HasPrefix(line, "move ") {
+				e.stale--
+				continue
+			}
+			if line != "" {
+				return line, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package cecp_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/cecp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEngine returns a controller of a Server running the fake searcher.
+func newEngine(t *testing.T) *cecp.Engine {
+	t.Helper()
+
+	serverR, clientW := io.Pipe()
+	clientR, serverW := io.Pipe()
+	s := cecp.NewServer("Gopher 1.0", &fakeSearcher{})
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), serverR, serverW)
+		serverW.Close()
+	}()
+
+	e, err := cecp.NewEngine(context.Background(), struct {
+		io.Reader
+		io.WriteCloser
+	}{clientR, clientW})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, e.Close())
+		assert.NoError(t, <-served)
+	})
+
+	return e
+}
+
+func TestEngine(t *testing.T) {
+	t.Run("Handshake", func(t *testing.T) {
+		e := newEngine(t)
+
+		assert.Equal(t, "Gopher 1.0", e.Name())
+		assert.Equal(t, "1", e.Features["setboard"])
+		assert.NoError(t, e.Ping(context.Background()))
+	})
+
+	t.Run("Replay a game and go", func(t *testing.T) {
+		e := newEngine(t)
+
+		game, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"))
+		require.NoError(t, err)
+		require.NoError(t, game.MakeMove("e2e4"))
+
+		require.NoError(t, e.Replay(context.Background(), game))
+		require.NoError(t, e.Post(true))
+		require.NoError(t, e.Level(cecp.Level{Base: time.Minute}))
+		require.NoError(t, e.Time(time.Minute, time.Minute))
+
+		var thinking []cecp.Thinking
+		move, err := e.Go(context.Background(), func(t cecp.Thinking) {
+			thinking = append(thinking, t)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "e8d7", move)
+		assert.Equal(t, []cecp.Thinking{
+			{Depth: 3, Score: 100002, Time: 250 * time.Millisecond, Nodes: 1200, PV: []string{"e8d7"}},
+		}, thinking)
+
+		// The engine plays black and replies to the moves of white.
+		require.NoError(t, e.UserMove("e4e5"))
+		move, err = e.WaitMove(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "d7c6", move)
+	})
+
+	t.Run("Illegal moves", func(t *testing.T) {
+		e := newEngine(t)
+
+		require.NoError(t, e.NewGame())
+		require.NoError(t, e.Force())
+		require.NoError(t, e.UserMove("e2e5"))
+
+		assert.ErrorIs(t, e.Ping(context.Background()), cecp.ErrIllegalMove)
+	})
+
+	t.Run("Game over", func(t *testing.T) {
+		e := newEngine(t)
+
+		require.NoError(t, e.NewGame())
+		require.NoError(t, e.Force())
+		for _, m := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+			require.NoError(t, e.UserMove(m))
+		}
+
+		_, err := e.Go(context.Background(), nil)
+
+		var result *cecp.ResultError
+		require.True(t, errors.As(err, &result))
+		assert.Equal(t, &cecp.ResultError{Result: "0-1", Comment: "Black mates"}, result)
+	})
+
+	t.Run("Context cancellation moves now", func(t *testing.T) {
+		e := newEngine(t)
+
+		require.NoError(t, e.NewGame())
+		require.NoError(t, e.MoveTime(time.Hour))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		move, err := e.Go(ctx, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "a2a3", move)
+	})
+	t.Run("Context cancellation does not wait forever", func(t *testing.T) {
+		// The engine accepts the handshake and never moves.
+		serverR, clientW := io.Pipe()
+		clientR, serverW := io.Pipe()
+		go func() {
+			scanner := bufio.NewScanner(serverR)
+			for scanner.Scan() {
+				if scanner.Text() == "protover 2" {
+					_, _ = io.WriteString(serverW, "feature usermove=1 done=1\n")
+				}
+			}
+		}()
+
+		e, err := cecp.NewEngine(context.Background(), struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		require.NoError(t, err)
+		defer e.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		move, err := e.Go(ctx, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, move)
+
+		// The move of the abandoned search is skipped.
+		_, err = io.WriteString(serverW, "move e2e4\nmove d2d4\n")
+		require.NoError(t, err)
+		move, err = e.WaitMove(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", move)
+	})
+
+	t.Run("Late move read by Ping", func(t *testing.T) {
+		// The engine ignores the ? command and sends the move of the
+		// abandoned search just before the pong.
+		serverR, clientW := io.Pipe()
+		clientR, serverW := io.Pipe()
+		go func() {
+			scanner := bufio.NewScanner(serverR)
+			for scanner.Scan() {
+				switch line := scanner.Text(); {
+				case line == "protover 2":
+					_, _ = io.WriteString(serverW, "feature ping=1 usermove=1 done=1\n")
+				case strings.HasPrefix(line, "ping "):
+					_, _ = io.WriteString(serverW, "move e2e4\npong "+strings.TrimPrefix(line, "ping ")+"\n")
+				case line == "usermove e7e5":
+					_, _ = io.WriteString(serverW, "move d2d4\n")
+				}
+			}
+		}()
+
+		e, err := cecp.NewEngine(context.Background(), struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		require.NoError(t, err)
+		defer e.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = e.Go(ctx, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, e.Ping(context.Background()))
+
+		require.NoError(t, e.UserMove("e7e5"))
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		move, err := e.WaitMove(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", move)
+	})
+}
@@ -0,0 +1,555 @@
+package cecp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+)
+
+// defaultMoveTime is the time of a search when the controller has set no
+// time control nor depth.
+const defaultMoveTime = 5 * time.Second
+
+// ServerOption is a function that configures a server.
+type ServerOption func(*Server)
+
+// WithGameOptions sets the options used to create the games of the server
+// (e.g. chess.WithParallelism).
+func WithGameOptions(opts ...chess.Option) ServerOption {
+	return func(s *Server) {
+		s.gameOpts = append(s.gameOpts, opts...)
+	}
+}
+
+// Server runs the engine side of the CECP protocol on top of a
+// uci.Searcher.
+//
+// It keeps the game played with the controller, runs one search at a time
+// in its own goroutine and plays the best move on the game when the search
+// ends. Moves from the controller are accepted in coordinate notation
+// (e.g. "e2e4", "e7e8q") and in SAN (e.g. "Nf3", "O-O").
+type Server struct {
+	name     string
+	searcher uci.Searcher
+	gameOpts []chess.Option
+
+	// writeMu serializes the writes to the controller.
+	writeMu sync.Mutex
+	w       io.Writer
+
+	game     *chess.Chess
+	chess960 bool
+	// force is true if the engine plays neither color.
+	force bool
+	// color is the color played by the engine.
+	color gochess.Piece
+
+	level    Level
+	moveTime time.Duration
+	depth    int
+	// engineTime and opponentTime are the clocks sent by the time and otim
+	// commands.
+	engineTime, opponentTime time.Duration
+
+	// post is read by the searches to send their thinking output.
+	post atomic.Bool
+
+	// search is the running search, or nil.
+	search *search
+}
+
+// search is a search running in the background.
+type search struct {
+	cancel context.CancelFunc
+	// result receives the result of the search.
+	result chan searchResult
+}
+
+// searchResult is the result of a search.
+type searchResult struct {
+	uci.SearchResult
+	err error
+}
+
+// NewServer returns a server for the engine with the given name, which
+// searches with searcher.
+func NewServer(name string, searcher uci.Searcher, opts ...ServerOption) *Server {
+	// As after the new command, the engine plays black.
+	s := &Server{name: name, searcher: searcher, color: gochess.Black}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Serve reads the commands of the controller from r and writes the
+// responses to w until the quit command, the end of r or ctx being done.
+// At the end of r, a running search is waited for and its move is sent.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	if err := s.newGame(); err != nil {
+		return err
+	}
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	defer s.abort()
+	for {
+		var results chan searchResult
+		if s.search != nil {
+			results = s.search.result
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-results:
+			s.search = nil
+			s.play(result)
+		case line, ok := <-lines:
+			if !ok {
+				s.finish()
+				return <-readErr
+			}
+
+			if quit := s.handle(ctx, line); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// handle runs a command of the controller and returns true if it is quit.
+func (s *Server) handle(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+
+	command, args := fields[0], fields[1:]
+	switch command {
+	case "xboard", "accepted", "rejected", "hard", "easy", "random", "computer", "name", "rating", "ics", "white", "black":
+	case "protover":
+		s.send(fmt.Sprintf("feature myname=%q setboard=1 usermove=1 ping=1 playother=1 san=0 colors=0 "+
+			"sigint=0 sigterm=0 reuse=1 analyze=0 variants=\"normal,fischerandom\" done=1", s.name))
+	case "new":
+		s.abort()
+		s.chess960 = false
+		s.force, s.color = false, gochess.Black
+		s.moveTime, s.depth = 0, 0
+		s.engineTime, s.opponentTime = s.level.Base, s.level.Base
+		if err := s.newGame(); err != nil {
+			s.sendError(err.Error(), line)
+		}
+	case "variant":
+		s.abort()
+		s.variant(line, args)
+	case "force":
+		s.abort()
+		s.force = true
+	case "go":
+		s.abort()
+		s.force, s.color = false, s.game.Turn()
+		s.think(ctx)
+	case "playother":
+		s.abort()
+		s.force, s.color = false, opponent(s.game.Turn())
+	case "usermove":
+		s.abort()
+		if len(args) == 1 {
+			s.userMove(ctx, args[0])
+		} else {
+			s.sendError("missing move", line)
+		}
+	case "?":
+		if s.search != nil {
+			s.search.cancel()
+		}
+	case "setboard":
+		s.abort()
+		s.setBoard(strings.Join(args, " "))
+	case "undo":
+		s.abort()
+		s.undo(line, 1)
+	case "remove":
+		s.abort()
+		s.undo(line, 2)
+	case "level":
+		level, err := ParseLevel(args)
+		if err != nil {
+			s.sendError(err.Error(), line)
+			return false
+		}
+		s.level, s.moveTime = level, 0
+		s.engineTime, s.opponentTime = level.Base, level.Base
+	case "st":
+		seconds, err := strconv.ParseFloat(strings.Join(args, ""), 64)
+		if err != nil {
+			s.sendError("invalid time", line)
+			return false
+		}
+		s.moveTime = time.Duration(seconds * float64(time.Second))
+	case "sd":
+		depth, err := strconv.Atoi(strings.Join(args, ""))
+		if err != nil {
+			s.sendError("invalid depth", line)
+			return false
+		}
+		s.depth = depth
+	case "time", "otim":
+		centiseconds, err := strconv.Atoi(strings.Join(args, ""))
+		if err != nil {
+			s.sendError("invalid time", line)
+			return false
+		}
+		if command == "time" {
+			s.engineTime = time.Duration(centiseconds) * 10 * time.Millisecond
+		} else {
+			s.opponentTime = time.Duration(centiseconds) * 10 * time.Millisecond
+		}
+	case "post":
+		s.post.Store(true)
+	case "nopost":
+		s.post.Store(false)
+	case "ping":
+		s.send("pong " + strings.Join(args, " "))
+	case "result":
+		s.abort()
+		s.force = true
+	case "quit":
+		return true
+	default:
+		// Protocol version 1 sends moves without the usermove command.
+		if s.isMove(command) {
+			s.abort()
+			s.userMove(ctx, command)
+			return false
+		}
+		s.sendError("unknown command", line)
+	}
+
+	return false
+}
+
+// variant runs a variant command.
+func (s *Server) variant(line string, args []string) {
+	if len(args) != 1 || (args[0] != "normal" && args[0] != "fischerandom") {
+		s.sendError("unsupported variant", line)
+		return
+	}
+
+	s.chess960 = args[0] == "fischerandom"
+	if err := s.newGame(); err != nil {
+		s.sendError(err.Error(), line)
+	}
+}
+
+// newGame sets the starting position.
+func (s *Server) newGame() error {
+	game, err := chess.New(s.gameOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to create game: %w", err)
+	}
+
+	s.game = game
+	return nil
+}
+
+// gameOptions returns the options used to create games.
+func (s *Server) gameOptions() []chess.Option {
+	opts := s.gameOpts
+	if s.chess960 {
+		opts = append(opts[:len(opts):len(opts)], chess.WithChess960())
+	}
+
+	return opts
+}
+
+// setBoard runs a setboard command. The game is kept if the FEN is invalid.
+func (s *Server) setBoard(fen string) {
+	game, err := chess.New(s.gameOptions()...)
+	if err == nil {
+		err = game.LoadPosition(fen)
+	}
+
+	if err != nil {
+		s.send("tellusererror Illegal position")
+		return
+	}
+
+	s.game = game
+}
+
+// isMove returns true if the command is a legal move of the game.
+func (s *Server) isMove(command string) bool {
+	_, err := s.parseMove(command)
+	return err == nil
+}
+
+// parseMove returns the legal move written in coordinate notation or SAN.
+func (s *Server) parseMove(move string) (chess.Move, error) {
+	if m, err := s.game.ParseMove(move); err == nil {
+		return m, nil
+	}
+
+	return s.game.ParseSAN(move)
+}
+
+// userMove plays a move of the controller, and starts thinking if the
+// engine has to reply.
+func (s *Server) userMove(ctx context.Context, move string) {
+	m, err := s.parseMove(move)
+	if err == nil {
+		err = s.game.PlayMove(m)
+	}
+
+	if err != nil {
+		s.send("Illegal move: " + move)
+		return
+	}
+
+	if s.sendResult() {
+		return
+	}
+
+	if !s.force && s.game.Turn() == s.color {
+		s.think(ctx)
+	}
+}
+
+// undo takes back the given number of plies.
+func (s *Server) undo(line string, plies int) {
+	if len(s.game.History()) < plies {
+		s.sendError("no moves to undo", line)
+		return
+	}
+
+	for range plies {
+		s.game.UnmakeMove()
+	}
+}
+
+// think starts a search of the current position in the background.
+func (s *Server) think(ctx context.Context) {
+	if s.game.Outcome().IsOver() {
+		s.sendResult()
+		return
+	}
+
+	// Every search gets its own game, so the searcher can modify it.
+	game, err := s.copyGame()
+	if err != nil {
+		s.sendError(err.Error(), "go")
+		return
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	srch := &search{cancel: cancel, result: make(chan searchResult, 1)}
+	s.search = srch
+
+	limits := s.limits()
+	start := time.Now()
+	go func() {
+		defer cancel()
+
+		result, err := s.searcher.Search(searchCtx, game, limits, func(info uci.Info) {
+			if s.post.Load() && info.Score != nil && len(info.PV) > 0 {
+				s.send(thinking(info, time.Since(start)).String())
+			}
+		})
+		if searchCtx.Err() != nil {
+			// The search was stopped, so its error is expected.
+			err = nil
+		}
+		srch.result <- searchResult{SearchResult: result, err: err}
+	}()
+}
+
+// copyGame returns a copy of the game with the same history.
+func (s *Server) copyGame() (*chess.Chess, error) {
+	game, err := chess.New(s.gameOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+
+	if err := game.LoadPosition(s.game.StartingFEN()); err != nil {
+		return nil, err
+	}
+
+	for _, m := range s.game.History() {
+		if err := game.PlayMove(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return game, nil
+}
+
+// limits returns the limits of a search from the time control, the time
+// per move and the depth set by the controller.
+func (s *Server) limits() uci.Limits {
+	limits := uci.Limits{Depth: s.depth, MoveTime: s.moveTime}
+	if s.moveTime == 0 && s.level.Base > 0 {
+		engine, opponent := &limits.WhiteTime, &limits.BlackTime
+		if s.color == gochess.Black {
+			engine, opponent = opponent, engine
+		}
+		*engine, *opponent = s.engineTime, s.opponentTime
+		limits.Clock = true
+		limits.WhiteIncrement, limits.BlackIncrement = s.level.Increment, s.level.Increment
+
+		if s.level.Moves > 0 {
+			played := len(s.game.History()) / 2
+			limits.MovesToGo = s.level.Moves - played%s.level.Moves
+		}
+	}
+
+	if limits.Depth == 0 && limits.MoveTime == 0 && !limits.Clock {
+		limits.MoveTime = defaultMoveTime
+	}
+
+	return limits
+}
+
+// thinking returns the thinking output of an info.
+func thinking(info uci.Info, elapsed time.Duration) Thinking {
+	score := info.Score.CP
+	if info.Score.IsMate {
+		score = mateScore + info.Score.Mate
+		if info.Score.Mate < 0 {
+			score = -mateScore + info.Score.Mate
+		}
+	}
+
+	if info.Time > 0 {
+		elapsed = info.Time
+	}
+
+	return Thinking{Depth: info.Depth, Score: score, Time: elapsed, Nodes: info.Nodes, PV: info.PV}
+}
+
+// play plays the best move of a finished search. The best move is played
+// even if the search failed, as long as the searcher returned one.
+func (s *Server) play(result searchResult) {
+	if result.err != nil && result.BestMove == "" {
+		s.sendError(result.err.Error(), "go")
+		return
+	}
+
+	m, err := s.game.ParseMove(result.BestMove)
+	if err == nil {
+		err = s.game.PlayMove(m)
+	}
+
+	if err != nil {
+		s.send("resign")
+		return
+	}
+
+	s.send("move " + s.moveString(m, result.BestMove))
+	s.sendResult()
+}
+
+// moveString returns the notation of a move played by the engine. In
+// fischerandom games the castling moves are sent as O-O and O-O-O, which is
+// the notation the controllers expect instead of the king capturing its own
+// rook.
+func (s *Server) moveString(m chess.Move, uciMove string) string {
+	if !s.chess960 || !m.IsCastle() {
+		return uciMove
+	}
+
+	if m.To.X > m.From.X {
+		return "O-O"
+	}
+
+	return "O-O-O"
+}
+
+// sendResult sends the result of the game if it is over, and returns true
+// if it is.
+func (s *Server) sendResult() bool {
+	o := s.game.Outcome()
+	if !o.IsOver() {
+		return false
+	}
+
+	comment := o.Termination.String()
+	if o.Termination == chess.TerminationCheckmate {
+		comment = "White mates"
+		if o.Winner == gochess.Black {
+			comment = "Black mates"
+		}
+	}
+
+	s.send(o.Result + " {" + comment + "}")
+	return true
+}
+
+// finish waits for the running search and plays its best move.
+func (s *Server) finish() {
+	if s.search == nil {
+		return
+	}
+
+	result := <-s.search.result
+	s.search = nil
+	s.play(result)
+}
+
+// abort stops the running search, discarding its best move.
+func (s *Server) abort() {
+	if s.search == nil {
+		return
+	}
+
+	s.search.cancel()
+	<-s.search.result
+	s.search = nil
+}
+
+// sendError sends an error about a command.
+func (s *Server) sendError(msg, command string) {
+	s.send("Error (" + msg + "): " + command)
+}
+
+// send writes a line to the controller.
+func (s *Server) send(line string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, _ = io.WriteString(s.w, line+"\n")
+}
+
+// opponent returns the opposite color.
+func opponent(color gochess.Piece) gochess.Piece {
+	if color == gochess.White {
+		return gochess.Black
+	}
+
+	return gochess.White
+}
@@ -0,0 +1,191 @@
+package cecp_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/cecp"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// search is a search received by a fakeSearcher.
+type search struct {
+	fen    string
+	limits uci.Limits
+}
+
+// fakeSearcher plays the first legal move of the position, or bestMove if
+// it is set. Searches of an hour or more wait for their context to be done.
+type fakeSearcher struct {
+	mu       sync.Mutex
+	searches []search
+	bestMove string
+}
+
+// Search records the search and returns the first legal move.
+func (f *fakeSearcher) Search(ctx context.Context, game *chess.Chess, limits uci.Limits, info func(uci.Info)) (uci.SearchResult, error) {
+	f.mu.Lock()
+	f.searches = append(f.searches, search{fen: game.FEN(), limits: limits})
+	f.mu.Unlock()
+
+	moves := game.AvailableMoves()
+	info(uci.Info{Depth: 3, Score: &uci.Score{Mate: 2, IsMate: true}, Nodes: 1200, Time: 250 * time.Millisecond, PV: moves[:1]})
+	if limits.MoveTime >= time.Hour {
+		<-ctx.Done()
+	}
+
+	best := moves[0]
+	if f.bestMove != "" {
+		best = f.bestMove
+	}
+
+	// Stopped searches return their best move with the error of ctx.
+	return uci.SearchResult{BestMove: best}, ctx.Err()
+}
+
+// last returns the last search.
+func (f *fakeSearcher) last() search {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.searches[len(f.searches)-1]
+}
+
+// serve runs the server with the given commands and returns its output
+// lines.
+func serve(t *testing.T, s *cecp.Server, commands ...string) []string {
+	t.Helper()
+
+	var out strings.Builder
+	err := s.Serve(context.Background(), strings.NewReader(strings.Join(commands, "\n")+"\n"), &out)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Feature handshake", func(t *testing.T) {
+		s := cecp.NewServer("Gopher 1.0", &fakeSearcher{})
+
+		out := serve(t, s, "xboard", "protover 2", "accepted setboard", "ping 7")
+
+		require.Len(t, out, 2)
+		features, err := cecp.ParseFeatures(strings.TrimPrefix(out[0], "feature "))
+		require.NoError(t, err)
+		assert.Equal(t, "Gopher 1.0", features["myname"])
+		assert.Equal(t, "1", features["usermove"])
+		assert.Equal(t, "1", features["done"])
+		assert.Equal(t, "pong 7", out[1])
+	})
+
+	t.Run("The engine replies to the moves of white", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := cecp.NewServer("Gopher", f)
+
+		out := serve(t, s, "new", "level 40 5 0", "time 30000", "otim 29000", "usermove e2e4")
+
+		assert.Equal(t, []string{"move a7a6"}, out)
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", f.last().fen)
+		assert.Equal(t, uci.Limits{
+			WhiteTime: 290 * time.Second,
+			Clock:     true,
+			BlackTime: 300 * time.Second,
+			MovesToGo: 40,
+		}, f.last().limits)
+	})
+
+	t.Run("The engine plays black before new", func(t *testing.T) {
+		s := cecp.NewServer("Gopher", &fakeSearcher{})
+
+		out := serve(t, s, "usermove e2e4")
+
+		assert.Equal(t, []string{"move a7a6"}, out)
+	})
+
+	t.Run("Castling in fischerandom games", func(t *testing.T) {
+		f := &fakeSearcher{bestMove: "e1h1"}
+		s := cecp.NewServer("Gopher", f)
+
+		out := serve(t, s, "new", "variant fischerandom", "force", "setboard 4k3/8/8/8/8/8/8/4K2R w K - 0 1", "go")
+		assert.Equal(t, []string{"move O-O"}, out)
+
+		f.bestMove = "e1g1"
+		out = serve(t, s, "new", "force", "setboard 4k3/8/8/8/8/8/8/4K2R w K - 0 1", "go")
+		assert.Equal(t, []string{"move e1g1"}, out)
+	})
+
+	t.Run("SAN moves in force mode", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := cecp.NewServer("Gopher", f)
+
+		out := serve(t, s, "new", "force", "usermove e4", "usermove e7e5", "usermove Nf3", "usermove Nf3", "sd 4", "go")
+
+		assert.Equal(t, []string{"Illegal move: Nf3", "move a7a6"}, out)
+		assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", f.last().fen)
+		assert.Equal(t, uci.Limits{Depth: 4}, f.last().limits)
+	})
+
+	t.Run("Thinking output", func(t *testing.T) {
+		s := cecp.NewServer("Gopher", &fakeSearcher{})
+
+		out := serve(t, s, "new", "post", "st 2", "go")
+
+		assert.Equal(t, []string{"3 100002 25 1200 a2a3", "move a2a3"}, out)
+	})
+
+	t.Run("Setboard, undo and remove", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := cecp.NewServer("Gopher", f)
+
+		out := serve(t, s,
+			"new", "force",
+			"setboard 4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+			"usermove e2e4", "usermove e8d7", "usermove e4e5",
+			"remove", "undo", "undo",
+			"setboard invalid",
+			"go",
+		)
+
+		assert.Equal(t, []string{"Error (no moves to undo): undo", "tellusererror Illegal position", "move e2e3"}, out)
+		assert.Equal(t, "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", f.last().fen)
+	})
+
+	t.Run("Move now", func(t *testing.T) {
+		s := cecp.NewServer("Gopher", &fakeSearcher{})
+
+		out := serve(t, s, "new", "st 3600", "go", "?")
+
+		assert.Equal(t, []string{"move a2a3"}, out)
+	})
+
+	t.Run("New resets the clocks", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := cecp.NewServer("Gopher", f)
+
+		serve(t, s, "new", "level 0 5 0", "time 100", "otim 200", "new", "go")
+
+		assert.Equal(t, uci.Limits{WhiteTime: 5 * time.Minute, BlackTime: 5 * time.Minute, Clock: true}, f.last().limits)
+	})
+
+	t.Run("Force aborts the search", func(t *testing.T) {
+		s := cecp.NewServer("Gopher", &fakeSearcher{})
+
+		out := serve(t, s, "new", "st 3600", "go", "force", "ping 1")
+
+		assert.Equal(t, []string{"pong 1"}, out)
+	})
+
+	t.Run("The engine reports the end of the game", func(t *testing.T) {
+		s := cecp.NewServer("Gopher", &fakeSearcher{})
+
+		out := serve(t, s, "new", "force", "f2f3", "e7e5", "g2g4", "d8h4", "result 0-1 {Black mates}")
+
+		assert.Equal(t, []string{"0-1 {Black mates}"}, out)
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess/clock"
 )
 
 type (
@@ -28,20 +30,28 @@ type (
 
 	// config represents configurations of how the methods will work.
 	config struct {
-		// Parallelism is the number of workers to use for the moves calculation.
+		// Parallelism is the number of workers to use for perft.
 		Parallelism int
+		// WorkerPool is the pool of workers to use for perft instead of
+		// starting new goroutines, or nil.
+		WorkerPool *WorkerPool
+		// Branching is true if the moves after the current ply are kept as
+		// a variation when a different move is played.
+		Branching bool
+		// Chess960 is true if the game follows the Chess960 castling rules.
+		Chess960 bool
 	}
 
 	// chessContext represents the history of a game.
 	chessContext struct {
 		// move is a played move.
-		move string
+		move Move
 		// fen is a FEN strings that represents the position.
 		fen string
 		// halfMove is the number of half moves since the last capture or pawn move.
 		halfMove int
-		// availableCastles is the castles that are available.
-		availableCastles string
+		// castles are the castles that are available.
+		castles castlingRights
 		// enPassantSquare is the square where a pawn can capture in passant.
 		enPassantSquare string
 		// whiteKingPosition is the position of the white king.
@@ -54,13 +64,28 @@ type (
 		checkmate bool
 		// stalemate is true if the current turn is in stalemate.
 		stalemate bool
+		// clock is the remaining time of the player after the move, if the
+		// move was played with a clock.
+		clock time.Duration
+		// clocked is true if the move was played with a clock.
+		clocked bool
+		// hash is the Zobrist hash of the position.
+		hash uint64
 	}
 
 	// Chess represents a Chess game.
 	//
 	// A Chess value is not safe for concurrent use by multiple goroutines.
+	// Use a SyncGame to share a game between goroutines.
 	Chess struct {
 		board Board
+		// bits is the bitboard representation of the board used for move
+		// generation and attack detection. It is the board itself when the
+		// board is backed by a gochess.BitBoard, or a mirror of it otherwise.
+		bits *gochess.BitBoard
+		// mirrored is true when bits is a mirror of a custom board, so the
+		// changes must be written to both of them.
+		mirrored bool
 		// turn is the current turn.
 		turn gochess.Piece
 		// movesCount is the number of moves played in algebaric notation.
@@ -68,15 +93,27 @@ type (
 		movesCount uint64
 		// halfMoves is the number of half moves since the last capture or pawn move.
 		halfMoves int
-		// enPassantSquare is the square where a pawn can capture in passant.
+		// enPassantSquare is the square where a pawn can capture in passant,
+		// or an empty string if there is none.
 		enPassantSquare string
-		// availableCastles is the castles that are available.
-		// It will has the same format as the FEN castles.
-		availableCastles string
+		// castles are the castles that are available.
+		castles castlingRights
+		// castleKingFiles are the files where the kings must be to castle,
+		// indexed by color index.
+		castleKingFiles [2]int
 		// moves are the available moves in the current position.
-		moves []string
+		moves []Move
+		// perftMoves are the buffers of the moves of every depth of perft,
+		// kept between calls.
+		perftMoves [][]Move
+		// stale is true if moves and the check, checkmate and stalemate
+		// flags don't describe the current position, which was reached with
+		// Push or Pop. They are updated by refresh when they are read.
+		stale bool
 		// actualFEN is the FEN string of the current position.
 		actualFEN string
+		// hash is the Zobrist hash of the current position.
+		hash uint64
 		// blackKingPosition is the position of the black king.
 		blackKingPosition *gochess.Coordinate
 		// whiteKingPosition is the position of the white king.
@@ -87,82 +124,77 @@ type (
 		checkmate bool
 		// stalemate is true if the current turn is in stalemate.
 		stalemate bool
+		// outcome is the outcome of a game ended by resignation, timeout,
+		// agreement or a draw claim.
+		outcome Outcome
+		// drawOffer is the color that offered a draw, or gochess.Empty.
+		drawOffer gochess.Piece
+		// clock is the clock of the game, or nil if the game is not timed.
+		clock *clock.Clock
 
 		// config represents configurations of how the methods will work.
 		config config
 
 		// history is the history of the game.
 		history []chessContext
-	}
-)
-
-var (
-	castlesMoves = map[string]gochess.Piece{
-		"e1g1": gochess.White,
-		"e1c1": gochess.White,
-		"e8g8": gochess.Black,
-		"e8c8": gochess.Black,
-	}
-
-	castleKingWay = map[string]gochess.Coordinate{
-		"e1g1": gochess.Coor(5, 7),
-		"e1c1": gochess.Coor(3, 7),
-		"e8g8": gochess.Coor(5, 0),
-		"e8c8": gochess.Coor(3, 0),
-	}
-
-	castleRook = map[string]gochess.Coordinate{
-		"e1g1": gochess.Coor(7, 7),
-		"e1c1": gochess.Coor(0, 7),
-		"e8g8": gochess.Coor(7, 0),
-		"e8c8": gochess.Coor(0, 0),
+		// redo are the moves after the current ply that can be redone with
+		// Forward, the next one last.
+		redo []Move
+		// lines are the variations kept with the WithBranching option, as
+		// the moves from the starting position.
+		lines [][]Move
 	}
 )
 
 // New creates a new chess game.
 //
-// The chess.AvailableMoves method will use a pool of workers to maximize
-// performance on the moves calculation.
-// By default, the number of workers is twice the number of available CPUs.
-// If you are running on a container environment or you want to use the
-// sequential version, you should set this value manually using the
-// WithParallelism option.
-// The pool of workers will be used only if the board implements the Cloner
+// The Perft and PerftDivide methods use a pool of workers to maximize
+// performance. By default, they start one worker per CPU available to the Go
+// scheduler on every call. The number of workers can be set with the
+// WithParallelism option, and the WithWorkerPool option shares a long-lived
+// WorkerPool between calls and games.
+// The workers will be used only if the board implements the Cloner
 // interface. If you are using a custom Board with the WithBoard option, you
 // should implement the Cloner interface to take advantage of the parallelism.
 func New(opts ...Option) (*Chess, error) {
 	c := &Chess{
-		board:            newBoardAdapter(gochess.DefaultChessBoard()),
-		turn:             gochess.White,
-		movesCount:       1,
-		halfMoves:        0,
-		enPassantSquare:  "",
-		availableCastles: "KQkq",
-		actualFEN:        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
-		moves: []string{
-			"a2a3", "a2a4", "b2b3", "b2b4", "c2c3", "c2c4", "d2d3", "d2d4",
-			"e2e3", "e2e4", "f2f3", "f2f4", "g2g3", "g2g4", "h2h3", "h2h4",
-			"b1a3", "b1c3", "g1f3", "g1h3",
-		},
+		turn:              gochess.White,
+		movesCount:        1,
+		halfMoves:         0,
+		enPassantSquare:   "",
+		castles:           defaultCastlingRights,
+		castleKingFiles:   [2]int{4, 4},
+		actualFEN:         "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
 		blackKingPosition: &gochess.Coordinate{X: 4, Y: 0},
 		whiteKingPosition: &gochess.Coordinate{X: 4, Y: 7},
 		check:             false,
 		checkmate:         false,
 		stalemate:         false,
 		config: config{
-			// To maximize performance chess uses twice the number of available
-			// CPUs. If you are running on a container environment or you want to
-			// use the sequential version, you should set this value manually.
-			Parallelism: runtime.NumCPU() * 2,
+			// Perft is CPU bound, so the workers beyond the CPUs the Go
+			// scheduler runs at once only add copies of the game and
+			// goroutines to switch between. GOMAXPROCS also follows the
+			// limit set by the GOMAXPROCS environment variable.
+			Parallelism: runtime.GOMAXPROCS(0),
 		},
 	}
 
+	c.setBoard(newBitBoardAdapter(gochess.DefaultChessBitBoard()))
+
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
 			return nil, fmt.Errorf("failed to apply option: %w", err)
 		}
 	}
 
+	if c.moves == nil {
+		c.moves = c.legalMoves(c.moves[:0], allMoves)
+	}
+
+	if err := c.startClock(); err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
@@ -178,11 +210,14 @@ func (c *Chess) LoadPosition(FEN string) error {
 	}
 
 	c.actualFEN = FEN
-	c.moves = c.legalMoves()
-	check := c.isCheck()
-	c.check = check && len(c.moves) > 0
-	c.checkmate = check && len(c.moves) == 0
-	c.stalemate = !check && len(c.moves) == 0
+	if c.config.Chess960 {
+		// Normalize the castling field to X-FEN.
+		c.actualFEN = c.calculateFEN()
+	}
+
+	c.hash = c.computeHash()
+	c.redo, c.lines = c.redo[:0], nil
+	c.updateMoves()
 	return nil
 }
 
@@ -197,55 +232,270 @@ func (c *Chess) Turn() gochess.Piece {
 //
 // If any of the kings is not in the board, the function returns an empty string.
 func (c *Chess) FEN() string {
+	if c.actualFEN == "" {
+		// The position was reached with Push.
+		return c.calculateFEN()
+	}
+
 	return c.actualFEN
 }
 
-// AvailableMoves returns the available legal moves for the current turn.
+// ShredderFEN returns the FEN string of the current position using the
+// Shredder-FEN castling notation, where every castle is represented by the
+// file of its rook (e.g. "HAha" instead of "KQkq").
+//
+// If any of the kings is not in the board, the function returns an empty string.
+func (c *Chess) ShredderFEN() string {
+	fen := c.FEN()
+	fields := strings.Split(fen, " ")
+	if len(fields) != 6 {
+		return fen
+	}
+
+	fields[2] = c.castlingFEN(true)
+	return strings.Join(fields, " ")
+}
+
+// StartingFEN returns the FEN string of the position where the game started,
+// before any of the moves returned by History.
+func (c *Chess) StartingFEN() string {
+	return c.startFEN()
+}
+
+// History returns the moves played since the starting position, in order.
+//
+// It always returns a non nil slice.
+func (c *Chess) History() []Move {
+	moves := make([]Move, len(c.history))
+	for i, ctx := range c.history {
+		moves[i] = ctx.move
+	}
+
+	return moves
+}
+
+// IsChess960 returns true if the game follows the Chess960 rules.
+func (c *Chess) IsChess960() bool {
+	return c.config.Chess960
+}
+
+// AvailableMoves returns the available legal moves for the current turn
+// in UCI notation.
 //
 // It always returns a non nil slice. It could be empty if the position is
 // checkmate or stalemate.
 func (c *Chess) AvailableMoves() []string {
+	c.refresh()
+	moves := make([]string, len(c.moves))
+	for i, m := range c.moves {
+		moves[i] = m.UCI()
+	}
+
+	return moves
+}
+
+// Moves returns the available legal moves for the current turn.
+//
+// It always returns a non nil slice. It could be empty if the position is
+// checkmate or stalemate.
+func (c *Chess) Moves() []Move {
+	c.refresh()
 	return slices.Clone(c.moves)
 }
 
+// GenerateMoves appends the legal moves for the current turn to dst and
+// returns the extended slice.
+//
+// Unlike Moves, it doesn't allocate when dst has enough capacity, so a
+// buffer can be reused for every position of a search:
+//
+//	buf := make([]chess.Move, 0, 256)
+//	for _, m := range game.GenerateMoves(buf[:0]) {
+//		// ...
+//	}
+func (c *Chess) GenerateMoves(dst []Move) []Move {
+	return c.legalMoves(dst, allMoves)
+}
+
+// GenerateCaptures appends the legal captures for the current turn,
+// including en passant captures and capturing promotions, to dst and
+// returns the extended slice. It doesn't allocate when dst has enough
+// capacity.
+//
+// Only the captures are generated, so it is cheaper than filtering the
+// result of GenerateMoves.
+func (c *Chess) GenerateCaptures(dst []Move) []Move {
+	return c.legalMoves(dst, captureMoves)
+}
+
+// GenerateQuietMoves appends the legal moves for the current turn that
+// don't capture a piece, including castles and promotions without capture,
+// to dst and returns the extended slice. It doesn't allocate when dst has
+// enough capacity.
+func (c *Chess) GenerateQuietMoves(dst []Move) []Move {
+	return c.legalMoves(dst, quietMoves)
+}
+
+// ParseMove returns the legal Move described by a UCI string (e.g. "e2e4").
+//
+// It returns an error if the string is not valid UCI or the move is not
+// legal in the current position.
+func (c *Chess) ParseMove(uci string) (Move, error) {
+	origin, target, promotion, err := parseUCI(uci)
+	if err != nil {
+		return Move{}, fmt.Errorf("move is not legal: %s", uci)
+	}
+
+	m, ok := c.findMove(origin, target, promotion)
+	if !ok {
+		return Move{}, fmt.Errorf("move is not legal: %s", uci)
+	}
+
+	return m, nil
+}
+
 // MakeMove checks if the move is legal and makes it.
-// It returns an error if the move is not legal.
+// The move must be in UCI notation (e.g. "e2e4").
+// It returns an error if the move is not legal or ErrGameOver if the game
+// has ended.
 func (c *Chess) MakeMove(move string) error {
-	if !slices.Contains(c.moves, move) {
-		return fmt.Errorf("move is not legal: %s", move)
+	if o := c.Outcome(); o.IsOver() {
+		return fmt.Errorf("%w: %s", ErrGameOver, o.Termination)
+	}
+
+	m, err := c.ParseMove(move)
+	if err != nil {
+		return err
 	}
 
-	c.makeMove(move)
-	c.actualFEN = c.calculateFEN(move)
-	c.moves = c.legalMoves()
+	if err := c.play(m); err != nil {
+		return err
+	}
+
+	c.follow(m)
+	return nil
+}
+
+// PlayMove checks if the move is legal and makes it.
+//
+// Only the From, To and Promotion fields of the move are used to look for
+// it in the legal moves, so the rest of the fields may be left empty.
+// It returns an error if the move is not legal or ErrGameOver if the game
+// has ended.
+func (c *Chess) PlayMove(m Move) error {
+	if o := c.Outcome(); o.IsOver() {
+		return fmt.Errorf("%w: %s", ErrGameOver, o.Termination)
+	}
+
+	legal, ok := c.findMove(m.From, m.To, m.Promotion)
+	if !ok {
+		return fmt.Errorf("move is not legal: %s", m.UCI())
+	}
+
+	if err := c.play(legal); err != nil {
+		return err
+	}
+
+	c.follow(legal)
+	return nil
+}
+
+// playMove makes a legal move and updates the state of the game.
+func (c *Chess) playMove(m Move) {
+	// Moving declines the draw offered by the opponent.
+	if c.drawOffer != c.turn {
+		c.drawOffer = gochess.Empty
+	}
+
+	c.makeMove(m)
+	c.actualFEN = c.calculateFEN()
+	c.updateMoves()
+}
+
+// updateMoves generates the legal moves of the current position and
+// updates the check, checkmate and stalemate flags.
+func (c *Chess) updateMoves() {
+	c.moves = c.legalMoves(c.moves[:0], allMoves)
 	check := c.isCheck()
 	c.check = check && len(c.moves) > 0
 	c.checkmate = check && len(c.moves) == 0
 	c.stalemate = !check && len(c.moves) == 0
-	return nil
+	c.stale = false
+}
+
+// refresh updates the legal moves and the check, checkmate and stalemate
+// flags if they are stale after Push or Pop.
+func (c *Chess) refresh() {
+	if c.stale {
+		c.updateMoves()
+	}
+}
+
+// Push plays a legal move with less work than PlayMove: the move is not
+// looked up in the legal moves, the outcome of the game is not checked, the
+// clock is not pressed, and the legal moves and the FEN string are only
+// built if they are read. It doesn't allocate once the game has grown to
+// the depth of the moves.
+//
+// It is meant for searches, which play and undo many moves on a game of
+// their own and generate the moves they need with GenerateMoves,
+// GenerateCaptures or GenerateQuietMoves. Checkers tells whether the side
+// to move is in check without generating its moves. The move must be one
+// of the legal moves of the position, and it must be undone with Pop
+// before the game is changed by any other method.
+func (c *Chess) Push(m Move) {
+	c.makeMove(m)
+	c.actualFEN = ""
+	c.stale = true
+}
+
+// Pop undoes the last move played with Push.
+func (c *Chess) Pop() {
+	c.unmakeMove()
+	c.stale = true
 }
 
 // UnmakeMove unmake the last move.
 //
 // It searches for the last move in the history and unmake it.
 // If there are no moves in the history, the function does nothing.
+// If the move was played with a clock, the press of the clock is undone too.
+//
+// The move is discarded along with the moves after the current ply. Use
+// Back to keep them.
 func (c *Chess) UnmakeMove() {
+	c.unmake()
+	c.redo = c.redo[:0]
+}
+
+// unmake unmakes the last move and the press of its clock.
+func (c *Chess) unmake() {
+	clocked := len(c.history) > 0 && c.history[len(c.history)-1].clocked
+
 	c.unmakeMove()
-	c.moves = c.legalMoves()
+	c.moves = c.legalMoves(c.moves[:0], allMoves)
+	c.stale = false
+
+	if clocked {
+		c.undoClock()
+	}
 }
 
 // IsCheck returns if the current turn is in check.
 func (c *Chess) IsCheck() bool {
+	c.refresh()
 	return c.check
 }
 
 // IsCheckmate returns if the current turn is in checkmate.
 func (c *Chess) IsCheckmate() bool {
+	c.refresh()
 	return c.checkmate
 }
 
 // IsStalemate returns if the current turn is in stalemate.
 func (c *Chess) IsStalemate() bool {
+	c.refresh()
 	return c.stalemate
 }
 
@@ -275,16 +525,14 @@ func (c *Chess) IsFiftyMoveRule() bool {
 // Any other material (pawn, rook, queen, two or more knights, or mixed minor
 // pieces not listed above) is considered sufficient.
 func (c *Chess) IsInsufficientMaterial() bool {
-	width := c.board.Width()
-
 	var knights, bishops int
 	var bishopSquareColor int // stores (x+y)%2 of the first bishop found
 	bishopSquareColor = -1
 	allBishopsSameColor := true
 
-	for y := range width {
-		for x := range width {
-			piece, _ := c.board.Square(gochess.Coor(x, y))
+	for y := range 8 {
+		for x := range 8 {
+			piece := c.pieceAt(gochess.Coor(x, y))
 			if piece == gochess.Empty {
 				continue
 			}
@@ -334,39 +582,28 @@ func (c *Chess) IsInsufficientMaterial() bool {
 // IsThreefoldRepetition returns true if the current position has occurred
 // at least three times during the game.
 //
-// Two positions are considered the same when piece placement, active color,
-// and castling availability are identical. The en passant field and move
-// counters are excluded: an en passant target square is set for exactly one
-// ply after a double pawn push and the same pawn can never double-push again,
-// so the identical non-"-" en passant square cannot appear in two distinct
-// positions within a single game.
+// Two positions are considered the same when their Zobrist hashes are equal:
+// the same piece placement, active color, castling availability and, when
+// an en passant capture is possible, en passant square. The move counters
+// are excluded.
 func (c *Chess) IsThreefoldRepetition() bool {
-	currentKey := positionKey(c.actualFEN)
+	return c.repetitions() >= 3
+}
+
+// repetitions returns the number of times the current position has occurred
+// during the game, including the current one.
+//
+// Only the positions since the last capture or pawn move are compared,
+// since the earlier ones can't be repeated.
+func (c *Chess) repetitions() int {
 	count := 1 // current position counts as one occurrence
-	for _, ctx := range c.history {
-		if positionKey(ctx.fen) == currentKey {
+	for i := len(c.history) - 1; i >= 0 && i >= len(c.history)-c.halfMoves; i-- {
+		if c.history[i].hash == c.hash {
 			count++
-			if count >= 3 {
-				return true
-			}
 		}
 	}
-	return false
-}
 
-// positionKey extracts the first three fields (piece placement, active color,
-// castling rights) from a FEN string for use in repetition detection.
-//
-// The en passant field is intentionally excluded: a given en passant target
-// square is set for exactly one ply and can never recur within the same game,
-// so including it would only produce false negatives without preventing false
-// positives.
-func positionKey(fen string) string {
-	parts := strings.SplitN(fen, " ", 5)
-	if len(parts) < 3 {
-		return fen
-	}
-	return parts[0] + " " + parts[1] + " " + parts[2]
+	return count
 }
 
 // Square returns the piece in a square.
@@ -387,13 +624,23 @@ func (c *Chess) Square(square string) (string, error) {
 	return gochess.PieceNames[p], nil
 }
 
+// Pieces returns the set of squares occupied by the given colored piece
+// (e.g. gochess.White|gochess.Knight), using the gochess.BitIndex layout:
+// bit 0 is a1 and bit 63 is h8.
+func (c *Chess) Pieces(p gochess.Piece) uint64 {
+	return c.bits.Pieces(p)
+}
+
 // clone creates a deep copy of the Chess structure.
 func (c Chess) clone() Chess {
 	cloned := c
+	cloned.perftMoves = nil
 
 	cloner, ok := c.board.(Cloner)
 	if ok {
-		cloned.board = cloner.Clone()
+		cloned.setBoard(cloner.Clone())
+	} else {
+		cloned.bits = c.bits.Clone()
 	}
 
 	if c.whiteKingPosition != nil {
@@ -422,10 +669,62 @@ func (c Chess) clone() Chess {
 		cloned.history = nil
 	}
 
-	if len(c.moves) > 0 {
-		cloned.moves = make([]string, len(c.moves))
-		copy(cloned.moves, c.moves)
-	}
+	// The moves are generated into the same slice after every move, so the
+	// clone needs its own one.
+	cloned.moves = slices.Clone(c.moves)
+	cloned.redo = slices.Clone(c.redo)
+	cloned.lines = slices.Clone(c.lines)
 
 	return cloned
 }
+
+// setBoard sets the board of the game and the bitboards used for the move
+// generation. If the board is not backed by a gochess.BitBoard, the bitboards
+// are built from the board squares and kept in sync with it from then on.
+func (c *Chess) setBoard(b Board) {
+	c.board = b
+	c.mirrored = false
+
+	switch bb := b.(type) {
+	case *bitBoardAdapter:
+		c.bits = bb.BitBoard
+		c.hash = c.computeHash()
+		return
+	case *gochess.BitBoard:
+		c.bits = bb
+		c.hash = c.computeHash()
+		return
+	}
+
+	c.bits, _ = gochess.NewBitBoard()
+	c.mirrored = true
+	for i := range 64 {
+		p, err := b.Square(gochess.BitCoordinate(i))
+		if _, ok := gochess.PieceNames[p]; err != nil || !ok {
+			continue
+		}
+
+		c.bits.Set(i, p)
+	}
+
+	c.hash = c.computeHash()
+}
+
+// setSquare sets a piece in a square of the board.
+//
+// The coordinate must be valid.
+func (c *Chess) setSquare(coor gochess.Coordinate, p gochess.Piece) {
+	i := gochess.BitIndex(coor)
+	c.hash ^= pieceKey(c.bits.PieceAt(i), i) ^ pieceKey(p, i)
+	c.bits.Set(i, p)
+	if c.mirrored {
+		_ = c.board.SetSquare(coor, p)
+	}
+}
+
+// pieceAt returns the piece in a square of the board.
+//
+// The coordinate must be valid.
+func (c Chess) pieceAt(coor gochess.Coordinate) gochess.Piece {
+	return c.bits.PieceAt(gochess.BitIndex(coor))
+}
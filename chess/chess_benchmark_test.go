@@ -1,6 +1,7 @@
 package chess_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/RchrdHndrcks/gochess/v2/chess"
@@ -70,3 +71,106 @@ func BenchmarkVariousMoves(b *testing.B) {
 		}
 	}
 }
+
+// kiwipete is a position with castles, en passant, promotions and pins on
+// both sides, commonly used to test move generation.
+const kiwipete = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+// benchmarkGenerate benchmarks a move generator on the positions after
+// every move of the kiwipete position, reached with Push and Pop as a
+// search does, failing if it allocates with a buffer of enough capacity.
+func benchmarkGenerate(b *testing.B, generate func(*chess.Chess, []chess.Move) []chess.Move) {
+	c, err := chess.New(chess.WithFEN(kiwipete))
+	if err != nil {
+		b.Fatalf("Error creating new chess game: %v", err)
+	}
+
+	moves := c.Moves()
+	buf := make([]chess.Move, 0, 256)
+	generateAll := func() {
+		for _, m := range moves {
+			c.Push(m)
+			buf = generate(c, buf[:0])
+			c.Pop()
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, generateAll); allocs != 0 {
+		b.Fatalf("Expected no allocations, but got %v per run", allocs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		generateAll()
+	}
+}
+
+func BenchmarkGenerateMoves(b *testing.B) {
+	benchmarkGenerate(b, (*chess.Chess).GenerateMoves)
+}
+
+func BenchmarkGenerateCaptures(b *testing.B) {
+	benchmarkGenerate(b, (*chess.Chess).GenerateCaptures)
+}
+
+func BenchmarkGenerateQuietMoves(b *testing.B) {
+	benchmarkGenerate(b, (*chess.Chess).GenerateQuietMoves)
+}
+
+func BenchmarkAppendUCI(b *testing.B) {
+	c, err := chess.New(chess.WithFEN(kiwipete))
+	if err != nil {
+		b.Fatalf("Error creating new chess game: %v", err)
+	}
+
+	moves := c.Moves()
+	buf := make([]byte, 0, 8*len(moves))
+	appendAll := func() {
+		buf = buf[:0]
+		for _, m := range moves {
+			buf = append(m.AppendUCI(buf), ' ')
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, appendAll); allocs != 0 {
+		b.Fatalf("Expected no allocations, but got %v per run", allocs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		appendAll()
+	}
+}
+
+// benchmarkPerft benchmarks the perft of the kiwipete position at depth 3.
+func benchmarkPerft(b *testing.B, opts ...chess.Option) {
+	c, err := chess.New(append(opts, chess.WithFEN(kiwipete))...)
+	if err != nil {
+		b.Fatalf("Error creating new chess game: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if nodes := c.Perft(3); nodes != 97862 {
+			b.Fatalf("Expected 97862 nodes, but got %d", nodes)
+		}
+	}
+}
+
+func BenchmarkPerftSequential(b *testing.B) {
+	benchmarkPerft(b, chess.WithParallelism(1))
+}
+
+func BenchmarkPerftParallel(b *testing.B) {
+	benchmarkPerft(b)
+}
+
+func BenchmarkPerftWorkerPool(b *testing.B) {
+	pool := chess.NewWorkerPool(context.Background(), 0)
+	defer pool.Close()
+
+	benchmarkPerft(b, chess.WithWorkerPool(pool))
+}
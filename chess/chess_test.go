@@ -602,6 +602,35 @@ func TestSquare(t *testing.T) {
 	})
 }
 
+func TestPieces(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		// Arrange
+		c, errOpts := chess.New()
+		require.Nil(t, errOpts)
+
+		// Act
+		knights := c.Pieces(gochess.White | gochess.Knight)
+		kings := c.Pieces(gochess.Black | gochess.King)
+
+		// Assert
+		assert.Equal(t, uint64(1<<1|1<<6), knights)
+		assert.Equal(t, uint64(1<<60), kings)
+	})
+
+	t.Run("After a move", func(t *testing.T) {
+		// Arrange
+		c, errOpts := chess.New()
+		require.Nil(t, errOpts)
+		require.Nil(t, c.MakeMove("g1f3"))
+
+		// Act
+		knights := c.Pieces(gochess.White | gochess.Knight)
+
+		// Assert
+		assert.Equal(t, uint64(1<<1|1<<21), knights)
+	})
+}
+
 // errorBoard is a mock Board implementation that returns an error on Square().
 type errorBoard struct {
 	squareErr error
@@ -812,6 +841,19 @@ func TestLoadPosition_Errors(t *testing.T) {
 		assert.Equal(t, "invalid FEN: rnbqkbnr/p8/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", err.Error())
 	})
 
+	t.Run("Invalid FEN - Short Row", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		// Act
+		err = c.LoadPosition("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKB w KQkq - 0 1")
+
+		// Assert
+		require.NotNil(t, err)
+		assert.Equal(t, "invalid FEN: rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKB w KQkq - 0 1", err.Error())
+	})
+
 	t.Run("Invalid FEN - Invalid Color", func(t *testing.T) {
 		// Arrange
 		c, err := chess.New()
@@ -1270,3 +1312,99 @@ func TestAvailableMoves_Consistency(t *testing.T) {
 		}
 	}
 }
+
+func TestAvailableMoves_PerftRegressions(t *testing.T) {
+	t.Run("Pawn cannot jump over a blocking piece", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New(chess.WithFEN("4k3/8/8/8/8/2N5/2P5/4K3 w - - 0 1"))
+		require.NoError(t, err)
+
+		// Act
+		moves := c.AvailableMoves()
+
+		// Assert
+		assert.NotContains(t, moves, "c2c4")
+		assert.NotContains(t, moves, "c2c3")
+	})
+
+	t.Run("Queenside castle requires an empty b-file square", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/8/RN2K3 w Q - 0 1"))
+		require.NoError(t, err)
+
+		// Act
+		moves := c.AvailableMoves()
+
+		// Assert
+		assert.NotContains(t, moves, "e1c1")
+	})
+
+	t.Run("Castle requires the rook in its square", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/8/4K3 w K - 0 1"))
+		require.NoError(t, err)
+
+		// Act
+		moves := c.AvailableMoves()
+
+		// Assert
+		assert.NotContains(t, moves, "e1g1")
+	})
+}
+
+func TestAvailableMoves_Evasions(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		expected []string
+	}{
+		{
+			name:     "Only the king moves in a double check",
+			fen:      "4k3/8/8/1B6/8/2n5/8/4RK2 b - - 0 1",
+			expected: []string{"e8d8", "e8f8", "e8f7"},
+		},
+		{
+			name:     "King cannot retreat along the checker ray",
+			fen:      "4k3/8/8/8/8/8/8/r3K3 w - - 0 1",
+			expected: []string{"e1d2", "e1e2", "e1f2"},
+		},
+		{
+			name:     "Check is blocked",
+			fen:      "4k3/8/8/8/8/8/3N4/r3K3 w - - 0 1",
+			expected: []string{"d2b1", "e1e2", "e1f2"},
+		},
+		{
+			name:     "En passant captures the checker",
+			fen:      "8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1",
+			expected: []string{"e4d3", "c5b4", "c5c4", "c5d4", "c5b5", "c5d5", "c5b6", "c5c6", "c5d6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c, err := chess.New(chess.WithFEN(tt.fen))
+			require.NoError(t, err)
+
+			// Act
+			moves := c.AvailableMoves()
+
+			// Assert
+			assert.ElementsMatch(t, tt.expected, moves)
+			assert.True(t, c.IsCheck())
+		})
+	}
+
+	t.Run("En passant cannot uncover a check along the rank", func(t *testing.T) {
+		// Arrange
+		c, err := chess.New(chess.WithFEN("8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 1"))
+		require.NoError(t, err)
+
+		// Act
+		moves := c.AvailableMoves()
+
+		// Assert
+		assert.NotContains(t, moves, "e5d6")
+		assert.Contains(t, moves, "e5e6")
+	})
+}
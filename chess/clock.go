@@ -0,0 +1,102 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess/clock"
+)
+
+// WithClock attaches a clock to the game.
+//
+// The clock is started for the side to move when the game is created, if it
+// has not started yet, and pressed on every move made with MakeMove or
+// PlayMove. When a flag falls, the game ends by timeout: the opponent wins,
+// or the game is drawn if the opponent has no pieces to checkmate with.
+// UnmakeMove undoes the press of the move, and the clock is paused when the
+// game ends.
+func WithClock(clk *clock.Clock) Option {
+	return func(c *Chess) error {
+		if clk == nil {
+			return errors.New("clock cannot be nil")
+		}
+
+		c.clock = clk
+		return nil
+	}
+}
+
+// Clock returns the clock of the game, or nil if the game has no clock.
+func (c *Chess) Clock() *clock.Clock {
+	return c.clock
+}
+
+// startClock starts the clock for the side to move. It returns an error if
+// the clock has already started for the other side.
+func (c *Chess) startClock() error {
+	if c.clock == nil {
+		return nil
+	}
+
+	if c.clock.Started() {
+		if c.clock.Turn() != c.turn {
+			return errors.New("failed to attach clock: the clock runs for the side not to move")
+		}
+		return nil
+	}
+
+	return c.clock.Start(c.turn)
+}
+
+// play presses the clock, if any, and makes a legal move. It returns
+// ErrGameOver if the player ran out of time before moving.
+func (c *Chess) play(m Move) error {
+	if c.clock == nil {
+		c.playMove(m)
+		return nil
+	}
+
+	remaining, err := c.clock.Press()
+	if errors.Is(err, clock.ErrFlagFall) {
+		return fmt.Errorf("%w: %s", ErrGameOver, c.Outcome().Termination)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to press clock: %w", err)
+	}
+
+	c.playMove(m)
+
+	ctx := &c.history[len(c.history)-1]
+	ctx.clock, ctx.clocked = remaining, true
+
+	if c.Outcome().IsOver() {
+		c.clock.Pause()
+	}
+
+	return nil
+}
+
+// undoClock undoes the last press of the clock, resuming it if the game
+// is not over anymore.
+func (c *Chess) undoClock() {
+	c.clock.Undo()
+	if !c.Outcome().IsOver() {
+		c.clock.Resume()
+	}
+}
+
+// flagOutcome returns the outcome of a game where a flag has fallen, and
+// false if no flag has fallen.
+func (c *Chess) flagOutcome() (Outcome, bool) {
+	if c.clock == nil {
+		return Outcome{}, false
+	}
+
+	flagged := c.clock.Flagged()
+	if flagged == gochess.Empty {
+		return Outcome{}, false
+	}
+
+	return c.timeoutOutcome(flagged), true
+}
@@ -0,0 +1,384 @@
+// Package clock provides chess clocks for the most common time controls:
+// sudden death, Fischer increment, Bronstein and simple delay, multi-stage
+// controls and hourglass.
+//
+// A Clock can be attached to a chess.Chess with the chess.WithClock option,
+// so it is pressed on every move and a flag fall ends the game.
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+var (
+	// ErrFlagFall is returned when a player runs out of time.
+	ErrFlagFall = errors.New("flag has fallen")
+	// ErrNotStarted is returned when the clock is pressed before it starts.
+	ErrNotStarted = errors.New("clock has not started")
+)
+
+// Option is a function that configures a clock.
+type Option func(*Clock)
+
+// WithTimeSource sets the function used to read the current time. By
+// default, the clock uses time.Now.
+//
+// It allows driving the clock with a ManualTime in tests or replays.
+func WithTimeSource(now func() time.Time) Option {
+	return func(c *Clock) {
+		c.now = now
+	}
+}
+
+// player is the state of the clock of a player.
+type player struct {
+	// remaining is the time left at the start of the current turn.
+	remaining time.Duration
+	// stage is the index of the stage being played.
+	stage int
+	// stageMoves is the number of moves played in the stage.
+	stageMoves int
+}
+
+// state is the state of both clocks, saved on every press so it can be
+// undone.
+type state struct {
+	players [2]player
+	turn    gochess.Piece
+	// spent is the time spent in the turn.
+	spent time.Duration
+}
+
+// Clock is a chess clock.
+//
+// A Clock is safe for concurrent use by multiple goroutines.
+type Clock struct {
+	mu sync.Mutex
+
+	control Control
+	now     func() time.Time
+
+	state
+	// started is true once the clock has been started.
+	started bool
+	// running is true if the clock of the player to move is running.
+	running bool
+	// since is the time when the clock started running in the current turn.
+	since time.Time
+	// flagged is the color that ran out of time, or gochess.Empty.
+	flagged gochess.Piece
+	// history are the states before each press, for Undo.
+	history []state
+}
+
+// New returns a stopped clock with the time of the first stage of the
+// control for both players.
+//
+// It returns an error if the control has no stages, a stage other than the
+// last one has no moves or any value is negative.
+func New(control Control, opts ...Option) (*Clock, error) {
+	if err := control.validate(); err != nil {
+		return nil, fmt.Errorf("invalid time control: %w", err)
+	}
+
+	c := &Clock{control: control, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	base := control.Stages[0].Time
+	c.players = [2]player{{remaining: base}, {remaining: base}}
+	c.turn = gochess.White
+
+	return c, nil
+}
+
+// Control returns the time control of the clock.
+func (c *Clock) Control() Control {
+	return c.control
+}
+
+// Start starts the clock of the given color. It does nothing if the clock
+// has already started.
+func (c *Clock) Start(turn gochess.Piece) error {
+	if turn != gochess.White && turn != gochess.Black {
+		return fmt.Errorf("invalid color: %d", turn)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started {
+		return nil
+	}
+
+	c.started, c.running = true, true
+	c.turn, c.since = turn, c.now()
+	return nil
+}
+
+// Started returns true if the clock has been started.
+func (c *Clock) Started() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.started
+}
+
+// Running returns true if the clock of the player to move is running.
+func (c *Clock) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkFlag()
+	return c.running
+}
+
+// Turn returns the color whose clock runs.
+func (c *Clock) Turn() gochess.Piece {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.turn
+}
+
+// Press ends the turn of the player to move, as a player does after moving.
+// The time spent is charged and given back as the mode of the control
+// defines, and the clock of the opponent starts running.
+//
+// It returns the remaining time of the player that moved, or ErrFlagFall if
+// the player ran out of time before pressing.
+func (c *Clock) Press() (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started {
+		return 0, ErrNotStarted
+	}
+
+	if c.checkFlag() {
+		return 0, fmt.Errorf("%w: %s", ErrFlagFall, colorName(c.flagged))
+	}
+
+	now := c.now()
+	spent := c.elapsed(now)
+	c.history = append(c.history, c.state)
+	c.history[len(c.history)-1].spent = spent
+
+	mover, other := &c.players[colorIndex(c.turn)], &c.players[1-colorIndex(c.turn)]
+	stage := c.control.stage(mover.stage)
+
+	mover.remaining = c.remaining(mover.remaining, spent)
+	switch c.control.Mode {
+	case ModeFischer:
+		mover.remaining += stage.Increment
+	case ModeBronstein:
+		mover.remaining += min(spent, stage.Increment)
+	case ModeHourglass:
+		other.remaining += spent
+	}
+
+	mover.stageMoves++
+	if stage.Moves > 0 && mover.stageMoves == stage.Moves {
+		mover.stage++
+		mover.stageMoves = 0
+		mover.remaining += c.control.stage(mover.stage).Time
+	}
+
+	c.turn = opponent(c.turn)
+	c.spent, c.since = 0, now
+
+	return mover.remaining, nil
+}
+
+// Undo takes back the last press. The clocks go back to the moment of the
+// press: the time given back for the move is removed, the time spent on it
+// is kept and the clock of the player that moved runs again.
+//
+// It does nothing if the clock has not been pressed.
+func (c *Clock) Undo() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.history) == 0 {
+		return
+	}
+
+	if c.flagged != gochess.Empty {
+		c.flagged, c.running = gochess.Empty, true
+	}
+
+	c.state = c.history[len(c.history)-1]
+	c.history = c.history[:len(c.history)-1]
+	c.since = c.now()
+}
+
+// Pause stops the clock of the player to move, keeping the time spent in
+// the turn.
+func (c *Clock) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running || c.checkFlag() {
+		return
+	}
+
+	c.spent = c.elapsed(c.now())
+	c.running = false
+}
+
+// Resume restarts the clock of the player to move after a Pause. It does
+// nothing if the clock has not started or a flag has fallen.
+func (c *Clock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started || c.running || c.flagged != gochess.Empty {
+		return
+	}
+
+	c.running = true
+	c.since = c.now()
+}
+
+// Remaining returns the time left to the given color.
+func (c *Clock) Remaining(color gochess.Piece) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkFlag()
+	return c.remainingOf(color, c.elapsed(c.now()))
+}
+
+// Flagged returns the color that ran out of time, or gochess.Empty if no
+// flag has fallen.
+func (c *Clock) Flagged() gochess.Piece {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkFlag()
+	return c.flagged
+}
+
+// checkFlag stops the clock if the player to move has run out of time, and
+// returns true if a flag has fallen.
+func (c *Clock) checkFlag() bool {
+	if c.flagged != gochess.Empty {
+		return true
+	}
+
+	if !c.running {
+		return false
+	}
+
+	i := colorIndex(c.turn)
+	if c.remaining(c.players[i].remaining, c.elapsed(c.now())) > 0 {
+		return false
+	}
+
+	if c.control.Mode == ModeHourglass {
+		// All the time of the player has gone to the opponent.
+		c.players[1-i].remaining += c.players[i].remaining
+	}
+
+	c.flagged, c.running = c.turn, false
+	c.players[i].remaining, c.spent = 0, 0
+	return true
+}
+
+// remainingOf returns the time left to a color when the player to move has
+// spent the given time.
+func (c *Clock) remainingOf(color gochess.Piece, spent time.Duration) time.Duration {
+	p := c.players[colorIndex(color)]
+	if color == c.turn {
+		return c.remaining(p.remaining, spent)
+	}
+
+	if c.control.Mode == ModeHourglass {
+		return p.remaining + spent
+	}
+
+	return p.remaining
+}
+
+// remaining returns the time left to the player to move when it had the
+// given time at the start of the turn and has spent the given time.
+func (c *Clock) remaining(start, spent time.Duration) time.Duration {
+	if c.control.Mode == ModeDelay {
+		delay := c.control.stage(c.players[colorIndex(c.turn)].stage).Increment
+		spent = max(0, spent-delay)
+	}
+
+	return max(0, start-spent)
+}
+
+// elapsed returns the time spent in the current turn.
+func (c *Clock) elapsed(now time.Time) time.Duration {
+	if !c.running {
+		return c.spent
+	}
+
+	return c.spent + now.Sub(c.since)
+}
+
+// colorIndex returns 0 for white and 1 for black.
+func colorIndex(color gochess.Piece) int {
+	if color == gochess.Black {
+		return 1
+	}
+
+	return 0
+}
+
+// colorName returns the name of a color.
+func colorName(color gochess.Piece) string {
+	if color == gochess.Black {
+		return "black"
+	}
+
+	return "white"
+}
+
+// opponent returns the opposite color.
+func opponent(color gochess.Piece) gochess.Piece {
+	if color == gochess.White {
+		return gochess.Black
+	}
+
+	return gochess.White
+}
+
+// ManualTime is a time source that only advances when told to. It is meant
+// for tests and replays, through WithTimeSource(m.Now).
+//
+// A ManualTime is safe for concurrent use by multiple goroutines.
+type ManualTime struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualTime returns a time source stopped at the given time.
+func NewManualTime(start time.Time) *ManualTime {
+	return &ManualTime{now: start}
+}
+
+// Now returns the current time of the source.
+func (m *ManualTime) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+// Advance moves the time of the source forward.
+func (m *ManualTime) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.now = m.now.Add(d)
+}
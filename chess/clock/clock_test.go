@@ -0,0 +1,193 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClock returns a started clock driven by a manual time source.
+func newClock(t *testing.T, control clock.Control) (*clock.Clock, *clock.ManualTime) {
+	t.Helper()
+
+	source := clock.NewManualTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := clock.New(control, clock.WithTimeSource(source.Now))
+	require.NoError(t, err)
+	require.NoError(t, c.Start(gochess.White))
+
+	return c, source
+}
+
+// move spends the given time and presses the clock.
+func move(t *testing.T, c *clock.Clock, source *clock.ManualTime, spent time.Duration) time.Duration {
+	t.Helper()
+
+	source.Advance(spent)
+	remaining, err := c.Press()
+	require.NoError(t, err)
+
+	return remaining
+}
+
+func TestClock(t *testing.T) {
+	t.Run("Sudden death", func(t *testing.T) {
+		c, source := newClock(t, clock.SuddenDeath(5*time.Minute))
+
+		assert.Equal(t, 4*time.Minute, move(t, c, source, time.Minute))
+		source.Advance(30 * time.Second)
+
+		assert.Equal(t, 4*time.Minute, c.Remaining(gochess.White))
+		assert.Equal(t, 4*time.Minute+30*time.Second, c.Remaining(gochess.Black))
+		assert.Equal(t, gochess.Black, c.Turn())
+	})
+
+	t.Run("Fischer increment", func(t *testing.T) {
+		c, source := newClock(t, clock.Fischer(3*time.Minute, 2*time.Second))
+
+		assert.Equal(t, 2*time.Minute+52*time.Second, move(t, c, source, 10*time.Second))
+		assert.Equal(t, 3*time.Minute+time.Second, move(t, c, source, time.Second))
+	})
+
+	t.Run("Bronstein delay", func(t *testing.T) {
+		c, source := newClock(t, clock.Bronstein(time.Minute, 5*time.Second))
+
+		assert.Equal(t, time.Minute, move(t, c, source, 3*time.Second))
+		assert.Equal(t, 52*time.Second, move(t, c, source, 13*time.Second))
+	})
+
+	t.Run("Simple delay", func(t *testing.T) {
+		c, source := newClock(t, clock.SimpleDelay(time.Minute, 5*time.Second))
+
+		source.Advance(3 * time.Second)
+		assert.Equal(t, time.Minute, c.Remaining(gochess.White))
+
+		assert.Equal(t, 52*time.Second, move(t, c, source, 10*time.Second))
+	})
+
+	t.Run("Hourglass", func(t *testing.T) {
+		c, source := newClock(t, clock.Hourglass(time.Minute))
+
+		source.Advance(20 * time.Second)
+		assert.Equal(t, 40*time.Second, c.Remaining(gochess.White))
+		assert.Equal(t, 80*time.Second, c.Remaining(gochess.Black))
+
+		_, err := c.Press()
+		require.NoError(t, err)
+		assert.Equal(t, 80*time.Second, c.Remaining(gochess.Black))
+	})
+
+	t.Run("Multi-stage control", func(t *testing.T) {
+		c, source := newClock(t, clock.MultiStage(clock.ModeFischer,
+			clock.Stage{Moves: 2, Time: 90 * time.Minute, Increment: 30 * time.Second},
+			clock.Stage{Time: 30 * time.Minute, Increment: 30 * time.Second},
+		))
+
+		assert.Equal(t, 89*time.Minute+30*time.Second, move(t, c, source, time.Minute))
+		move(t, c, source, time.Minute)
+
+		// The second move of white completes the first stage.
+		assert.Equal(t, 118*time.Minute+30*time.Second, move(t, c, source, 90*time.Second))
+	})
+
+	t.Run("Repeated last stage", func(t *testing.T) {
+		c, source := newClock(t, clock.MultiStage(clock.ModeFischer,
+			clock.Stage{Moves: 1, Time: time.Minute},
+		))
+
+		assert.Equal(t, 2*time.Minute-10*time.Second, move(t, c, source, 10*time.Second))
+	})
+
+	t.Run("Flag fall", func(t *testing.T) {
+		c, source := newClock(t, clock.SuddenDeath(time.Minute))
+
+		move(t, c, source, 10*time.Second)
+		source.Advance(time.Minute)
+
+		assert.Equal(t, gochess.Black, c.Flagged())
+		assert.Zero(t, c.Remaining(gochess.Black))
+		assert.False(t, c.Running())
+
+		_, err := c.Press()
+		assert.ErrorIs(t, err, clock.ErrFlagFall)
+	})
+
+	t.Run("Pause and resume", func(t *testing.T) {
+		c, source := newClock(t, clock.SuddenDeath(time.Minute))
+
+		source.Advance(10 * time.Second)
+		c.Pause()
+		source.Advance(time.Hour)
+		assert.Equal(t, 50*time.Second, c.Remaining(gochess.White))
+		assert.Equal(t, gochess.Empty, c.Flagged())
+
+		c.Resume()
+		assert.Equal(t, 45*time.Second, move(t, c, source, 5*time.Second))
+	})
+
+	t.Run("Undo", func(t *testing.T) {
+		c, source := newClock(t, clock.Fischer(time.Minute, 5*time.Second))
+
+		move(t, c, source, 10*time.Second)
+		source.Advance(20 * time.Second)
+		c.Undo()
+
+		assert.Equal(t, gochess.White, c.Turn())
+		assert.Equal(t, 50*time.Second, c.Remaining(gochess.White))
+		assert.Equal(t, time.Minute, c.Remaining(gochess.Black))
+
+		source.Advance(5 * time.Second)
+		assert.Equal(t, 45*time.Second, c.Remaining(gochess.White))
+	})
+
+	t.Run("Press before start", func(t *testing.T) {
+		c, err := clock.New(clock.SuddenDeath(time.Minute))
+		require.NoError(t, err)
+
+		_, err = c.Press()
+		assert.ErrorIs(t, err, clock.ErrNotStarted)
+	})
+}
+
+func TestControl(t *testing.T) {
+	t.Run("PGN TimeControl tag", func(t *testing.T) {
+		tests := []struct {
+			control clock.Control
+			tag     string
+		}{
+			{clock.SuddenDeath(5 * time.Minute), "300"},
+			{clock.Fischer(3*time.Minute, 2*time.Second), "180+2"},
+			{clock.Hourglass(time.Minute), "*60"},
+			{clock.MultiStage(clock.ModeFischer,
+				clock.Stage{Moves: 40, Time: 90 * time.Minute, Increment: 30 * time.Second},
+				clock.Stage{Time: 30 * time.Minute, Increment: 30 * time.Second},
+			), "40/5400+30:1800+30"},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.tag, tt.control.String())
+
+			tc := tt.control.TimeControl()
+			control, err := clock.FromTimeControl(tc)
+			require.NoError(t, err)
+			assert.Equal(t, tt.control, control)
+		}
+	})
+
+	t.Run("Invalid controls", func(t *testing.T) {
+		controls := []clock.Control{
+			{Mode: clock.ModeFischer},
+			{Mode: clock.Mode(42), Stages: []clock.Stage{{Time: time.Minute}}},
+			{Mode: clock.ModeFischer, Stages: []clock.Stage{{Time: -time.Minute}}},
+			clock.MultiStage(clock.ModeFischer, clock.Stage{Time: time.Minute}, clock.Stage{Time: time.Minute}),
+		}
+
+		for _, control := range controls {
+			_, err := clock.New(control)
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+)
+
+// Mode is the way a time control gives time back to the players for their
+// moves.
+type Mode int
+
+const (
+	// ModeFischer adds the increment to the clock after every move. Without
+	// increment, it is a sudden death control.
+	ModeFischer Mode = iota
+	// ModeBronstein gives back the time spent on every move, up to the
+	// delay.
+	ModeBronstein
+	// ModeDelay waits for the delay before the clock starts running on every
+	// move. It is also known as simple or US delay.
+	ModeDelay
+	// ModeHourglass adds the time spent on every move to the clock of the
+	// opponent.
+	ModeHourglass
+)
+
+// modeNames are the names of the modes.
+var modeNames = map[Mode]string{
+	ModeFischer:   "fischer",
+	ModeBronstein: "bronstein",
+	ModeDelay:     "delay",
+	ModeHourglass: "hourglass",
+}
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Stage is a stage of a time control.
+type Stage struct {
+	// Moves is the number of moves to play in the stage, or 0 if the stage
+	// lasts until the end of the game. If the last stage has a number of
+	// moves, it is repeated.
+	Moves int
+	// Time is the time added to the clock at the start of the stage.
+	Time time.Duration
+	// Increment is the time given back for every move of the stage, as
+	// defined by the Mode of the control: the increment of the Fischer mode
+	// or the delay of the Bronstein and delay modes.
+	Increment time.Duration
+}
+
+// Control is a time control.
+type Control struct {
+	// Mode is the way the increment of the stages is applied.
+	Mode Mode
+	// Stages are the stages of the control, in the order they are played.
+	Stages []Stage
+}
+
+// SuddenDeath returns a time control with a fixed time for the whole game.
+func SuddenDeath(base time.Duration) Control {
+	return Fischer(base, 0)
+}
+
+// Fischer returns a time control with a base time and an increment added
+// after every move.
+func Fischer(base, increment time.Duration) Control {
+	return Control{Mode: ModeFischer, Stages: []Stage{{Time: base, Increment: increment}}}
+}
+
+// Bronstein returns a time control with a base time where the time spent on
+// every move is given back, up to the delay.
+func Bronstein(base, delay time.Duration) Control {
+	return Control{Mode: ModeBronstein, Stages: []Stage{{Time: base, Increment: delay}}}
+}
+
+// SimpleDelay returns a time control with a base time where the clock waits
+// for the delay before it starts running on every move.
+func SimpleDelay(base, delay time.Duration) Control {
+	return Control{Mode: ModeDelay, Stages: []Stage{{Time: base, Increment: delay}}}
+}
+
+// Hourglass returns a time control where the time spent on every move is
+// added to the clock of the opponent.
+func Hourglass(base time.Duration) Control {
+	return Control{Mode: ModeHourglass, Stages: []Stage{{Time: base}}}
+}
+
+// MultiStage returns a time control with several stages, such as 40 moves
+// in 90 minutes followed by 30 minutes for the rest of the game, both with
+// an increment of 30 seconds:
+//
+//	clock.MultiStage(clock.ModeFischer,
+//		clock.Stage{Moves: 40, Time: 90 * time.Minute, Increment: 30 * time.Second},
+//		clock.Stage{Time: 30 * time.Minute, Increment: 30 * time.Second},
+//	)
+func MultiStage(mode Mode, stages ...Stage) Control {
+	return Control{Mode: mode, Stages: stages}
+}
+
+// FromTimeControl returns the control described by a PGN TimeControl tag.
+//
+// Sandclock periods are read as the hourglass mode and increments as the
+// Fischer mode. It returns an error for untimed games and for controls
+// mixing sandclock periods with other periods.
+func FromTimeControl(tc chesspgn.TimeControl) (Control, error) {
+	if tc.Untimed || len(tc.Periods) == 0 {
+		return Control{}, errors.New("time control without periods")
+	}
+
+	c := Control{Mode: ModeFischer}
+	if tc.Periods[0].Sandclock {
+		c.Mode = ModeHourglass
+	}
+
+	for _, p := range tc.Periods {
+		if p.Sandclock != (c.Mode == ModeHourglass) {
+			return Control{}, fmt.Errorf("time control %s mixes sandclock and other periods", tc)
+		}
+
+		c.Stages = append(c.Stages, Stage{Moves: p.Moves, Time: p.Base, Increment: p.Increment})
+	}
+
+	return c, nil
+}
+
+// TimeControl returns the control as a PGN TimeControl tag.
+//
+// The tag has no notation for delays, so the delays of the Bronstein and
+// delay modes are written as increments.
+func (c Control) TimeControl() chesspgn.TimeControl {
+	var tc chesspgn.TimeControl
+	for _, s := range c.Stages {
+		p := chesspgn.TimeControlPeriod{Moves: s.Moves, Base: s.Time, Increment: s.Increment}
+		if c.Mode == ModeHourglass {
+			p = chesspgn.TimeControlPeriod{Base: s.Time, Sandclock: true}
+		}
+
+		tc.Periods = append(tc.Periods, p)
+	}
+
+	return tc
+}
+
+// String returns the control in the PGN TimeControl tag format.
+func (c Control) String() string {
+	return c.TimeControl().String()
+}
+
+// validate returns an error if the control cannot be played.
+func (c Control) validate() error {
+	if _, ok := modeNames[c.Mode]; !ok {
+		return fmt.Errorf("invalid mode: %s", c.Mode)
+	}
+
+	if len(c.Stages) == 0 {
+		return errors.New("time control without stages")
+	}
+
+	for i, s := range c.Stages {
+		if s.Moves < 0 || s.Time < 0 || s.Increment < 0 {
+			return fmt.Errorf("invalid stage %d: negative values", i+1)
+		}
+
+		if s.Moves == 0 && i != len(c.Stages)-1 {
+			return fmt.Errorf("invalid stage %d: only the last stage can last until the end of the game", i+1)
+		}
+	}
+
+	return nil
+}
+
+// stage returns the stage with the given index, repeating the last one.
+func (c Control) stage(i int) Stage {
+	return c.Stages[min(i, len(c.Stages)-1)]
+}
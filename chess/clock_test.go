@@ -0,0 +1,127 @@
+package chess_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/clock"
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTimedGame returns a game with a clock driven by a manual time source.
+func newTimedGame(t *testing.T, control clock.Control, opts ...chess.Option) (*chess.Chess, *clock.ManualTime) {
+	t.Helper()
+
+	source := clock.NewManualTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	clk, err := clock.New(control, clock.WithTimeSource(source.Now))
+	require.NoError(t, err)
+
+	c, err := chess.New(append(opts, chess.WithClock(clk))...)
+	require.NoError(t, err)
+
+	return c, source
+}
+
+func TestWithClock(t *testing.T) {
+	t.Run("Moves press the clock", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.Fischer(time.Minute, 2*time.Second))
+
+		source.Advance(10 * time.Second)
+		require.NoError(t, c.MakeMove("e2e4"))
+
+		assert.Equal(t, gochess.Black, c.Clock().Turn())
+		assert.Equal(t, 52*time.Second, c.Clock().Remaining(gochess.White))
+	})
+
+	t.Run("Flag fall ends the game", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.SuddenDeath(time.Minute))
+
+		require.NoError(t, c.MakeMove("e2e4"))
+		source.Advance(time.Minute)
+
+		assert.Equal(t, chess.Outcome{
+			Winner:      gochess.White,
+			Result:      chesspgn.ResultWhiteWins,
+			Termination: chess.TerminationTimeout,
+		}, c.Outcome())
+		assert.ErrorIs(t, c.MakeMove("e7e5"), chess.ErrGameOver)
+	})
+
+	t.Run("Flag fall against a lone king is a draw", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.SuddenDeath(time.Minute), chess.WithFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"))
+
+		source.Advance(2 * time.Minute)
+
+		assert.Equal(t, chess.Outcome{
+			Winner:      gochess.Empty,
+			Result:      chesspgn.ResultDraw,
+			Termination: chess.TerminationTimeout,
+		}, c.Outcome())
+	})
+
+	t.Run("UnmakeMove undoes the press", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.Fischer(time.Minute, 5*time.Second))
+
+		source.Advance(10 * time.Second)
+		require.NoError(t, c.MakeMove("e2e4"))
+		c.UnmakeMove()
+
+		assert.Equal(t, gochess.White, c.Clock().Turn())
+		assert.Equal(t, 50*time.Second, c.Clock().Remaining(gochess.White))
+	})
+
+	t.Run("The clock stops when the game ends", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.SuddenDeath(time.Minute))
+
+		for _, m := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+			require.NoError(t, c.MakeMove(m))
+		}
+		source.Advance(time.Hour)
+
+		assert.Equal(t, chess.TerminationCheckmate, c.Outcome().Termination)
+		assert.False(t, c.Clock().Running())
+
+		c.UnmakeMove()
+		assert.True(t, c.Clock().Running())
+	})
+
+	t.Run("Resignation stops the clock", func(t *testing.T) {
+		c, _ := newTimedGame(t, clock.SuddenDeath(time.Minute))
+
+		require.NoError(t, c.Resign(gochess.White))
+		assert.False(t, c.Clock().Running())
+	})
+
+	t.Run("Clock running for the side not to move", func(t *testing.T) {
+		clk, err := clock.New(clock.SuddenDeath(time.Minute))
+		require.NoError(t, err)
+		require.NoError(t, clk.Start(gochess.Black))
+
+		_, err = chess.New(chess.WithClock(clk))
+		assert.Error(t, err)
+	})
+
+	t.Run("PGN has the time control and the clock times", func(t *testing.T) {
+		c, source := newTimedGame(t, clock.Fischer(3*time.Minute, 2*time.Second))
+
+		source.Advance(3 * time.Second)
+		require.NoError(t, c.MakeMove("e2e4"))
+		source.Advance(5 * time.Second)
+		require.NoError(t, c.MakeMove("e7e5"))
+
+		pgn := c.PGN(chesspgn.PGNTags{{Name: "TimeControl", Value: "60"}})
+
+		game, err := chesspgn.ParseGame(pgn)
+		require.NoError(t, err)
+		assert.Equal(t, "180+2", game.Tags.Get("TimeControl"))
+		assert.Contains(t, pgn, "1. e4 {[%clk 0:02:59]} 1... e5 {[%clk 0:02:57]} *")
+
+		white, ok := chesspgn.ParseClockComment(game.Root.Next().CommentsAfter[0])
+		require.True(t, ok)
+		assert.Equal(t, 179*time.Second, white)
+	})
+}
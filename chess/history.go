@@ -0,0 +1,285 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+// ErrPlyOutOfRange is returned when navigating to a ply outside the line of
+// the game.
+var ErrPlyOutOfRange = errors.New("ply out of range")
+
+// WithBranching keeps the moves after the current ply as a variation when a
+// different move is played there, instead of discarding them.
+//
+// The variations that continue from the current ply are returned by
+// Variations, and playing the first move of one of them switches to it.
+func WithBranching() Option {
+	return func(c *Chess) error {
+		c.config.Branching = true
+		return nil
+	}
+}
+
+// Ply returns the number of moves played to reach the current position,
+// which is the position of the cursor in the line of the game.
+func (c *Chess) Ply() int {
+	return len(c.history)
+}
+
+// PlyCount returns the number of moves of the line of the game, including
+// the moves after the current ply that can be redone with Forward.
+func (c *Chess) PlyCount() int {
+	return len(c.history) + len(c.redo)
+}
+
+// Line returns the moves of the line of the game, in order, including the
+// moves after the current ply.
+//
+// It always returns a non nil slice.
+func (c *Chess) Line() []Move {
+	moves := c.History()
+	for _, m := range slices.Backward(c.redo) {
+		moves = append(moves, m)
+	}
+
+	return moves
+}
+
+// UCIMoves returns the moves of the line of the game in UCI notation,
+// including the moves after the current ply.
+func (c *Chess) UCIMoves() []string {
+	line := c.Line()
+	moves := make([]string, len(line))
+	for i, m := range line {
+		moves[i] = m.UCI()
+	}
+
+	return moves
+}
+
+// SANMoves returns the moves of the line of the game in SAN, with check
+// and checkmate suffixes, including the moves after the current ply.
+//
+// The starting position has always been loaded before, but if it can't be
+// replayed, the moves are returned in UCI notation.
+func (c *Chess) SANMoves() []string {
+	replay, err := c.startingGame()
+	if err != nil {
+		return c.UCIMoves()
+	}
+
+	line := c.Line()
+	moves := make([]string, len(line))
+	for i, m := range line {
+		moves[i] = replay.san(m)
+		replay.playMove(m)
+	}
+
+	return moves
+}
+
+// FENs returns the FEN string of the position at every ply of the line of
+// the game, starting with the starting position, so the FEN string at ply n
+// is at index n.
+func (c *Chess) FENs() []string {
+	fens := make([]string, 0, c.PlyCount()+1)
+	for _, ctx := range c.history {
+		fens = append(fens, ctx.fen)
+	}
+	fens = append(fens, c.FEN())
+
+	if len(c.redo) == 0 {
+		return fens
+	}
+
+	replay := c.clone()
+	for _, m := range slices.Backward(c.redo) {
+		replay.makeMove(m)
+		replay.actualFEN = replay.calculateFEN()
+		fens = append(fens, replay.actualFEN)
+	}
+
+	return fens
+}
+
+// CapturedPieces returns the piece captured by every move of the line of
+// the game, or gochess.Empty for the moves that don't capture, including
+// the moves after the current ply.
+func (c *Chess) CapturedPieces() []gochess.Piece {
+	line := c.Line()
+	pieces := make([]gochess.Piece, len(line))
+	for i, m := range line {
+		pieces[i] = m.Captured
+	}
+
+	return pieces
+}
+
+// Back undoes the last move, keeping it to be redone with Forward. It
+// returns ErrPlyOutOfRange if no move has been played.
+//
+// As UnmakeMove, it undoes the press of the clock of the move.
+func (c *Chess) Back() error {
+	if len(c.history) == 0 {
+		return fmt.Errorf("%w: no move to undo", ErrPlyOutOfRange)
+	}
+
+	m := c.history[len(c.history)-1].move
+	c.unmake()
+	c.redo = append(c.redo, m)
+	return nil
+}
+
+// Forward redoes the next move of the line of the game. It returns
+// ErrPlyOutOfRange if there are no moves after the current ply.
+//
+// The move is redone even if the game has ended, e.g. by resignation, but
+// as MakeMove, it presses the clock and returns ErrGameOver if the player
+// ran out of time.
+func (c *Chess) Forward() error {
+	if len(c.redo) == 0 {
+		return fmt.Errorf("%w: no move to redo", ErrPlyOutOfRange)
+	}
+
+	m := c.redo[len(c.redo)-1]
+	if err := c.play(m); err != nil {
+		return err
+	}
+
+	c.redo = c.redo[:len(c.redo)-1]
+	return nil
+}
+
+// GoToPly moves the cursor to the position after the given number of moves
+// of the line of the game, undoing or redoing the moves in between. It
+// returns ErrPlyOutOfRange if the ply is negative or greater than PlyCount.
+func (c *Chess) GoToPly(ply int) error {
+	if ply < 0 || ply > c.PlyCount() {
+		return fmt.Errorf("%w: %d", ErrPlyOutOfRange, ply)
+	}
+
+	for len(c.history) > ply {
+		if err := c.Back(); err != nil {
+			return err
+		}
+	}
+
+	for len(c.history) < ply {
+		if err := c.Forward(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start moves the cursor to the starting position.
+func (c *Chess) Start() {
+	for len(c.history) > 0 {
+		_ = c.Back()
+	}
+}
+
+// End moves the cursor to the last position of the line of the game.
+func (c *Chess) End() error {
+	return c.GoToPly(c.PlyCount())
+}
+
+// Variations returns the continuations of the variations kept with the
+// WithBranching option that leave the line of the game at the current ply,
+// from the move played instead of the next one.
+func (c *Chess) Variations() [][]Move {
+	ply := len(c.history)
+	var variations [][]Move
+	for _, line := range c.lines {
+		if len(line) > ply && c.startsWith(line) && (len(c.redo) == 0 || line[ply] != c.redo[len(c.redo)-1]) {
+			variations = append(variations, slices.Clone(line[ply:]))
+		}
+	}
+
+	return variations
+}
+
+// startsWith returns true if a line starts with the moves played.
+func (c *Chess) startsWith(line []Move) bool {
+	if len(line) < len(c.history) {
+		return false
+	}
+
+	for i, ctx := range c.history {
+		if line[i] != ctx.move {
+			return false
+		}
+	}
+
+	return true
+}
+
+// follow updates the moves after the current ply once a move is played.
+//
+// The move is taken from them if it is the next one. Otherwise they are
+// discarded or, with the WithBranching option, kept as a variation, and the
+// variation that continues with the move, if any, becomes the line of the
+// game.
+func (c *Chess) follow(m Move) {
+	if len(c.redo) == 0 && len(c.lines) == 0 {
+		return
+	}
+
+	if len(c.redo) > 0 && c.redo[len(c.redo)-1] == m {
+		c.redo = c.redo[:len(c.redo)-1]
+		return
+	}
+
+	if !c.config.Branching {
+		c.redo = c.redo[:0]
+		return
+	}
+
+	if len(c.redo) > 0 {
+		line := make([]Move, 0, len(c.history)-1+len(c.redo))
+		for _, ctx := range c.history[:len(c.history)-1] {
+			line = append(line, ctx.move)
+		}
+
+		for _, next := range slices.Backward(c.redo) {
+			line = append(line, next)
+		}
+
+		c.lines = append(c.lines, line)
+		c.redo = c.redo[:0]
+	}
+
+	// A variation that ends with the move has nothing to redo, but it is
+	// removed too since it is part of the line of the game.
+	i := slices.IndexFunc(c.lines, c.startsWith)
+	if i < 0 {
+		return
+	}
+
+	for _, next := range slices.Backward(c.lines[i][len(c.history):]) {
+		c.redo = append(c.redo, next)
+	}
+
+	c.lines = slices.Delete(c.lines, i, i+1)
+}
+
+// startingGame returns a new game in the starting position of the game, to
+// replay its moves.
+func (c *Chess) startingGame() (*Chess, error) {
+	opts := []Option{WithParallelism(1)}
+	if c.config.Chess960 {
+		opts = append(opts, WithChess960())
+	}
+
+	replay, err := New(append(opts, WithFEN(c.startFEN()))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the starting position: %w", err)
+	}
+
+	return replay, nil
+}
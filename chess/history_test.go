@@ -0,0 +1,191 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGameWithMoves creates a game and makes the moves in UCI notation.
+func newGameWithMoves(t *testing.T, moves []string, opts ...chess.Option) *chess.Chess {
+	t.Helper()
+
+	c, err := chess.New(opts...)
+	require.NoError(t, err)
+
+	for _, m := range moves {
+		require.NoError(t, c.MakeMove(m))
+	}
+
+	return c
+}
+
+func TestMoveList(t *testing.T) {
+	c := newGameWithMoves(t, []string{"e2e4", "d7d5", "e4d5", "d8d5", "b1c3", "d5a5"})
+	require.NoError(t, c.GoToPly(2))
+
+	assert.Equal(t, 2, c.Ply())
+	assert.Equal(t, 6, c.PlyCount())
+	assert.Len(t, c.History(), 2)
+	assert.Len(t, c.Line(), 6)
+	assert.Equal(t, []string{"e2e4", "d7d5", "e4d5", "d8d5", "b1c3", "d5a5"}, c.UCIMoves())
+	assert.Equal(t, []string{"e4", "d5", "exd5", "Qxd5", "Nc3", "Qa5"}, c.SANMoves())
+	assert.Equal(t, []gochess.Piece{
+		gochess.Empty, gochess.Empty, gochess.Black | gochess.Pawn,
+		gochess.White | gochess.Pawn, gochess.Empty, gochess.Empty,
+	}, c.CapturedPieces())
+
+	assert.Equal(t, []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+		"rnbqkbnr/ppp1pppp/8/3P4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2",
+		"rnb1kbnr/ppp1pppp/8/3q4/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3",
+		"rnb1kbnr/ppp1pppp/8/3q4/8/2N5/PPPP1PPP/R1BQKBNR b KQkq - 1 3",
+		"rnb1kbnr/ppp1pppp/8/q7/8/2N5/PPPP1PPP/R1BQKBNR w KQkq - 2 4",
+	}, c.FENs())
+	assert.Equal(t, c.FENs()[2], c.FEN())
+
+	t.Run("Empty game", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		assert.NotNil(t, c.Line())
+		assert.Empty(t, c.UCIMoves())
+		assert.Empty(t, c.SANMoves())
+		assert.Empty(t, c.CapturedPieces())
+		assert.Equal(t, []string{c.FEN()}, c.FENs())
+	})
+}
+
+func TestNavigation(t *testing.T) {
+	moves := []string{"e2e4", "e7e5", "g1f3", "b8c6"}
+	c := newGameWithMoves(t, moves)
+	fens := c.FENs()
+
+	t.Run("Back and Forward", func(t *testing.T) {
+		require.NoError(t, c.Back())
+		assert.Equal(t, fens[3], c.FEN())
+		assert.Equal(t, 3, c.Ply())
+		assert.Equal(t, 4, c.PlyCount())
+
+		require.NoError(t, c.Forward())
+		assert.Equal(t, fens[4], c.FEN())
+		assert.ErrorIs(t, c.Forward(), chess.ErrPlyOutOfRange)
+	})
+
+	t.Run("Start and End", func(t *testing.T) {
+		c.Start()
+		assert.Equal(t, fens[0], c.FEN())
+		assert.Equal(t, 0, c.Ply())
+		assert.Len(t, c.Moves(), 20)
+		assert.ErrorIs(t, c.Back(), chess.ErrPlyOutOfRange)
+
+		require.NoError(t, c.End())
+		assert.Equal(t, fens[4], c.FEN())
+		assert.Equal(t, moves, c.UCIMoves())
+	})
+
+	t.Run("GoToPly", func(t *testing.T) {
+		for _, ply := range []int{2, 0, 3, 4, 1} {
+			require.NoError(t, c.GoToPly(ply))
+			assert.Equal(t, fens[ply], c.FEN())
+			assert.Equal(t, ply, c.Ply())
+		}
+
+		assert.ErrorIs(t, c.GoToPly(-1), chess.ErrPlyOutOfRange)
+		assert.ErrorIs(t, c.GoToPly(5), chess.ErrPlyOutOfRange)
+		assert.Equal(t, 1, c.Ply())
+	})
+
+	t.Run("Replaying the next move keeps the line", func(t *testing.T) {
+		require.NoError(t, c.GoToPly(1))
+		require.NoError(t, c.MakeMove("e7e5"))
+		assert.Equal(t, 4, c.PlyCount())
+		assert.Equal(t, moves, c.UCIMoves())
+	})
+
+	t.Run("UnmakeMove discards the line", func(t *testing.T) {
+		c := newGameWithMoves(t, moves)
+		require.NoError(t, c.GoToPly(2))
+
+		c.UnmakeMove()
+		assert.Equal(t, 1, c.PlyCount())
+		assert.ErrorIs(t, c.Forward(), chess.ErrPlyOutOfRange)
+	})
+
+	t.Run("LoadPosition discards the line", func(t *testing.T) {
+		c := newGameWithMoves(t, moves)
+		require.NoError(t, c.GoToPly(2))
+
+		require.NoError(t, c.LoadPosition("4k3/8/8/8/8/8/8/4K3 w - - 0 1"))
+		assert.Equal(t, c.Ply(), c.PlyCount())
+	})
+}
+
+func TestNavigation_Truncate(t *testing.T) {
+	c := newGameWithMoves(t, []string{"e2e4", "e7e5", "g1f3", "b8c6"})
+	require.NoError(t, c.GoToPly(2))
+
+	require.NoError(t, c.MakeMove("f1c4"))
+	assert.Equal(t, []string{"e2e4", "e7e5", "f1c4"}, c.UCIMoves())
+	assert.Equal(t, 3, c.PlyCount())
+	assert.Empty(t, c.Variations())
+}
+
+func TestNavigation_Branch(t *testing.T) {
+	main := []string{"e2e4", "e7e5", "g1f3", "b8c6"}
+	c := newGameWithMoves(t, main, chess.WithBranching())
+	require.NoError(t, c.GoToPly(2))
+
+	// Playing a different move keeps the line as a variation.
+	require.NoError(t, c.MakeMove("f1c4"))
+	require.NoError(t, c.MakeMove("g8f6"))
+	assert.Equal(t, []string{"e2e4", "e7e5", "f1c4", "g8f6"}, c.UCIMoves())
+	assert.Empty(t, c.Variations())
+
+	require.NoError(t, c.GoToPly(2))
+	variations := c.Variations()
+	require.Len(t, variations, 1)
+	assert.Equal(t, "g1f3", variations[0][0].UCI())
+	assert.Len(t, variations[0], 2)
+
+	// Playing the first move of a variation switches to it.
+	require.NoError(t, c.MakeMove("g1f3"))
+	assert.Equal(t, main, c.UCIMoves())
+	assert.Equal(t, 3, c.Ply())
+
+	require.NoError(t, c.Back())
+	variations = c.Variations()
+	require.Len(t, variations, 1)
+	assert.Equal(t, []string{"f1c4", "g8f6"}, []string{variations[0][0].UCI(), variations[0][1].UCI()})
+
+	// A third move at the same ply adds another variation.
+	require.NoError(t, c.MakeMove("d2d4"))
+	require.NoError(t, c.Back())
+	assert.Len(t, c.Variations(), 2)
+
+	require.NoError(t, c.End())
+	assert.Equal(t, []string{"e2e4", "e7e5", "d2d4"}, c.UCIMoves())
+}
+
+func TestSyncGame_Navigation(t *testing.T) {
+	g := newSyncGame(t)
+	require.NoError(t, g.MakeMove("e2e4"))
+	require.NoError(t, g.MakeMove("e7e5"))
+
+	ch, cancel := g.Subscribe(4)
+	defer cancel()
+
+	require.NoError(t, g.Update((*chess.Chess).Back))
+	require.NoError(t, g.Update((*chess.Chess).Forward))
+
+	e := <-ch
+	assert.Equal(t, chess.EventUndo, e.Type)
+	e = <-ch
+	assert.Equal(t, chess.EventMove, e.Type)
+	assert.Equal(t, "e7e5", e.Move.UCI())
+}
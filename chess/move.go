@@ -0,0 +1,158 @@
+package chess
+
+import (
+	"fmt"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+// MoveFlag is a bitfield that describes the special properties of a Move.
+type MoveFlag uint8
+
+const (
+	// FlagCastle marks a castling move. From is the king origin square. In
+	// standard games To is the king target square (e.g. e1g1), and in
+	// Chess960 games it is the square of the rook the king castles with
+	// (e.g. e1h1).
+	FlagCastle MoveFlag = 1 << iota
+	// FlagEnPassant marks an en passant capture. The captured pawn is not
+	// on the target square but behind it.
+	FlagEnPassant
+	// FlagDoublePush marks a pawn advancing two squares from its initial rank.
+	FlagDoublePush
+)
+
+// Move represents a chess move in a given position.
+//
+// Besides the origin and target squares, a Move carries the moving piece,
+// the captured piece and the promotion piece so consumers do not need to
+// inspect the position to know what the move does. Pieces are colored
+// (e.g. gochess.White|gochess.Pawn). Captured and Promotion are gochess.Empty
+// when the move is not a capture or a promotion respectively.
+//
+// Move values are comparable and can be used as map keys.
+type Move struct {
+	// From is the origin square of the move.
+	From gochess.Coordinate
+	// To is the target square of the move.
+	To gochess.Coordinate
+	// Piece is the moving piece.
+	Piece gochess.Piece
+	// Captured is the captured piece, if any.
+	Captured gochess.Piece
+	// Promotion is the piece the pawn is promoted to, if any.
+	Promotion gochess.Piece
+	// Flags describes the special properties of the move.
+	Flags MoveFlag
+}
+
+// String returns the UCI notation of the move (e.g. "e2e4" or "e7e8q").
+func (m Move) String() string {
+	return m.UCI()
+}
+
+// UCI returns the UCI notation of the move (e.g. "e2e4" or "e7e8q").
+func (m Move) UCI() string {
+	return string(m.AppendUCI(make([]byte, 0, 5)))
+}
+
+// AppendUCI appends the UCI notation of the move to dst and returns the
+// extended buffer. It doesn't allocate when dst has enough capacity.
+func (m Move) AppendUCI(dst []byte) []byte {
+	dst = appendSquare(dst, m.From)
+	dst = appendSquare(dst, m.To)
+	if m.Promotion != gochess.Empty {
+		// The promotion piece is always written in lowercase.
+		dst = append(dst, pieceLetters[gochess.PieceType(m.Promotion)|gochess.Black])
+	}
+
+	return dst
+}
+
+// appendSquare appends the algebraic notation of a coordinate to dst. Nothing
+// is appended if the coordinate is out of bounds.
+func appendSquare(dst []byte, c gochess.Coordinate) []byte {
+	if !onBoard(c.X, c.Y) {
+		return dst
+	}
+
+	return append(dst, byte('a'+c.X), byte('8'-c.Y))
+}
+
+// Has returns true if the move has all the given flags set.
+func (m Move) Has(f MoveFlag) bool {
+	return m.Flags&f == f
+}
+
+// IsCapture returns true if the move captures a piece, including en passant.
+func (m Move) IsCapture() bool {
+	return m.Captured != gochess.Empty
+}
+
+// IsCastle returns true if the move is a castling move.
+func (m Move) IsCastle() bool {
+	return m.Has(FlagCastle)
+}
+
+// IsEnPassant returns true if the move is an en passant capture.
+func (m Move) IsEnPassant() bool {
+	return m.Has(FlagEnPassant)
+}
+
+// IsDoublePush returns true if the move is a two-square pawn advance.
+func (m Move) IsDoublePush() bool {
+	return m.Has(FlagDoublePush)
+}
+
+// IsPromotion returns true if the move is a pawn promotion.
+func (m Move) IsPromotion() bool {
+	return m.Promotion != gochess.Empty
+}
+
+// parseUCI parses a UCI string into its origin, target and uncolored
+// promotion piece. The promotion piece is gochess.Empty if the move is not a
+// promotion.
+func parseUCI(uci string) (gochess.Coordinate, gochess.Coordinate, gochess.Piece, error) {
+	if len(uci) < 4 || len(uci) > 5 {
+		return gochess.Coordinate{}, gochess.Coordinate{}, gochess.Empty,
+			fmt.Errorf("invalid UCI move: %s", uci)
+	}
+
+	origin, err := AlgebraicToCoordinate(uci[:2])
+	if err != nil {
+		return gochess.Coordinate{}, gochess.Coordinate{}, gochess.Empty,
+			fmt.Errorf("invalid UCI move: %s", uci)
+	}
+
+	target, err := AlgebraicToCoordinate(uci[2:4])
+	if err != nil {
+		return gochess.Coordinate{}, gochess.Coordinate{}, gochess.Empty,
+			fmt.Errorf("invalid UCI move: %s", uci)
+	}
+
+	promotion := gochess.Empty
+	if len(uci) == 5 {
+		p, ok := gochess.PiecesWithoutColor[uci[4:5]]
+		if !ok || p == gochess.Pawn || p == gochess.King {
+			return gochess.Coordinate{}, gochess.Coordinate{}, gochess.Empty,
+				fmt.Errorf("invalid UCI move: %s", uci)
+		}
+		promotion = p
+	}
+
+	return origin, target, promotion, nil
+}
+
+// findMove looks for a legal move with the given origin, target and
+// promotion piece type. The promotion may be colored or not.
+func (c *Chess) findMove(origin, target gochess.Coordinate, promotion gochess.Piece) (Move, bool) {
+	c.refresh()
+	promotion = gochess.PieceType(promotion)
+	for _, m := range c.moves {
+		if m.From == origin && m.To == target && gochess.PieceType(m.Promotion) == promotion {
+			return m, true
+		}
+	}
+
+	return Move{}, false
+}
@@ -0,0 +1,302 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMove(t *testing.T) {
+	t.Run("UCI", func(t *testing.T) {
+		m := chess.Move{From: gochess.Coor(4, 6), To: gochess.Coor(4, 4)}
+		assert.Equal(t, "e2e4", m.UCI())
+		assert.Equal(t, "e2e4", m.String())
+
+		m = chess.Move{
+			From:      gochess.Coor(0, 1),
+			To:        gochess.Coor(0, 0),
+			Promotion: gochess.White | gochess.Knight,
+		}
+		assert.Equal(t, "a7a8n", m.UCI())
+
+		m = chess.Move{
+			From:      gochess.Coor(4, 6),
+			To:        gochess.Coor(4, 7),
+			Promotion: gochess.Black | gochess.Queen,
+		}
+		assert.Equal(t, "e2e1q", m.UCI())
+	})
+
+	t.Run("AppendUCI", func(t *testing.T) {
+		m := chess.Move{From: gochess.Coor(6, 7), To: gochess.Coor(5, 5)}
+		assert.Equal(t, "e2e4 g1f3", string(m.AppendUCI([]byte("e2e4 "))))
+
+		buf := make([]byte, 0, 5)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = m.AppendUCI(buf[:0])
+		})
+		assert.Zero(t, allocs)
+	})
+
+	t.Run("Flags", func(t *testing.T) {
+		m := chess.Move{Flags: chess.FlagCastle | chess.FlagDoublePush}
+		assert.True(t, m.IsCastle())
+		assert.True(t, m.IsDoublePush())
+		assert.False(t, m.IsEnPassant())
+		assert.True(t, m.Has(chess.FlagCastle|chess.FlagDoublePush))
+		assert.False(t, m.Has(chess.FlagCastle|chess.FlagEnPassant))
+	})
+}
+
+func TestMoves(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		moves := c.Moves()
+		require.Len(t, moves, 20)
+
+		uci := make([]string, len(moves))
+		for i, m := range moves {
+			uci[i] = m.UCI()
+		}
+		assert.ElementsMatch(t, c.AvailableMoves(), uci)
+	})
+
+	t.Run("Double push", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("e2e4")
+		require.NoError(t, err)
+		assert.Equal(t, gochess.White|gochess.Pawn, m.Piece)
+		assert.True(t, m.IsDoublePush())
+		assert.False(t, m.IsCapture())
+
+		m, err = c.ParseMove("e2e3")
+		require.NoError(t, err)
+		assert.False(t, m.IsDoublePush())
+	})
+
+	t.Run("Capture", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2"))
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("e4d5")
+		require.NoError(t, err)
+		assert.True(t, m.IsCapture())
+		assert.Equal(t, gochess.Black|gochess.Pawn, m.Captured)
+	})
+
+	t.Run("En passant", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3"))
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("e5f6")
+		require.NoError(t, err)
+		assert.True(t, m.IsEnPassant())
+		assert.True(t, m.IsCapture())
+		assert.Equal(t, gochess.Black|gochess.Pawn, m.Captured)
+	})
+
+	t.Run("Castle", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("7k/8/8/8/8/8/P6P/R3K2R w KQ - 0 1"))
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("e1g1")
+		require.NoError(t, err)
+		assert.True(t, m.IsCastle())
+		assert.Equal(t, gochess.White|gochess.King, m.Piece)
+
+		m, err = c.ParseMove("e1f1")
+		require.NoError(t, err)
+		assert.False(t, m.IsCastle())
+	})
+
+	t.Run("Promotion with capture", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("1r5k/P7/8/8/8/8/8/7K w - - 0 1"))
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("a7b8q")
+		require.NoError(t, err)
+		assert.True(t, m.IsPromotion())
+		assert.True(t, m.IsCapture())
+		assert.Equal(t, gochess.White|gochess.Queen, m.Promotion)
+		assert.Equal(t, gochess.Black|gochess.Rook, m.Captured)
+	})
+}
+
+func TestGenerateMoves(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"))
+	require.NoError(t, err)
+
+	t.Run("Moves", func(t *testing.T) {
+		assert.Equal(t, c.Moves(), c.GenerateMoves(nil))
+
+		// The moves are appended after the existing ones.
+		prefix := []chess.Move{{From: gochess.Coor(0, 0), To: gochess.Coor(0, 1)}}
+		moves := c.GenerateMoves(prefix)
+		assert.Equal(t, prefix[0], moves[0])
+		assert.Equal(t, c.Moves(), moves[1:])
+	})
+
+	t.Run("Captures and quiet moves", func(t *testing.T) {
+		captures := c.GenerateCaptures(nil)
+		quiet := c.GenerateQuietMoves(nil)
+		assert.Len(t, captures, 8)
+		assert.Len(t, quiet, 40)
+
+		for _, m := range captures {
+			assert.True(t, m.IsCapture(), m.String())
+		}
+
+		for _, m := range quiet {
+			assert.False(t, m.IsCapture(), m.String())
+		}
+
+		assert.ElementsMatch(t, c.Moves(), append(captures, quiet...))
+	})
+
+	t.Run("Captures and quiet moves after Push", func(t *testing.T) {
+		// Positions with en passant captures, promotions with and without
+		// capture, castles and checks.
+		for _, fen := range []string{
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		} {
+			c, err := chess.New(chess.WithFEN(fen))
+			require.NoError(t, err)
+
+			for _, m := range c.Moves() {
+				c.Push(m)
+				captures := c.GenerateCaptures(nil)
+				quiet := c.GenerateQuietMoves(nil)
+				for _, m := range captures {
+					assert.True(t, m.IsCapture(), m.String())
+				}
+				for _, m := range quiet {
+					assert.False(t, m.IsCapture(), m.String())
+				}
+				assert.ElementsMatch(t, c.Moves(), append(captures, quiet...), fen+" "+m.String())
+				c.Pop()
+			}
+		}
+	})
+
+	t.Run("No allocations", func(t *testing.T) {
+		buf := make([]chess.Move, 0, 256)
+		generators := map[string]func([]chess.Move) []chess.Move{
+			"GenerateMoves":      c.GenerateMoves,
+			"GenerateCaptures":   c.GenerateCaptures,
+			"GenerateQuietMoves": c.GenerateQuietMoves,
+		}
+
+		for name, generate := range generators {
+			allocs := testing.AllocsPerRun(100, func() {
+				buf = generate(buf[:0])
+			})
+			assert.Zero(t, allocs, name)
+		}
+	})
+}
+
+func TestParseMove_Errors(t *testing.T) {
+	c, err := chess.New()
+	require.NoError(t, err)
+
+	for _, uci := range []string{"", "e2", "e2e5", "e2e4q", "z1z2", "e2e4e4"} {
+		_, err := c.ParseMove(uci)
+		assert.Error(t, err, "ParseMove(%q)", uci)
+	}
+}
+
+func TestPlayMove(t *testing.T) {
+	t.Run("Only From, To and Promotion are required", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		err = c.PlayMove(chess.Move{From: gochess.Coor(6, 7), To: gochess.Coor(5, 5)})
+		require.NoError(t, err)
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - 1 1", c.FEN())
+	})
+
+	t.Run("Illegal move", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		err = c.PlayMove(chess.Move{From: gochess.Coor(4, 6), To: gochess.Coor(4, 3)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "move is not legal")
+	})
+
+	t.Run("Unmake promotion with capture restores the board", func(t *testing.T) {
+		fen := "1r5k/P7/8/8/8/8/8/7K w - - 0 1"
+		c, err := chess.New(chess.WithFEN(fen))
+		require.NoError(t, err)
+
+		m, err := c.ParseMove("a7b8n")
+		require.NoError(t, err)
+		require.NoError(t, c.PlayMove(m))
+		assert.Equal(t, "1N5k/8/8/8/8/8/8/7K b - - 0 1", c.FEN())
+
+		c.UnmakeMove()
+		assert.Equal(t, fen, c.FEN())
+		square, err := c.Square("b8")
+		require.NoError(t, err)
+		assert.Equal(t, "r", square)
+	})
+}
+
+func TestMoveSAN(t *testing.T) {
+	c, err := chess.New()
+	require.NoError(t, err)
+
+	san, err := c.MoveSAN(chess.Move{From: gochess.Coor(6, 7), To: gochess.Coor(5, 5)})
+	require.NoError(t, err)
+	assert.Equal(t, "Nf3", san)
+
+	m, err := c.ParseSAN("Nf3")
+	require.NoError(t, err)
+	assert.Equal(t, gochess.Coor(6, 7), m.From)
+	assert.Equal(t, gochess.White|gochess.Knight, m.Piece)
+
+	_, err = c.MoveSAN(chess.Move{From: gochess.Coor(6, 7), To: gochess.Coor(6, 5)})
+	assert.Error(t, err)
+}
+
+func TestPush(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"))
+	require.NoError(t, err)
+	start := c.Position()
+
+	for _, m := range c.Moves() {
+		played := start.Play(m)
+
+		c.Push(m)
+		assert.Equal(t, played.FEN(), c.FEN(), m.String())
+		assert.Equal(t, played.Hash(), c.Hash(), m.String())
+		assert.Equal(t, played.IsCheck(), c.IsCheck(), m.String())
+		assert.ElementsMatch(t, played.Moves(), c.Moves(), m.String())
+
+		c.Pop()
+		assert.Equal(t, start, c.Position(), m.String())
+	}
+
+	t.Run("No allocations", func(t *testing.T) {
+		m, err := c.ParseMove("e5f7")
+		require.NoError(t, err)
+		c.Push(m)
+		c.Pop()
+
+		allocs := testing.AllocsPerRun(100, func() {
+			c.Push(m)
+			c.Pop()
+		})
+		assert.Zero(t, allocs)
+	})
+}
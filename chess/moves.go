@@ -1,84 +1,80 @@
 package chess
 
 import (
-	"strings"
-	"sync"
+	"math/bits"
 
 	"github.com/RchrdHndrcks/gochess/v2"
 )
 
-// capacityByPiece is a helper map where the key is the piece and the value is
-// the max moves count for that piece. It is useful to know which capacity asign
-// to the slice where the moves of these pieces are stored.
-var capacityByPiece = map[gochess.Piece]int{
-	gochess.White | gochess.Queen:  27,
-	gochess.Black | gochess.Queen:  27,
-	gochess.White | gochess.Rook:   14,
-	gochess.Black | gochess.Rook:   14,
-	gochess.White | gochess.Bishop: 13,
-	gochess.Black | gochess.Bishop: 13,
-}
+// squareCoordinates holds the coordinate of every square by bit index. The
+// king positions of the moves point to them, so moving a king doesn't
+// allocate. They must not be modified through those pointers.
+var squareCoordinates = func() (coordinates [64]gochess.Coordinate) {
+	for i := range coordinates {
+		coordinates[i] = gochess.BitCoordinate(i)
+	}
+	return coordinates
+}()
 
 // makeMove makes a move without checking if it is legal.
-func (c *Chess) makeMove(move string) {
+func (c *Chess) makeMove(m Move) {
 	lastFEN := c.actualFEN
+	lastHash := c.hash
+	o, t := m.From, m.To
 
-	// Ignore the error because the move should be already validated.
-	o, _ := AlgebraicToCoordinate(move[:2])
-	t, _ := AlgebraicToCoordinate(move[2:4])
-
-	if c.isCastleMove(move) {
-		// If the move is a castle move, we need to move the rook too.
-		//
-		// Ignore the error because the coordinates is valid because
-		// the move is already validated.
-		c.makeMoveOnBoard(castleRook[move], gochess.Coor((o.X+t.X)/2, o.Y))
-	}
+	// The keys of the castling rights, the en passant file and the turn are
+	// removed before the move and added back once they are updated. The
+	// keys of the pieces are updated by setSquare.
+	c.hash ^= c.stateKey()
 
-	if c.isEnPassantMove(move) {
+	if m.IsEnPassant() {
 		// If the move is an en passant capture, we need to remove the captured pawn.
 		// The captured pawn is behind the target square.
-		//
-		// Ignore the error because the coordinates is valid because
-		// the move is already validated.
-		_ = c.board.SetSquare(gochess.Coor(t.X, o.Y), gochess.Empty)
+		c.setSquare(gochess.Coor(t.X, o.Y), gochess.Empty)
 	}
 
-	// UCI moves only permit 5 characters if the move is a pawn coronation.
-	if len(move) == 5 {
-		// Ignore the error because the coordinates is valid because
-		// the move is already validated.
-		_ = c.board.SetSquare(t, gochess.PiecesWithoutColor[move[4:5]]|c.turn)
-		_ = c.board.SetSquare(o, gochess.Empty)
-	} else {
-		// Ignore the error because the coordinates is valid because
-		// the move is already validated.
+	switch {
+	case m.IsCastle():
+		// If the move is a castle move, we need to move the rook too.
+		// Both origins are cleared first because in Chess960 the king
+		// and the rook could land on each other's squares.
+		kingTarget, rookOrigin, rookTarget := c.castleSquares(m)
+		c.setSquare(o, gochess.Empty)
+		c.setSquare(rookOrigin, gochess.Empty)
+		c.setSquare(kingTarget, m.Piece)
+		c.setSquare(rookTarget, c.turn|gochess.Rook)
+		t = kingTarget
+	case m.IsPromotion():
+		c.setSquare(t, m.Promotion)
+		c.setSquare(o, gochess.Empty)
+	default:
 		c.makeMoveOnBoard(o, t)
 	}
 
 	c.history = append(
 		c.history,
 		chessContext{
-			move:              move,
+			move:              m,
 			fen:               lastFEN,
 			halfMove:          c.halfMoves,
-			availableCastles:  c.availableCastles,
+			castles:           c.castles,
 			enPassantSquare:   c.enPassantSquare,
 			whiteKingPosition: c.whiteKingPosition,
 			blackKingPosition: c.blackKingPosition,
 			check:             c.check,
 			checkmate:         c.checkmate,
 			stalemate:         c.stalemate,
+			hash:              lastHash,
 		},
 	)
 
-	// If the origin is the king, update the king position.
-	if o == *c.whiteKingPosition {
-		c.whiteKingPosition = &t
+	// If the moving piece is the king, update the king position.
+	if m.Piece == gochess.White|gochess.King {
+		c.whiteKingPosition = &squareCoordinates[gochess.BitIndex(t)]
 	}
 
-	if o == *c.blackKingPosition {
-		c.blackKingPosition = &t
+	if m.Piece == gochess.Black|gochess.King {
+		c.blackKingPosition = &squareCoordinates[gochess.BitIndex(t)]
 	}
 
 	c.toggleColor()
@@ -86,15 +82,16 @@ func (c *Chess) makeMove(move string) {
 	c.updateCastlePossibilities()
 	c.updateHalfMoves()
 	c.updateEnPassantSquare()
+	c.hash ^= c.stateKey()
 }
 
 // makeMoveOnBoard is a helper function to make a move on the board.
 //
 // It must be used only when the move is already validated.
 func (c *Chess) makeMoveOnBoard(origin, target gochess.Coordinate) {
-	p, _ := c.board.Square(origin)
-	_ = c.board.SetSquare(origin, gochess.Empty)
-	_ = c.board.SetSquare(target, p)
+	p := c.pieceAt(origin)
+	c.setSquare(origin, gochess.Empty)
+	c.setSquare(target, p)
 }
 
 // unmakeMove is a helper function to unmake the last move.
@@ -112,7 +109,7 @@ func (c *Chess) unmakeMove() {
 	c.history = c.history[:len(c.history)-1]
 
 	c.halfMoves = lastContext.halfMove
-	c.availableCastles = lastContext.availableCastles
+	c.castles = lastContext.castles
 	c.enPassantSquare = lastContext.enPassantSquare
 	c.whiteKingPosition = lastContext.whiteKingPosition
 	c.blackKingPosition = lastContext.blackKingPosition
@@ -127,446 +124,325 @@ func (c *Chess) unmakeMove() {
 		c.movesCount--
 	}
 
-	move := lastContext.move
-	o, _ := AlgebraicToCoordinate(move[2:4])
-	t, _ := AlgebraicToCoordinate(move[:2])
+	m := lastContext.move
 
-	// If it was a promotion, restore the captured piece.
-	if len(move) == 5 {
-		_ = c.board.SetSquare(t, gochess.Pawn|c.turn)
-	} else {
-		// Move the piece back to its original position
-		c.makeMoveOnBoard(o, t)
+	if m.IsCastle() {
+		kingTarget, rookOrigin, rookTarget := c.castleSquares(m)
+		c.setSquare(kingTarget, gochess.Empty)
+		c.setSquare(rookTarget, gochess.Empty)
+		c.setSquare(rookOrigin, c.turn|gochess.Rook)
+		c.setSquare(m.From, m.Piece)
+		c.hash = lastContext.hash
+		return
 	}
 
-	// Check if there was a capture by examining the previous FEN.
-	capturedPiece := pieceFromFEN(lastContext.fen, o)
-	if capturedPiece != gochess.Empty {
-		_ = c.board.SetSquare(o, capturedPiece)
-	}
+	// Put the moving piece back in its origin. This also undoes promotions
+	// because the Move keeps the original pawn.
+	c.setSquare(m.To, gochess.Empty)
+	c.setSquare(m.From, m.Piece)
 
-	if c.isCastleMove(move) {
-		c.makeMoveOnBoard(gochess.Coor((o.X+t.X)/2, o.Y), castleRook[move])
+	if m.IsEnPassant() {
+		// Restore the captured pawn behind the target square.
+		c.setSquare(gochess.Coor(m.To.X, m.From.Y), m.Captured)
+	} else if m.IsCapture() {
+		c.setSquare(m.To, m.Captured)
 	}
 
-	if c.isEnPassantMove(move) {
-		// Restore the captured pawn.
-		_ = c.board.SetSquare(gochess.Coor(o.X, t.Y), gochess.Pawn|c.turn)
-	}
+	c.hash = lastContext.hash
 }
 
-// movesForPiece returns the available moves for a piece.
-//
-// The function returns a slice of UCI moves.
-// (e.g. "e2e4" for moving the piece at e2 to e4.)
-// Disclaimer: This function does not check if the move is legal for a Chess game.
-func (c Chess) movesForPiece(piece gochess.Piece, origin gochess.Coordinate) []string {
-	switch gochess.PieceType(piece) {
-	case gochess.Pawn:
-		return c.pawnMoves(origin)
-	case gochess.Rook:
-		return c.rookMoves(origin)
-	case gochess.Queen:
-		return c.queenMoves(origin)
-	case gochess.King:
-		return append(c.kingMoves(origin), c.kingCastleMoves(origin)...)
-	case gochess.Bishop:
-		return c.bishopMoves(origin)
-	case gochess.Knight:
-		return c.knightMoves(origin)
-	}
-
-	return nil
-}
+// maxMoves is the maximum number of legal moves of a chess position.
+const maxMoves = 218
 
-// pawnMoves returns all the valid pawn moves.
-func (c Chess) pawnMoves(origin gochess.Coordinate) []string {
-	p, _ := c.board.Square(origin)
-	dir := -1
-	if p&gochess.White == gochess.Empty {
-		dir = 1
-	}
+// promotionPieces are the pieces a pawn can be promoted to.
+var promotionPieces = [4]gochess.Piece{gochess.Queen, gochess.Rook, gochess.Bishop, gochess.Knight}
 
-	isPromotion := false
-	tCor := gochess.Coor(origin.X, origin.Y+1*dir)
-	if tCor.Y == 7 || tCor.Y == 0 {
-		isPromotion = true
-	}
+// moveKind selects the moves generated by legalMoves.
+type moveKind uint8
 
-	moves := make([]string, 0, 2)
-	s, _ := c.board.Square(tCor)
-	if s == gochess.Empty {
-		moves = append(moves, UCI(origin, tCor))
-	}
+const (
+	// allMoves are all the moves of the position.
+	allMoves moveKind = iota
+	// captureMoves are the moves that capture a piece, including en passant
+	// captures and capturing promotions.
+	captureMoves
+	// quietMoves are the moves that don't capture a piece, including castles
+	// and promotions without capture.
+	quietMoves
+)
 
-	if isPromotion {
-		captureMoves := c.pawnCaptureMoves(origin, true)
-		// Only add push-promotion moves if the target square is empty.
-		// Previously this called promotionPossibilities unconditionally, which
-		// generated pseudo-legal captures to the promotion square even when it
-		// was occupied (e.g. by the opponent's king), incorrectly marking that
-		// square as attacked.
-		if s == gochess.Empty {
-			captureMoves = append(captureMoves, c.promotionPossibilities(origin, tCor)...)
+// availableMoves appends the available moves of the given kind for the
+// current turn to moves without checking if they are legal.
+func (c *Chess) availableMoves(moves []Move, kind moveKind) []Move {
+	b := c.bits
+	us := c.turn
+	occupied := b.Occupied()
+
+	// The squares the pieces can move to.
+	allowed := ^b.Color(us)
+	switch kind {
+	case captureMoves:
+		allowed = b.Color(opponent(us))
+	case quietMoves:
+		allowed = ^occupied
+	}
+
+	moves = c.pawnMoves(moves, kind)
+
+	for _, pieceType := range []gochess.Piece{gochess.Knight, gochess.Bishop, gochess.Rook, gochess.Queen, gochess.King} {
+		piece := us | pieceType
+		for set := b.Pieces(piece); set != 0; {
+			from := popSquare(&set)
+
+			for targets := pieceAttacks(pieceType, from, occupied) & allowed; targets != 0; {
+				to := popSquare(&targets)
+				moves = append(moves, Move{
+					From:     gochess.BitCoordinate(from),
+					To:       gochess.BitCoordinate(to),
+					Piece:    piece,
+					Captured: b.PieceAt(to),
+				})
+			}
 		}
-		return captureMoves
-	}
-
-	if !(dir == 1 && origin.Y == 1) && !(dir == -1 && origin.Y == 6) {
-		return append(c.pawnCaptureMoves(origin, false), moves...)
 	}
 
-	tCor = gochess.Coor(origin.X, origin.Y+2*dir)
-	s, _ = c.board.Square(tCor)
-	if s == gochess.Empty {
-		moves = append(moves, UCI(origin, tCor))
+	if kind == captureMoves {
+		return moves
 	}
 
-	return append(c.pawnCaptureMoves(origin, false), moves...)
+	return c.castleMoves(moves)
 }
 
-// pawnCaptureMoves returns all the valid pawn capture moves.
-func (c Chess) pawnCaptureMoves(origin gochess.Coordinate, isPromotion bool) []string {
-	p, _ := c.board.Square(origin)
-	pColor := gochess.PieceColor(p)
-	dir := -1
-	if pColor == gochess.Black {
-		dir = 1
-	}
-
-	moves := make([]string, 0, 2)
-	offsets := []int{-1, 1}
-	for _, o := range offsets {
-		tCor := gochess.Coor(origin.X+o, origin.Y+1*dir)
-		if tCor.X < 0 || tCor.X > 7 || tCor.Y < 0 || tCor.Y > 7 {
-			continue
-		}
+// pawnMoves appends the pseudo-legal pawn moves of the given kind for the
+// current turn to moves.
+func (c *Chess) pawnMoves(moves []Move, kind moveKind) []Move {
+	b := c.bits
+	us := c.turn
+	pawn := us | gochess.Pawn
+	enemies := b.Color(opponent(us))
+	empty := ^b.Occupied()
 
-		if CoordinateToAlgebraic(tCor) == c.enPassantSquare {
-			moves = append(moves, UCI(origin, tCor))
-			continue
-		}
+	forward, startRank, promotionRank := 8, 1, 7
+	if us == gochess.Black {
+		forward, startRank, promotionRank = -8, 6, 0
+	}
 
-		ts, _ := c.board.Square(tCor)
-		if ts == gochess.Empty || ts&pColor != gochess.Empty {
-			continue
-		}
+	if kind == quietMoves {
+		enemies = 0
+	}
 
-		if !isPromotion {
-			moves = append(moves, UCI(origin, tCor))
-			continue
+	enPassant := -1
+	if kind != quietMoves && c.enPassantSquare != "" {
+		if coor, err := AlgebraicToCoordinate(c.enPassantSquare); err == nil {
+			enPassant = gochess.BitIndex(coor)
 		}
-
-		moves = append(moves, c.promotionPossibilities(origin, tCor)...)
 	}
 
-	return moves
-}
+	for set := b.Pieces(pawn); set != 0; {
+		from := popSquare(&set)
+		origin := gochess.BitCoordinate(from)
 
-// promotionPossibilities is a helper function that returns the UCI moves with
-// the value of the piece to be promoted.
-func (c Chess) promotionPossibilities(origin, target gochess.Coordinate) []string {
-	moves := make([]string, 4)
-	for i, p := range []gochess.Piece{gochess.Queen, gochess.Rook, gochess.Bishop, gochess.Knight} {
-		moves[i] = UCI(origin, target, p)
-	}
+		targets := pawnAttacks[colorIndex(us)][from] & enemies
+		singlePush := kind != captureMoves && empty&squareBit(from+forward) != 0
+		if singlePush {
+			targets |= squareBit(from + forward)
+		}
 
-	return moves
-}
+		for targets != 0 {
+			to := popSquare(&targets)
+			m := Move{From: origin, To: gochess.BitCoordinate(to), Piece: pawn, Captured: b.PieceAt(to)}
+			if to/8 != promotionRank {
+				moves = append(moves, m)
+				continue
+			}
 
-// knightMoves returns valid knight moves.
-func (c Chess) knightMoves(origin gochess.Coordinate) []string {
-	offsets := []gochess.Coordinate{
-		{X: 1, Y: 2}, {X: 2, Y: 1},
-		{X: 1, Y: -2}, {X: 2, Y: -1},
-		{X: -1, Y: 2}, {X: -2, Y: 1},
-		{X: -1, Y: -2}, {X: -2, Y: -1},
-	}
+			for _, p := range promotionPieces {
+				m.Promotion = us | p
+				moves = append(moves, m)
+			}
+		}
 
-	return c.oneStepPieces(origin, offsets)
-}
+		// The pawn can only advance two squares if the square in front is empty.
+		if to := from + 2*forward; singlePush && from/8 == startRank && empty&squareBit(to) != 0 {
+			moves = append(moves, Move{
+				From:  origin,
+				To:    gochess.BitCoordinate(to),
+				Piece: pawn,
+				Flags: FlagDoublePush,
+			})
+		}
 
-// kingMoves returns valid king moves.
-func (c Chess) kingMoves(origin gochess.Coordinate) []string {
-	offsets := []gochess.Coordinate{
-		{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: -1},
-		{X: 0, Y: 1}, {X: 0, Y: -1},
-		{X: -1, Y: 1}, {X: -1, Y: 0}, {X: -1, Y: -1},
+		if enPassant >= 0 && pawnAttacks[colorIndex(us)][from]&squareBit(enPassant) != 0 {
+			moves = append(moves, Move{
+				From:     origin,
+				To:       gochess.BitCoordinate(enPassant),
+				Piece:    pawn,
+				Captured: opponent(us) | gochess.Pawn,
+				Flags:    FlagEnPassant,
+			})
+		}
 	}
 
-	return c.oneStepPieces(origin, offsets)
+	return moves
 }
 
-// kingCastleMoves returns valid castle moves.
-func (c Chess) kingCastleMoves(origin gochess.Coordinate) []string {
-	if c.availableCastles == "-" {
-		return nil
-	}
-
-	p, _ := c.board.Square(origin)
-	kingColor := gochess.PieceColor(p)
-
-	castleDirections := map[string]int{
-		"k": 1, "K": 1,
-		"q": -1, "Q": -1,
-	}
-
-	moves := make([]string, 0, 2)
-	for castle, dir := range castleDirections {
-		if !strings.Contains(c.availableCastles, castle) {
+// castleMoves appends the pseudo-legal castle moves of the current turn to
+// moves. The squares the king passes through are checked later by isCastleLegal.
+//
+// Every square the king and the rook pass through or land on must be empty,
+// except for the squares of the castling king and rook themselves.
+func (c *Chess) castleMoves(moves []Move) []Move {
+	us := colorIndex(c.turn)
+	origin := c.kingsPosition(c.turn)
+	if origin != gochess.Coor(c.castleKingFiles[us], backRank(c.turn)) {
+		return moves
+	}
+
+	for _, side := range []int{kingSide, queenSide} {
+		rookFile := c.castles[us][side]
+		if rookFile == noCastle {
 			continue
 		}
 
-		if gochess.Pieces[castle]&kingColor == gochess.Empty {
+		rookOrigin := gochess.Coor(rookFile, origin.Y)
+		if c.pieceAt(rookOrigin) != c.turn|gochess.Rook {
 			continue
 		}
 
-		ts, err := c.board.Square(gochess.Coor(origin.X+dir, origin.Y))
-		if err != nil || ts != gochess.Empty {
-			continue
+		m := Move{From: origin, To: rookOrigin, Piece: c.turn | gochess.King, Flags: FlagCastle}
+		kingTarget, _, rookTarget := c.castleSquares(m)
+		if !c.config.Chess960 {
+			m.To = kingTarget
 		}
 
-		ts, err = c.board.Square(gochess.Coor(origin.X+2*dir, origin.Y))
-		if err != nil || ts != gochess.Empty {
+		if !c.isCastlePathEmpty(origin, kingTarget, rookOrigin, rookTarget) {
 			continue
 		}
 
-		moves = append(moves, UCI(origin, gochess.Coor(origin.X+2*dir, origin.Y)))
-
-		if len(moves) == 2 {
-			break
-		}
+		moves = append(moves, m)
 	}
 
 	return moves
 }
 
-// rookMoves returns valid rook moves.
-func (c Chess) rookMoves(origin gochess.Coordinate) []string {
-	offsets := []gochess.Coordinate{{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1}}
-	return c.slidingPieces(origin, offsets)
-}
-
-// bishopMoves returns valid bishop moves.
-func (c Chess) bishopMoves(origin gochess.Coordinate) []string {
-	offsets := []gochess.Coordinate{{X: 1, Y: 1}, {X: -1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: -1}}
-	return c.slidingPieces(origin, offsets)
-}
-
-// queenMoves returns valid queen moves.
-func (c Chess) queenMoves(origin gochess.Coordinate) []string {
-	return append(c.rookMoves(origin), c.bishopMoves(origin)...)
-}
-
-// slidingPieces returns valid moves for sliding pieces.
-func (c Chess) slidingPieces(origin gochess.Coordinate, offsets []gochess.Coordinate) []string {
-	p, _ := c.board.Square(origin)
-
-	color := gochess.PieceColor(p)
-	moves := make([]string, 0, capacityByPiece[p])
-	for _, d := range offsets {
-		for i := 1; ; i++ {
-			tCor := gochess.Coor(origin.X+i*d.X, origin.Y+i*d.Y)
-			ts, err := c.board.Square(tCor)
-			if err != nil {
-				break
-			}
-
-			if ts == gochess.Empty {
-				moves = append(moves, UCI(origin, tCor))
-				continue
-			}
-
-			if ts&color == gochess.Empty {
-				moves = append(moves, UCI(origin, tCor))
-				break
-			}
-
-			// If the piece is the same color, stop looking in that direction.
-			break
-		}
-	}
-
-	return moves
-}
-
-func (c Chess) oneStepPieces(origin gochess.Coordinate, offsets []gochess.Coordinate) []string {
-	p, _ := c.board.Square(origin)
-
-	color := gochess.PieceColor(p)
-	moves := make([]string, 0, 8)
-	for _, d := range offsets {
-		tCor := gochess.Coor(origin.X+d.X, origin.Y+d.Y)
-		ts, err := c.board.Square(tCor)
-		if err != nil {
-			continue
-		}
-
-		if ts == gochess.Empty {
-			moves = append(moves, UCI(origin, tCor))
+// isCastlePathEmpty returns true if every square between the king and
+// rook origins and targets is empty, ignoring the king and the rook.
+func (c *Chess) isCastlePathEmpty(kingOrigin, kingTarget, rookOrigin, rookTarget gochess.Coordinate) bool {
+	from := min(kingOrigin.X, kingTarget.X, rookOrigin.X, rookTarget.X)
+	to := max(kingOrigin.X, kingTarget.X, rookOrigin.X, rookTarget.X)
+	for x := from; x <= to; x++ {
+		if x == kingOrigin.X || x == rookOrigin.X {
 			continue
 		}
 
-		if ts&color == gochess.Empty {
-			moves = append(moves, UCI(origin, tCor))
+		if c.pieceAt(gochess.Coor(x, kingOrigin.Y)) != gochess.Empty {
+			return false
 		}
 	}
 
-	return moves
-}
-
-// isCastleMove returns if the move is a castle move.
-//
-// The passed move must be valid.
-func (c Chess) isCastleMove(move string) bool {
-	if castlesMoves[move] != c.turn {
-		return false
-	}
-
-	origin, _ := AlgebraicToCoordinate(move[:2])
-	p, _ := c.board.Square(origin)
-
-	return p == gochess.King|c.turn
+	return true
 }
 
-// isEnPassantMove returns if the move is an en passant capture move.
+// legalMoves appends the legal moves of the given kind for the current turn
+// to moves. It doesn't allocate when moves has enough capacity.
 //
-// The passed move must be valid.
-func (c Chess) isEnPassantMove(move string) bool {
-	if c.enPassantSquare == "" {
-		return false
-	}
-
-	origin, _ := AlgebraicToCoordinate(move[:2])
-
-	if move[2:4] != c.enPassantSquare {
-		return false
-	}
-
-	p, _ := c.board.Square(origin)
-	return gochess.PieceType(p) == gochess.Pawn
-}
-
-// destinationMatch looks for a destination in a list of moves.
-// It returns true if any of the moves has the destination.
-// The function expects the moves in UCI format.
-func destinationMatch(moves []string, destination gochess.Coordinate) bool {
-	algCoor := CoordinateToAlgebraic(destination)
-	for _, move := range moves {
-		dest := move[2:4]
-		if dest == algCoor {
-			return true
+// The checkers and the pinned pieces are found once per position, so the
+// pseudo-legal moves are validated without making them: in check, the moves
+// of the other pieces must capture the checker or block its ray, pinned
+// pieces must stay in their pin rays and the king can't move to an attacked
+// square. Only the en passant captures and the castles need a closer look.
+func (c *Chess) legalMoves(moves []Move, kind moveKind) []Move {
+	if moves == nil {
+		moves = make([]Move, 0, 48)
+	}
+
+	start := len(moves)
+	moves = c.availableMoves(moves, kind)
+	b := c.bits
+	us, them := c.turn, opponent(c.turn)
+	kings := b.Pieces(us | gochess.King)
+	if kings == 0 {
+		return moves
+	}
+
+	king := bits.TrailingZeros64(kings)
+	occupied := b.Occupied()
+	checkers := attackersOf(b, king, them, occupied)
+
+	// The moves that resolve a check. Only the king can move in a double
+	// check.
+	evasions := ^uint64(0)
+	switch bits.OnesCount64(checkers) {
+	case 0:
+	case 1:
+		evasions = checkers | between(king, bits.TrailingZeros64(checkers))
+	default:
+		evasions = 0
+	}
+
+	var pinned uint64
+	var pinRays [64]uint64
+	var buf [8]Pin
+	for _, p := range pins(b, us, buf[:0]) {
+		sq := gochess.BitIndex(p.Square)
+		pinned |= squareBit(sq)
+		pinRays[sq] = p.Ray
+	}
+
+	legalMoves := moves[:start]
+	for _, m := range moves[start:] {
+		from, to := gochess.BitIndex(m.From), gochess.BitIndex(m.To)
+
+		var legal bool
+		switch {
+		case m.IsCastle():
+			legal = checkers == 0 && c.isCastleLegal(m)
+		case m.Piece == us|gochess.King:
+			// The king can't hide behind itself from a sliding checker.
+			legal = attackersOf(b, to, them, occupied&^kings) == 0
+		case m.IsEnPassant():
+			// The captured pawn leaves the board too, which may uncover a
+			// check along the rank of the capture.
+			captured := gochess.BitIndex(gochess.Coor(m.To.X, m.From.Y))
+			after := occupied&^squareBit(from)&^squareBit(captured) | squareBit(to)
+			legal = attackersOf(b, king, them, after)&^squareBit(captured) == 0
+		default:
+			legal = evasions&squareBit(to) != 0 &&
+				(pinned&squareBit(from) == 0 || pinRays[from]&squareBit(to) != 0)
 		}
-	}
-
-	return false
-}
 
-// legalMoves returns the legal moves for the current turn.
-func (c Chess) legalMoves() []string {
-	moves := c.availableMoves()
-	legalMoves := make([]string, 0, len(moves))
-
-	goroutinesCount := c.config.Parallelism
-	_, ok := c.board.(Cloner)
-	if !ok || goroutinesCount <= 1 {
-		return c.calculateLegalMovesSecuentially(moves)
-	}
-
-	wg := &sync.WaitGroup{}
-	availableMovesChan := make(chan string, goroutinesCount)
-	legalMovesChan := make(chan string, len(moves))
-	wg.Add(goroutinesCount)
-	for range goroutinesCount {
-		go func() {
-			defer wg.Done()
-			copy := c.clone()
-
-			for move := range availableMovesChan {
-				if copy.isLegalMove(move) {
-					legalMovesChan <- move
-				}
-			}
-		}()
-	}
-
-	for _, move := range moves {
-		availableMovesChan <- move
-	}
-
-	close(availableMovesChan)
-	wg.Wait()
-	close(legalMovesChan)
-
-	for move := range legalMovesChan {
-		legalMoves = append(legalMoves, move)
-	}
-
-	return legalMoves
-}
-
-func (c Chess) calculateLegalMovesSecuentially(moves []string) []string {
-	legalMoves := make([]string, 0, len(moves))
-	for _, move := range moves {
-		if c.isLegalMove(move) {
-			legalMoves = append(legalMoves, move)
+		if legal {
+			legalMoves = append(legalMoves, m)
 		}
 	}
 
 	return legalMoves
 }
 
-// availableMoves returns the available moves for the current turn without checking if they are legal.
-func (c Chess) availableMoves() []string {
-	moves := make([]string, 0, 40)
-	for x := range 8 {
-		for y := range 8 {
-			origin := gochess.Coor(x, y)
-			piece, _ := c.board.Square(origin)
-			if piece&c.turn == gochess.Empty {
-				continue
-			}
-
-			moves = append(moves, c.movesForPiece(piece, origin)...)
-		}
-	}
-
-	return moves
-}
-
-// isLegalMove is a helper function that verifies if the move is legal.
+// isCastleLegal returns true if a castle move of a king that is not in check
+// is legal.
 //
-// It verifies it making the move in a temporary board and checking if the
-// king is in check or the king way is under attack in castling moves.
-func (c Chess) isLegalMove(move string) bool {
-	kingsColor := c.turn
-
-	c.makeMove(move)
-
-	availableMoves := c.availableMoves()
-	kingPosition := c.kingsPosition(kingsColor)
-
-	kingUnderAttack := destinationMatch(availableMoves, kingPosition)
-	c.unmakeMove()
-
-	// If the king is under attack, the move is not legal.
-	if kingUnderAttack {
-		return false
-	}
-
-	// FIDE rule 3.8.2: castling has three restrictions on attacked squares.
-	if c.isCastleMove(move) {
-		// (1) Cannot castle while in check. isCheck() is called on the restored
-		//     pre-castle position, so the king is still on its starting square and
-		//     pawn attacks to that square are generated correctly.
-		if c.isCheck() {
-			return false
-		}
-		// (2) Cannot castle through check (king passage square under attack).
-		if destinationMatch(availableMoves, castleKingWay[move]) {
+// FIDE rule 3.8.2: the king can't castle while in check, through a square
+// under attack or into check.
+func (c *Chess) isCastleLegal(m Move) bool {
+	them := opponent(c.turn)
+	kingTarget, rookOrigin, rookTarget := c.castleSquares(m)
+	step := 1
+	if kingTarget.X < m.From.X {
+		step = -1
+	}
+
+	for x := m.From.X; x != kingTarget.X; {
+		x += step
+		passage := gochess.Coor(x, m.From.Y)
+		if isSquareAttacked(c.bits, gochess.BitIndex(passage), them) {
 			return false
 		}
 	}
 
-	return true
+	// The king and the rook may leave lines open or closed once they have
+	// moved.
+	occupied := c.bits.Occupied() &^ squareBit(gochess.BitIndex(m.From)) &^ squareBit(gochess.BitIndex(rookOrigin))
+	occupied |= squareBit(gochess.BitIndex(kingTarget)) | squareBit(gochess.BitIndex(rookTarget))
+	return attackersOf(c.bits, gochess.BitIndex(kingTarget), them, occupied) == 0
 }
@@ -31,9 +31,20 @@ func CoordinateToAlgebraic(c gochess.Coordinate) string {
 		return ""
 	}
 
-	return fmt.Sprintf("%c%d", 'a'+c.X, 8-c.Y)
+	return squareNames[c.Y][c.X]
 }
 
+// squareNames holds the algebraic notation of every square, indexed by its
+// row and column, so CoordinateToAlgebraic doesn't allocate.
+var squareNames = func() (names [8][8]string) {
+	for y := range 8 {
+		for x := range 8 {
+			names[y][x] = fmt.Sprintf("%c%d", 'a'+x, 8-y)
+		}
+	}
+	return names
+}()
+
 // UCI returns the UCI notation of a move.
 //
 // It receives the origin and target coordinates of the move.
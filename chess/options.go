@@ -1,7 +1,11 @@
 package chess
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/RchrdHndrcks/gochess/v2"
 )
 
 // Option is a function that configures a chess.
@@ -10,9 +14,20 @@ type Option func(*Chess) error
 // WithBoard sets the board of the chess.
 // If the board is nil, it returns an error.
 // If you want to use this option, it must be the first one.
+//
+// A *gochess.Board or *gochess.BitBoard is wrapped to implement the Cloner
+// interface. Other boards are mirrored into bitboards for the move generation,
+// so they must be 8x8 boards.
 func WithBoard(b Board) Option {
 	return func(c *Chess) error {
-		c.board = b
+		switch board := b.(type) {
+		case *gochess.Board:
+			b = newBoardAdapter(board)
+		case *gochess.BitBoard:
+			b = newBitBoardAdapter(board)
+		}
+
+		c.setBoard(b)
 		return nil
 	}
 }
@@ -30,12 +45,77 @@ func WithFEN(FEN string) Option {
 	}
 }
 
-// WithParallelism sets the number of workers to use for the moves calculation.
+// WithParallelism sets the number of workers to use for Perft and PerftDivide.
 // If the number of workers is less or equal to 1, the Chess will use the sequential
 // version without throwing goroutines.
+//
+// The legal moves are always generated sequentially, since every position is
+// validated once without making the moves.
 func WithParallelism(n int) Option {
 	return func(c *Chess) error {
 		c.config.Parallelism = n
 		return nil
 	}
 }
+
+// WithWorkerPool sets the pool of workers to use for Perft and PerftDivide
+// instead of starting new goroutines on every call. It takes precedence over
+// WithParallelism. If the pool is nil, it returns an error.
+//
+// The pool can be shared by several games. Closing it doesn't affect the
+// games, which compute perft sequentially from then on. The other methods
+// don't use the pool, since the legal moves are generated sequentially.
+func WithWorkerPool(p *WorkerPool) Option {
+	return func(c *Chess) error {
+		if p == nil {
+			return errors.New("worker pool is nil")
+		}
+
+		c.config.WorkerPool = p
+		return nil
+	}
+}
+
+// WithChess960 enables the Chess960 (Fischer Random Chess) rules.
+//
+// In Chess960 the castle moves are represented in UCI notation as the king
+// capturing its own rook (e.g. "e1h1"), and the castling field of the FEN
+// strings is read in both X-FEN and Shredder-FEN notations. The castling
+// rights of the current position are read again with the Chess960 rules,
+// so this option can be used before or after WithFEN.
+func WithChess960() Option {
+	return func(c *Chess) error {
+		c.config.Chess960 = true
+
+		castles, castleKingFiles, err := c.parseCastlingRights(strings.Split(c.actualFEN, " ")[2])
+		if err != nil {
+			return fmt.Errorf("failed to enable Chess960: %w", err)
+		}
+
+		c.castles = castles
+		c.castleKingFiles = castleKingFiles
+		c.actualFEN = c.calculateFEN()
+		c.hash = c.computeHash()
+		c.moves = c.legalMoves(c.moves[:0], allMoves)
+		return nil
+	}
+}
+
+// WithChess960Position enables the Chess960 rules and sets the starting
+// position with the given index, as returned by Chess960StartingFEN.
+// It returns an error if the index is not in the range [0, 959].
+func WithChess960Position(n int) Option {
+	return func(c *Chess) error {
+		fen, err := Chess960StartingFEN(n)
+		if err != nil {
+			return err
+		}
+
+		c.config.Chess960 = true
+		if err := c.LoadPosition(fen); err != nil {
+			return fmt.Errorf("failed to load position: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package chess
 
 import (
+	"context"
 	"testing"
 
 	"github.com/RchrdHndrcks/gochess/v2"
@@ -17,6 +18,62 @@ func TestWithParallelism(t *testing.T) {
 	}
 }
 
+func TestWithWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(context.Background(), 2)
+	defer pool.Close()
+
+	c, err := New(WithWorkerPool(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.config.WorkerPool != pool {
+		t.Errorf("expected the worker pool to be used")
+	}
+
+	if _, err := New(WithWorkerPool(nil)); err == nil {
+		t.Errorf("expected an error for a nil worker pool")
+	}
+}
+
+func TestSyncTo(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []string{"e2e4", "e7e5", "g1f3"} {
+		if err := c.MakeMove(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cloned := c.clone()
+
+	c.UnmakeMove()
+	for _, m := range []string{"f1c4", "b8c6"} {
+		if err := c.MakeMove(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !cloned.syncTo(c) {
+		t.Fatal("expected the copy to be synchronized")
+	}
+
+	if cloned.hash != c.hash || len(cloned.history) != len(c.history) {
+		t.Errorf("expected the copy to reach the position of the game")
+	}
+
+	if err := c.LoadPosition("4k3/8/8/8/8/8/8/4K3 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cloned.syncTo(c) {
+		t.Errorf("expected a loaded position not to be synchronized")
+	}
+}
+
 func TestWithBoard(t *testing.T) {
 	board, err := gochess.NewBoard(8)
 	if err != nil {
@@ -32,3 +89,44 @@ func TestWithBoard(t *testing.T) {
 		t.Errorf("expected board width to be 8, got %d", c.board.Width())
 	}
 }
+
+func TestWithBoard_BitBoard(t *testing.T) {
+	board := gochess.DefaultChessBitBoard()
+
+	c, err := New(WithBoard(board))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.bits != board {
+		t.Errorf("expected the bitboard to be used for move generation")
+	}
+
+	if len(c.AvailableMoves()) != 20 {
+		t.Errorf("expected 20 moves, got %d", len(c.AvailableMoves()))
+	}
+}
+
+func TestWithBoard_CustomBoardIsMirrored(t *testing.T) {
+	c, err := New(WithBoard(newBoardAdapter(gochess.DefaultChessBoard())), WithParallelism(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.mirrored {
+		t.Fatal("expected a custom board to be mirrored")
+	}
+
+	if err := c.MakeMove("e2e4"); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := c.board.Square(gochess.Coor(4, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p != gochess.White|gochess.Pawn {
+		t.Errorf("expected the move to be written to the custom board, got %d", p)
+	}
+}
@@ -0,0 +1,275 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+)
+
+// Termination is the reason why a game ended.
+type Termination int
+
+const (
+	// TerminationNone means the game is still in progress.
+	TerminationNone Termination = iota
+	// TerminationCheckmate means the side to move is checkmated.
+	TerminationCheckmate
+	// TerminationStalemate means the side to move has no legal moves and
+	// is not in check.
+	TerminationStalemate
+	// TerminationInsufficientMaterial means neither side can checkmate.
+	TerminationInsufficientMaterial
+	// TerminationSeventyFiveMoveRule means 75 moves were played by each side
+	// without captures or pawn moves.
+	TerminationSeventyFiveMoveRule
+	// TerminationFivefoldRepetition means the same position occurred five times.
+	TerminationFivefoldRepetition
+	// TerminationResignation means one of the players resigned.
+	TerminationResignation
+	// TerminationTimeout means one of the players ran out of time.
+	TerminationTimeout
+	// TerminationAgreement means the players agreed to a draw.
+	TerminationAgreement
+	// TerminationFiftyMoveRule means a draw was claimed by the fifty-move rule.
+	TerminationFiftyMoveRule
+	// TerminationThreefoldRepetition means a draw was claimed by threefold
+	// repetition.
+	TerminationThreefoldRepetition
+)
+
+// terminationNames are the human readable names of the terminations.
+var terminationNames = map[Termination]string{
+	TerminationNone:                 "none",
+	TerminationCheckmate:            "checkmate",
+	TerminationStalemate:            "stalemate",
+	TerminationInsufficientMaterial: "insufficient material",
+	TerminationSeventyFiveMoveRule:  "seventy-five-move rule",
+	TerminationFivefoldRepetition:   "fivefold repetition",
+	TerminationResignation:          "resignation",
+	TerminationTimeout:              "timeout",
+	TerminationAgreement:            "agreement",
+	TerminationFiftyMoveRule:        "fifty-move rule",
+	TerminationThreefoldRepetition:  "threefold repetition",
+}
+
+// String returns the human readable name of the termination.
+func (t Termination) String() string {
+	if name, ok := terminationNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Termination(%d)", int(t))
+}
+
+// Outcome is the result of a game.
+type Outcome struct {
+	// Winner is the color of the winner, or gochess.Empty if the game is
+	// drawn or still in progress.
+	Winner gochess.Piece
+	// Result is the PGN result string: "1-0", "0-1", "1/2-1/2" or "*".
+	Result string
+	// Termination is the reason why the game ended.
+	Termination Termination
+}
+
+// IsOver returns true if the game has ended.
+func (o Outcome) IsOver() bool {
+	return o.Termination != TerminationNone
+}
+
+// ErrGameOver is returned when an action is not allowed because the game
+// has already ended.
+var ErrGameOver = errors.New("game is over")
+
+// Outcome returns the outcome of the game.
+//
+// A game ends automatically by checkmate, stalemate, insufficient material,
+// the seventy-five-move rule or fivefold repetition. It also ends when a
+// player resigns, runs out of time, the players agree to a draw or a draw
+// is claimed, or the flag of a player falls on the clock of the game. Once
+// the game has ended, MakeMove and PlayMove return ErrGameOver.
+func (c *Chess) Outcome() Outcome {
+	if c.outcome.IsOver() {
+		return c.outcome
+	}
+
+	c.refresh()
+	switch {
+	case c.checkmate:
+		return newWin(opponent(c.turn), TerminationCheckmate)
+	case c.stalemate:
+		return newDraw(TerminationStalemate)
+	case c.IsInsufficientMaterial():
+		return newDraw(TerminationInsufficientMaterial)
+	case c.halfMoves >= 150:
+		return newDraw(TerminationSeventyFiveMoveRule)
+	case c.repetitions() >= 5:
+		return newDraw(TerminationFivefoldRepetition)
+	}
+
+	if o, ok := c.flagOutcome(); ok {
+		return o
+	}
+
+	return Outcome{Winner: gochess.Empty, Result: chesspgn.ResultOngoing, Termination: TerminationNone}
+}
+
+// Resign ends the game with the given color resigning.
+//
+// It returns an error if the color is not valid or the game is over.
+func (c *Chess) Resign(color gochess.Piece) error {
+	if err := c.validateAction(color); err != nil {
+		return err
+	}
+
+	c.end(newWin(opponent(color), TerminationResignation))
+	return nil
+}
+
+// Timeout ends the game with the given color running out of time.
+//
+// The opponent wins, unless no legal sequence of moves could lead to its
+// checkmating the color, in which case the game is drawn. It returns an
+// error if the color is not valid or the game is over.
+func (c *Chess) Timeout(color gochess.Piece) error {
+	if err := c.validateAction(color); err != nil {
+		return err
+	}
+
+	c.end(c.timeoutOutcome(color))
+	return nil
+}
+
+// timeoutOutcome returns the outcome of a game where the given color ran out
+// of time.
+func (c *Chess) timeoutOutcome(color gochess.Piece) Outcome {
+	if !c.hasMatingMaterial(opponent(color)) {
+		return newDraw(TerminationTimeout)
+	}
+
+	return newWin(opponent(color), TerminationTimeout)
+}
+
+// OfferDraw records a draw offer from the given color.
+//
+// The offer stands until the opponent accepts it with AcceptDraw or makes
+// a move. It returns an error if the color is not valid or the game is over.
+func (c *Chess) OfferDraw(color gochess.Piece) error {
+	if err := c.validateAction(color); err != nil {
+		return err
+	}
+
+	c.drawOffer = color
+	return nil
+}
+
+// AcceptDraw accepts the draw offered by the opponent of the given color
+// and ends the game.
+//
+// It returns an error if the color is not valid, the game is over or the
+// opponent has not offered a draw.
+func (c *Chess) AcceptDraw(color gochess.Piece) error {
+	if err := c.validateAction(color); err != nil {
+		return err
+	}
+
+	if c.drawOffer != opponent(color) {
+		return errors.New("there is no draw offer to accept")
+	}
+
+	c.end(newDraw(TerminationAgreement))
+	return nil
+}
+
+// ClaimDraw ends the game with a draw claimed by the side to move under the
+// fifty-move rule or threefold repetition.
+//
+// It returns an error if the game is over or none of the rules applies.
+func (c *Chess) ClaimDraw() error {
+	if err := c.validateAction(c.turn); err != nil {
+		return err
+	}
+
+	switch {
+	case c.IsFiftyMoveRule():
+		c.end(newDraw(TerminationFiftyMoveRule))
+	case c.IsThreefoldRepetition():
+		c.end(newDraw(TerminationThreefoldRepetition))
+	default:
+		return errors.New("no draw can be claimed in the current position")
+	}
+
+	return nil
+}
+
+// end ends the game with the given outcome, pausing the clock.
+func (c *Chess) end(o Outcome) {
+	c.outcome = o
+	if c.clock != nil {
+		c.clock.Pause()
+	}
+}
+
+// validateAction returns an error if the color is not valid or the game is over.
+func (c *Chess) validateAction(color gochess.Piece) error {
+	if color != gochess.White && color != gochess.Black {
+		return fmt.Errorf("invalid color: %d", color)
+	}
+
+	if o := c.Outcome(); o.IsOver() {
+		return fmt.Errorf("%w: %s", ErrGameOver, o.Termination)
+	}
+
+	return nil
+}
+
+// darkSquares are the dark squares of the board, indexed by gochess.BitIndex.
+const darkSquares uint64 = 0xAA55AA55AA55AA55
+
+// hasMatingMaterial returns true if the given color could checkmate its
+// opponent by any legal sequence of moves, as decided by FIDE 6.9 when the
+// opponent runs out of time.
+//
+// The pieces of the opponent count too: a lone knight can mate unless the
+// opponent only has queens besides the king, and a lone bishop can mate if
+// there are pawns, knights or bishops on both square colors.
+func (c *Chess) hasMatingMaterial(color gochess.Piece) bool {
+	own := c.bits.Color(color) &^ c.bits.Pieces(color|gochess.King)
+	heavy := c.bits.Pieces(color|gochess.Pawn) | c.bits.Pieces(color|gochess.Rook) | c.bits.Pieces(color|gochess.Queen)
+	if heavy != 0 {
+		return true
+	}
+
+	other := opponent(color)
+	if own&c.bits.Pieces(color|gochess.Knight) != 0 {
+		rest := c.bits.Color(other) &^ c.bits.Pieces(other|gochess.King) &^ c.bits.Pieces(other|gochess.Queen)
+		return own&(own-1) != 0 || rest != 0
+	}
+
+	if own&c.bits.Pieces(color|gochess.Bishop) != 0 {
+		bishops := c.bits.Pieces(gochess.White|gochess.Bishop) | c.bits.Pieces(gochess.Black|gochess.Bishop)
+		pawns := c.bits.Pieces(gochess.White|gochess.Pawn) | c.bits.Pieces(gochess.Black|gochess.Pawn)
+		knights := c.bits.Pieces(gochess.White|gochess.Knight) | c.bits.Pieces(gochess.Black|gochess.Knight)
+		sameColor := bishops&darkSquares == 0 || bishops&^darkSquares == 0
+		return !sameColor || pawns != 0 || knights != 0
+	}
+
+	return false
+}
+
+// newWin returns the outcome of a game won by the given color.
+func newWin(winner gochess.Piece, termination Termination) Outcome {
+	result := chesspgn.ResultWhiteWins
+	if winner == gochess.Black {
+		result = chesspgn.ResultBlackWins
+	}
+
+	return Outcome{Winner: winner, Result: result, Termination: termination}
+}
+
+// newDraw returns the outcome of a drawn game.
+func newDraw(termination Termination) Outcome {
+	return Outcome{Winner: gochess.Empty, Result: chesspgn.ResultDraw, Termination: termination}
+}
@@ -0,0 +1,224 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		moves    []string
+		expected chess.Outcome
+	}{
+		{
+			name:     "Ongoing",
+			fen:      "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			expected: chess.Outcome{Winner: gochess.Empty, Result: chesspgn.ResultOngoing, Termination: chess.TerminationNone},
+		},
+		{
+			name:     "Checkmate",
+			fen:      "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2",
+			moves:    []string{"d8h4"},
+			expected: chess.Outcome{Winner: gochess.Black, Result: chesspgn.ResultBlackWins, Termination: chess.TerminationCheckmate},
+		},
+		{
+			name:     "Stalemate",
+			fen:      "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			expected: chess.Outcome{Winner: gochess.Empty, Result: chesspgn.ResultDraw, Termination: chess.TerminationStalemate},
+		},
+		{
+			name:     "Insufficient material",
+			fen:      "4k3/8/8/8/8/8/8/4K1N1 w - - 0 1",
+			expected: chess.Outcome{Winner: gochess.Empty, Result: chesspgn.ResultDraw, Termination: chess.TerminationInsufficientMaterial},
+		},
+		{
+			name:     "Seventy-five-move rule",
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 149 100",
+			moves:    []string{"a1a2"},
+			expected: chess.Outcome{Winner: gochess.Empty, Result: chesspgn.ResultDraw, Termination: chess.TerminationSeventyFiveMoveRule},
+		},
+		{
+			name: "Fivefold repetition",
+			fen:  "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			moves: []string{
+				"g1f3", "g8f6", "f3g1", "f6g8",
+				"g1f3", "g8f6", "f3g1", "f6g8",
+				"g1f3", "g8f6", "f3g1", "f6g8",
+				"g1f3", "g8f6", "f3g1", "f6g8",
+			},
+			expected: chess.Outcome{Winner: gochess.Empty, Result: chesspgn.ResultDraw, Termination: chess.TerminationFivefoldRepetition},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c, err := chess.New(chess.WithFEN(tt.fen))
+			require.NoError(t, err)
+
+			// Act
+			for _, m := range tt.moves {
+				require.NoError(t, c.MakeMove(m))
+			}
+
+			// Assert
+			assert.Equal(t, tt.expected, c.Outcome())
+			assert.Equal(t, tt.expected.Termination != chess.TerminationNone, c.Outcome().IsOver())
+		})
+	}
+
+	t.Run("Moves are rejected after an automatic ending", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/8/4K1N1 w - - 0 1"))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, c.MakeMove("e1e2"), chess.ErrGameOver)
+	})
+}
+
+func TestResign(t *testing.T) {
+	c, err := chess.New()
+	require.NoError(t, err)
+	require.NoError(t, c.MakeMove("e2e4"))
+
+	require.NoError(t, c.Resign(gochess.White))
+
+	assert.Equal(t, chess.Outcome{
+		Winner:      gochess.Black,
+		Result:      chesspgn.ResultBlackWins,
+		Termination: chess.TerminationResignation,
+	}, c.Outcome())
+	assert.ErrorIs(t, c.MakeMove("e7e5"), chess.ErrGameOver)
+	assert.ErrorIs(t, c.Resign(gochess.Black), chess.ErrGameOver)
+
+	c, err = chess.New()
+	require.NoError(t, err)
+	assert.Error(t, c.Resign(gochess.Empty))
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("Opponent wins", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		require.NoError(t, c.Timeout(gochess.Black))
+
+		assert.Equal(t, gochess.White, c.Outcome().Winner)
+		assert.Equal(t, chesspgn.ResultWhiteWins, c.Outcome().Result)
+		assert.Equal(t, chess.TerminationTimeout, c.Outcome().Termination)
+	})
+
+	t.Run("Material of the color that ran out of time", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			fen    string
+			color  gochess.Piece
+			winner gochess.Piece
+		}{
+			{"Lone king", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", gochess.White, gochess.Empty},
+			{"Knight against a rook", "4k1n1/8/8/8/8/8/8/R3K3 w - - 0 1", gochess.White, gochess.Black},
+			{"Knight against pawns and a queen", "7k/8/8/8/8/2n5/PQ6/K7 w - - 0 1", gochess.White, gochess.Black},
+			{"Knight against a queen", "7k/8/8/8/8/2n5/1Q6/K7 w - - 0 1", gochess.White, gochess.Empty},
+			{"Bishop against a rook", "4k2r/8/8/8/8/8/8/2B1K3 b - - 0 1", gochess.Black, gochess.Empty},
+			{"Bishop against a pawn", "4k3/p7/8/8/8/8/8/2B1K3 b - - 0 1", gochess.Black, gochess.White},
+			{"Bishop against a knight", "4k1n1/8/8/8/8/8/8/2B1K3 b - - 0 1", gochess.Black, gochess.White},
+			{"Bishops on the same color", "4kb1r/8/8/8/8/8/8/2B1K3 b - - 0 1", gochess.Black, gochess.Empty},
+			{"Bishops on opposite colors", "2b1k3/8/8/8/8/8/8/2B1K3 b - - 0 1", gochess.Black, gochess.White},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c, err := chess.New(chess.WithFEN(tt.fen))
+				require.NoError(t, err)
+
+				require.NoError(t, c.Timeout(tt.color))
+
+				assert.Equal(t, tt.winner, c.Outcome().Winner)
+				assert.Equal(t, chess.TerminationTimeout, c.Outcome().Termination)
+			})
+		}
+	})
+}
+
+func TestDrawOffer(t *testing.T) {
+	t.Run("Accepted", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		require.NoError(t, c.OfferDraw(gochess.White))
+		require.NoError(t, c.MakeMove("e2e4"))
+		require.NoError(t, c.AcceptDraw(gochess.Black))
+
+		assert.Equal(t, chess.Outcome{
+			Winner:      gochess.Empty,
+			Result:      chesspgn.ResultDraw,
+			Termination: chess.TerminationAgreement,
+		}, c.Outcome())
+		assert.ErrorIs(t, c.MakeMove("e7e5"), chess.ErrGameOver)
+	})
+
+	t.Run("Declined by moving", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		require.NoError(t, c.OfferDraw(gochess.White))
+		require.NoError(t, c.MakeMove("e2e4"))
+		require.NoError(t, c.MakeMove("e7e5"))
+
+		assert.Error(t, c.AcceptDraw(gochess.Black))
+		assert.False(t, c.Outcome().IsOver())
+	})
+
+	t.Run("Own offer cannot be accepted", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		require.NoError(t, c.OfferDraw(gochess.White))
+
+		assert.Error(t, c.AcceptDraw(gochess.White))
+	})
+}
+
+func TestClaimDraw(t *testing.T) {
+	t.Run("Fifty-move rule", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/8/R3K3 w - - 100 60"))
+		require.NoError(t, err)
+
+		require.NoError(t, c.ClaimDraw())
+
+		assert.Equal(t, chess.TerminationFiftyMoveRule, c.Outcome().Termination)
+		assert.Equal(t, chesspgn.ResultDraw, c.Outcome().Result)
+	})
+
+	t.Run("Threefold repetition", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+		for _, m := range []string{"g1f3", "g8f6", "f3g1", "f6g8", "g1f3", "g8f6", "f3g1", "f6g8"} {
+			require.NoError(t, c.MakeMove(m))
+		}
+
+		require.NoError(t, c.ClaimDraw())
+
+		assert.Equal(t, chess.TerminationThreefoldRepetition, c.Outcome().Termination)
+	})
+
+	t.Run("Nothing to claim", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		assert.Error(t, c.ClaimDraw())
+		assert.False(t, c.Outcome().IsOver())
+	})
+}
+
+func TestTermination_String(t *testing.T) {
+	assert.Equal(t, "checkmate", chess.TerminationCheckmate.String())
+	assert.Equal(t, "threefold repetition", chess.TerminationThreefoldRepetition.String())
+	assert.Equal(t, "Termination(99)", chess.Termination(99).String())
+}
@@ -0,0 +1,261 @@
+package chess
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// PerftCase is a position of a perft suite with its expected leaf node
+	// counts by depth.
+	PerftCase struct {
+		// Line is the line of the suite where the position was read.
+		Line int
+		// FEN is the position.
+		FEN string
+		// Nodes are the expected leaf node counts indexed by depth.
+		Nodes map[int]uint64
+	}
+
+	// PerftMismatch is a depth of a perft suite position whose leaf node
+	// count differs from the expected one.
+	PerftMismatch struct {
+		// Line is the line of the suite where the position was read.
+		Line int
+		// FEN is the position.
+		FEN string
+		// Depth is the depth of the mismatching count.
+		Depth int
+		// Expected is the expected leaf node count.
+		Expected uint64
+		// Got is the leaf node count calculated by the move generator.
+		Got uint64
+	}
+)
+
+// String returns a human readable description of the mismatch.
+func (m PerftMismatch) String() string {
+	return fmt.Sprintf("line %d: %s: depth %d: expected %d nodes, got %d",
+		m.Line, m.FEN, m.Depth, m.Expected, m.Got)
+}
+
+// Perft returns the number of leaf nodes of the legal move tree of the
+// current position at the given depth.
+//
+// It is the standard way to verify a move generator against known results.
+// The root moves are distributed among the workers configured with the
+// WithParallelism or WithWorkerPool options, unless the tree is small
+// enough to be computed faster sequentially. The game is left unchanged.
+func (c *Chess) Perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	var total uint64
+	for _, n := range c.PerftDivide(depth) {
+		total += n
+	}
+
+	return total
+}
+
+// PerftDivide returns the number of leaf nodes at the given depth below
+// each legal move of the current position, keyed by the move in UCI
+// notation.
+//
+// The root moves are distributed among the workers configured with the
+// WithParallelism or WithWorkerPool options, unless the tree is small
+// enough to be computed faster sequentially. The game is left unchanged.
+func (c *Chess) PerftDivide(depth int) map[string]uint64 {
+	c.refresh()
+	divide := make(map[string]uint64, len(c.moves))
+	if depth <= 0 {
+		return divide
+	}
+
+	goroutinesCount := c.perftWorkers(depth)
+	if goroutinesCount <= 1 {
+		for _, m := range c.moves {
+			divide[m.UCI()] = c.perftMove(m, depth)
+		}
+
+		return divide
+	}
+
+	if c.config.WorkerPool != nil {
+		c.config.WorkerPool.perftDivide(c, depth, divide)
+		return divide
+	}
+
+	type result struct {
+		move  string
+		nodes uint64
+	}
+
+	wg := &sync.WaitGroup{}
+	movesChan := make(chan Move, len(c.moves))
+	resultsChan := make(chan result, len(c.moves))
+	wg.Add(goroutinesCount)
+	for range goroutinesCount {
+		go func() {
+			defer wg.Done()
+			copy := c.clone()
+
+			for m := range movesChan {
+				resultsChan <- result{move: m.UCI(), nodes: copy.perftMove(m, depth)}
+			}
+		}()
+	}
+
+	for _, m := range c.moves {
+		movesChan <- m
+	}
+
+	close(movesChan)
+	wg.Wait()
+	close(resultsChan)
+
+	for r := range resultsChan {
+		divide[r.move] = r.nodes
+	}
+
+	return divide
+}
+
+// perftMove returns the number of leaf nodes at the given depth below a
+// legal move of the current position.
+func (c *Chess) perftMove(m Move, depth int) uint64 {
+	if depth == 1 {
+		return 1
+	}
+
+	for len(c.perftMoves) < depth-1 {
+		c.perftMoves = append(c.perftMoves, make([]Move, 0, maxMoves))
+	}
+
+	c.makeMove(m)
+	nodes := c.perft(depth - 1)
+	c.unmakeMove()
+
+	return nodes
+}
+
+// perft is the sequential recursive perft. The moves of every depth are
+// generated into their buffer of perftMoves, so the tree is walked without
+// allocating.
+func (c *Chess) perft(depth int) uint64 {
+	moves := c.legalMoves(c.perftMoves[depth-1][:0], allMoves)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, m := range moves {
+		c.makeMove(m)
+		nodes += c.perft(depth - 1)
+		c.unmakeMove()
+	}
+
+	return nodes
+}
+
+// ParsePerftSuite reads a perft suite in EPD format.
+//
+// Every non-empty line must contain a position followed by the expected
+// node counts, separated by semicolons:
+//
+//	rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 ;D1 20 ;D2 400
+//
+// The halfmove clock and fullmove number of the position are optional.
+// Lines starting with '#' are ignored.
+func ParsePerftSuite(r io.Reader) ([]PerftCase, error) {
+	var cases []PerftCase
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ";")
+		fen := strings.Join(strings.Fields(fields[0]), " ")
+		switch len(strings.Fields(fen)) {
+		case 4:
+			fen += " 0 1"
+		case 6:
+		default:
+			return nil, fmt.Errorf("invalid perft suite: line %d: invalid position: %s", line, fen)
+		}
+
+		pc := PerftCase{Line: line, FEN: fen, Nodes: make(map[int]uint64, len(fields)-1)}
+		for _, field := range fields[1:] {
+			parts := strings.Fields(field)
+			if len(parts) != 2 || len(parts[0]) < 2 || parts[0][0] != 'D' {
+				return nil, fmt.Errorf("invalid perft suite: line %d: invalid depth: %s", line, field)
+			}
+
+			depth, err := strconv.Atoi(parts[0][1:])
+			if err != nil || depth < 1 {
+				return nil, fmt.Errorf("invalid perft suite: line %d: invalid depth: %s", line, field)
+			}
+
+			nodes, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid perft suite: line %d: invalid nodes: %s", line, field)
+			}
+
+			pc.Nodes[depth] = nodes
+		}
+
+		cases = append(cases, pc)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read perft suite: %w", err)
+	}
+
+	return cases, nil
+}
+
+// RunPerftSuite runs perft for every case and depth up to maxDepth and
+// returns the mismatching results. A maxDepth less or equal to 0 runs all
+// the depths of the suite.
+//
+// The options are applied to every game created for the cases, so the
+// WithParallelism option can be used to tune the perft workers.
+// It returns an error if any of the positions cannot be loaded.
+func RunPerftSuite(cases []PerftCase, maxDepth int, opts ...Option) ([]PerftMismatch, error) {
+	var mismatches []PerftMismatch
+	for _, pc := range cases {
+		c, err := New(append(opts, WithFEN(pc.FEN))...)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", pc.Line, err)
+		}
+
+		for _, depth := range slices.Sorted(maps.Keys(pc.Nodes)) {
+			if maxDepth > 0 && depth > maxDepth {
+				break
+			}
+
+			expected := pc.Nodes[depth]
+			if got := c.Perft(depth); got != expected {
+				mismatches = append(mismatches, PerftMismatch{
+					Line:     pc.Line,
+					FEN:      pc.FEN,
+					Depth:    depth,
+					Expected: expected,
+					Got:      got,
+				})
+			}
+		}
+	}
+
+	return mismatches, nil
+}
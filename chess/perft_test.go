@@ -0,0 +1,133 @@
+package chess_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// perftSuite holds well known perft results from the Chess Programming Wiki.
+const perftSuite = `
+# Initial position.
+rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 ;D1 20 ;D2 400 ;D3 8902
+# Kiwipete.
+r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - ;D1 48 ;D2 2039 ;D3 97862
+8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - ;D1 14 ;D2 191 ;D3 2812 ;D4 43238
+r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1 ;D1 6 ;D2 264 ;D3 9467
+rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8 ;D1 44 ;D2 1486 ;D3 62379
+r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10 ;D1 46 ;D2 2079 ;D3 89890
+`
+
+func TestPerft(t *testing.T) {
+	t.Run("Depth 0", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), c.Perft(0))
+	})
+
+	t.Run("Sequential and parallel match", func(t *testing.T) {
+		fen := "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+		sequential, err := chess.New(chess.WithFEN(fen), chess.WithParallelism(1))
+		require.NoError(t, err)
+		parallel, err := chess.New(chess.WithFEN(fen), chess.WithParallelism(4))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(97862), sequential.Perft(3))
+		assert.Equal(t, uint64(97862), parallel.Perft(3))
+	})
+
+	t.Run("Game is left unchanged", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+		fen := c.FEN()
+
+		c.Perft(3)
+
+		assert.Equal(t, fen, c.FEN())
+		assert.Len(t, c.AvailableMoves(), 20)
+	})
+}
+
+func TestPerftDivide(t *testing.T) {
+	c, err := chess.New(chess.WithFEN("8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1"))
+	require.NoError(t, err)
+
+	divide := c.PerftDivide(2)
+
+	assert.Len(t, divide, 14)
+	assert.Equal(t, uint64(16), divide["e2e4"])
+	assert.Equal(t, uint64(4), divide["g2g3"])
+	assert.Equal(t, uint64(2), divide["b4f4"])
+
+	var total uint64
+	for _, n := range divide {
+		total += n
+	}
+	assert.Equal(t, uint64(191), total)
+}
+
+func TestParsePerftSuite(t *testing.T) {
+	t.Run("Valid suite", func(t *testing.T) {
+		cases, err := chess.ParsePerftSuite(strings.NewReader(perftSuite))
+		require.NoError(t, err)
+		require.Len(t, cases, 6)
+
+		assert.Equal(t, 3, cases[0].Line)
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", cases[0].FEN)
+		assert.Equal(t, map[int]uint64{1: 20, 2: 400, 3: 8902}, cases[0].Nodes)
+
+		// Missing move counters are completed.
+		assert.Equal(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", cases[1].FEN)
+	})
+
+	t.Run("Invalid position", func(t *testing.T) {
+		_, err := chess.ParsePerftSuite(strings.NewReader("8/8/8 w ;D1 1"))
+		assert.ErrorContains(t, err, "line 1")
+	})
+
+	t.Run("Invalid depth", func(t *testing.T) {
+		_, err := chess.ParsePerftSuite(strings.NewReader("4k3/8/8/8/8/8/8/4K3 w - - ;X1 5"))
+		assert.ErrorContains(t, err, "invalid depth")
+	})
+
+	t.Run("Invalid nodes", func(t *testing.T) {
+		_, err := chess.ParsePerftSuite(strings.NewReader("4k3/8/8/8/8/8/8/4K3 w - - ;D1 five"))
+		assert.ErrorContains(t, err, "invalid nodes")
+	})
+}
+
+func TestRunPerftSuite(t *testing.T) {
+	t.Run("Standard suite", func(t *testing.T) {
+		cases, err := chess.ParsePerftSuite(strings.NewReader(perftSuite))
+		require.NoError(t, err)
+
+		mismatches, err := chess.RunPerftSuite(cases, 3)
+		require.NoError(t, err)
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("Mismatches are reported", func(t *testing.T) {
+		cases, err := chess.ParsePerftSuite(strings.NewReader(
+			"4k3/8/8/8/8/8/8/4K3 w - - 0 1 ;D1 5 ;D2 30 ;D3 1000"))
+		require.NoError(t, err)
+
+		mismatches, err := chess.RunPerftSuite(cases, 2)
+		require.NoError(t, err)
+		require.Len(t, mismatches, 1)
+		assert.Equal(t, 2, mismatches[0].Depth)
+		assert.Equal(t, uint64(30), mismatches[0].Expected)
+		assert.Equal(t, uint64(25), mismatches[0].Got)
+		assert.Contains(t, mismatches[0].String(), "line 1")
+	})
+
+	t.Run("Invalid position", func(t *testing.T) {
+		cases := []chess.PerftCase{{Line: 7, FEN: "8/8/8/8/8/8/8/8 w - - 0 1"}}
+
+		_, err := chess.RunPerftSuite(cases, 1)
+		assert.ErrorContains(t, err, "line 7")
+	})
+}
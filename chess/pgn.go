@@ -1,117 +1,284 @@
 package chess
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 
-	"github.com/RchrdHndrcks/gochess/v2"
 	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
 )
 
+// PGNMoveError is the error returned by FromPGN when a move of the move text
+// cannot be played.
+type PGNMoveError struct {
+	// Ply is the number of the half move that failed, starting at 1.
+	Ply int
+	// Token is the move text token that failed.
+	Token string
+	// Err is the reason why the move could not be played.
+	Err error
+}
+
+// Error returns the description of the error.
+func (e *PGNMoveError) Error() string {
+	return fmt.Sprintf("ply %d: invalid move %q: %v", e.Ply, e.Token, e.Err)
+}
+
+// Unwrap returns the reason why the move could not be played.
+func (e *PGNMoveError) Unwrap() error {
+	return e.Err
+}
+
+// FromPGN creates a new chess game by replaying the mainline of a PGN game.
+//
+// The moves may be written in SAN or UCI notation. If the PGN has a FEN tag,
+// the game starts from that position. The options are applied before the
+// position is loaded. Chess960 games are detected by the Variant tag, and
+// WithChess960 can be used for the ones without it.
+//
+// It returns the tags of the game, and a *PGNMoveError if any of the moves is
+// not legal.
+func FromPGN(pgn string, opts ...Option) (*Chess, chesspgn.PGNTags, error) {
+	game, err := chesspgn.ParseGame(pgn)
+	if errors.Is(err, io.EOF) {
+		game, err = chesspgn.NewGame(), nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse PGN: %w", err)
+	}
+
+	c, err := FromPGNGame(game, nil, opts...)
+	return c, game.Tags, err
+}
+
+// FromPGNGame creates a new chess game by replaying the moves of a PGN game
+// tree from its root up to the given node, which may be in any variation.
+// If node is nil, the whole mainline is replayed.
+//
+// The starting position and the variant are taken from the tags of the game
+// as in FromPGN. It returns a *PGNMoveError if any of the moves is not legal.
+func FromPGNGame(game *chesspgn.Game, node *chesspgn.Node, opts ...Option) (*Chess, error) {
+	setUp, fen := game.Tags.Get("SetUp"), game.Tags.Get("FEN")
+	if setUp == "1" && fen == "" {
+		return nil, errors.New("failed to load PGN: SetUp tag without FEN tag")
+	}
+
+	opts = slices.Clone(opts)
+	if isChess960Variant(game.Tags.Get("Variant")) {
+		opts = append(opts, WithChess960())
+	}
+
+	if fen != "" {
+		opts = append(opts, WithFEN(fen))
+	}
+
+	c, err := New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PGN: %w", err)
+	}
+
+	var tokens []string
+	if node == nil {
+		tokens = game.Moves()
+	} else {
+		tokens = node.Moves()
+	}
+
+	for i, token := range tokens {
+		if err := c.playToken(token); err != nil {
+			return nil, &PGNMoveError{Ply: i + 1, Token: token, Err: err}
+		}
+	}
+
+	return c, nil
+}
+
+// playToken plays a move written in SAN or UCI notation. Move annotations
+// like "!" or "?!" are ignored.
+func (c *Chess) playToken(token string) error {
+	if o := c.Outcome(); o.IsOver() {
+		return fmt.Errorf("%w: %s", ErrGameOver, o.Termination)
+	}
+
+	san := strings.TrimRight(token, "!?")
+	m, err := c.ParseSAN(san)
+	if err != nil {
+		var uciErr error
+		if m, uciErr = c.ParseMove(san); uciErr != nil {
+			return err
+		}
+	}
+
+	c.playMove(m)
+	return nil
+}
+
+// PGNOption is a function that configures the PGN export.
+type PGNOption func(*pgnConfig)
+
+// pgnConfig represents configurations of how the PGN is exported.
+type pgnConfig struct {
+	// uci is true if the moves are written in UCI notation.
+	uci bool
+}
+
+// WithUCIMoveText writes the moves of the PGN in UCI notation instead of SAN.
+// It is intended for machine use, since most PGN readers expect SAN.
+func WithUCIMoveText() PGNOption {
+	return func(cfg *pgnConfig) {
+		cfg.uci = true
+	}
+}
+
 // PGN generates a PGN string from the current game state.
 //
-// It writes the seven required tag pairs and the move text using UCI notation.
-// Empty tag values default to "?". The Result tag is determined automatically
-// if not provided: "1-0" or "0-1" for checkmate, "1/2-1/2" for stalemate,
-// and "*" for an ongoing game.
-func (c *Chess) PGN(tags chesspgn.PGNTags) string {
+// It writes the seven required tag pairs, followed by the rest of the tags in
+// their order, and the move text using SAN with check and checkmate suffixes.
+// Empty values of the seven required tags default to "?". The Result
+// tag is determined automatically if not provided from the Outcome of the
+// game: "1-0", "0-1", "1/2-1/2" or "*" for an ongoing game.
+//
+// The SetUp, FEN and Variant tags are written from the game itself: if the
+// game didn't start from the initial position, the SetUp and FEN tags are
+// written with the starting position, and Chess960 games have the Variant tag.
+// Games with a clock have the TimeControl tag of the clock and a [%clk]
+// comment after every move.
+func (c *Chess) PGN(tags chesspgn.PGNTags, opts ...PGNOption) string {
+	cfg := pgnConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	var sb strings.Builder
 
-	result := c.determineResult(tags.Result)
+	result := c.determineResult(tags.Get("Result"))
+	startFEN := c.startFEN()
+
+	// Write the seven tag roster first, then the tags describing the game
+	// and finally the rest of the tags in their order.
+	for _, name := range chesspgn.SevenTagRoster {
+		if name == "Result" {
+			writeTag(&sb, name, result)
+			continue
+		}
 
-	// Write tag pairs.
-	writeTag(&sb, "Event", tagValue(tags.Event))
-	writeTag(&sb, "Site", tagValue(tags.Site))
-	writeTag(&sb, "Date", tagValue(tags.Date))
-	writeTag(&sb, "Round", tagValue(tags.Round))
-	writeTag(&sb, "White", tagValue(tags.White))
-	writeTag(&sb, "Black", tagValue(tags.Black))
-	writeTag(&sb, "Result", result)
+		writeTag(&sb, name, tagValue(tags.Get(name)))
+	}
+
+	if c.config.Chess960 {
+		writeTag(&sb, "Variant", "Chess960")
+	}
+
+	if startFEN != initialFEN || c.config.Chess960 {
+		writeTag(&sb, "SetUp", "1")
+		writeTag(&sb, "FEN", startFEN)
+	}
+
+	if c.clock != nil {
+		writeTag(&sb, "TimeControl", c.clock.Control().String())
+	}
+
+	for _, tag := range tags {
+		switch {
+		case isGeneratedTag(tag.Name),
+			tag.Name == "Variant" && c.config.Chess960,
+			tag.Name == "TimeControl" && c.clock != nil:
+			continue
+		}
+
+		writeTag(&sb, tag.Name, tag.Value)
+	}
 
 	sb.WriteString("\n")
 
 	// Write moves.
-	moveText := c.buildMoveText(result)
-	sb.WriteString(wrapLines(moveText, 80))
+	sb.WriteString(c.buildMoveText(startFEN, result, cfg))
 	sb.WriteString("\n")
 
 	return sb.String()
 }
 
-// determineResult returns the game result string.
-func (c *Chess) determineResult(provided string) string {
-	if provided != "" {
-		return provided
-	}
+// initialFEN is the FEN string of the initial position.
+const initialFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
-	if c.checkmate {
-		if c.turn == gochess.White {
-			return chesspgn.ResultBlackWins
-		}
-		return chesspgn.ResultWhiteWins
+// isGeneratedTag returns true if the tag is always written from the game
+// state instead of the provided tags.
+func isGeneratedTag(name string) bool {
+	return slices.Contains(chesspgn.SevenTagRoster[:], name) || name == "SetUp" || name == "FEN"
+}
+
+// isChess960Variant returns true if the value of a Variant tag describes a
+// Chess960 game.
+func isChess960Variant(variant string) bool {
+	switch strings.ToLower(variant) {
+	case "chess960", "chess 960", "fischerandom", "fischer random":
+		return true
 	}
 
-	if c.stalemate {
-		return chesspgn.ResultDraw
+	return false
+}
+
+// startFEN returns the FEN string of the position where the game started.
+func (c *Chess) startFEN() string {
+	if len(c.history) == 0 {
+		return c.FEN()
 	}
 
-	return chesspgn.ResultOngoing
+	return c.history[0].fen
 }
 
-// buildMoveText builds the move text from the game history.
-func (c *Chess) buildMoveText(result string) string {
-	var parts []string
-	for i, ctx := range c.history {
-		moveNum := i/2 + 1
-		if i%2 == 0 {
-			parts = append(parts, fmt.Sprintf("%d.", moveNum))
-		}
-		parts = append(parts, ctx.move)
+// determineResult returns the game result string.
+func (c *Chess) determineResult(provided string) string {
+	if provided != "" {
+		return provided
 	}
-	parts = append(parts, result)
-	return strings.Join(parts, " ")
+
+	return c.Outcome().Result
 }
 
-// wrapLines wraps text at the given column width, breaking at spaces.
-func wrapLines(text string, maxWidth int) string {
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return ""
+// buildMoveText builds the move text from the game history.
+//
+// The moves are replayed from the starting position to write them in SAN.
+// If it is black to move at the beginning, the first move number is written
+// with an ellipsis (e.g. "12..."). Moves played with a clock are followed by
+// a [%clk] comment with the remaining time of the player.
+func (c *Chess) buildMoveText(startFEN, result string, cfg pgnConfig) string {
+	replay, err := c.startingGame()
+	if err != nil {
+		// The starting position has always been loaded before, so this
+		// should never happen. Fall back to UCI, which needs no replay.
+		cfg.uci = true
 	}
 
-	var sb strings.Builder
-	lineLen := 0
+	game := chesspgn.NewGame()
+	game.Tags.Set("FEN", startFEN)
+	game.Result = result
 
-	for i, word := range words {
-		if i == 0 {
-			sb.WriteString(word)
-			lineLen = len(word)
-			continue
+	node := game.Root
+	for _, ctx := range c.history {
+		move := ctx.move.UCI()
+		if !cfg.uci {
+			move = replay.san(ctx.move)
+			replay.playMove(ctx.move)
 		}
 
-		if lineLen+1+len(word) > maxWidth {
-			sb.WriteString("\n")
-			sb.WriteString(word)
-			lineLen = len(word)
-		} else {
-			sb.WriteString(" ")
-			sb.WriteString(word)
-			lineLen += 1 + len(word)
+		node = node.AddVariation(move)
+		if ctx.clocked {
+			node.CommentsAfter = []string{chesspgn.ClockComment(ctx.clock)}
 		}
 	}
 
-	return sb.String()
+	return game.MoveText()
 }
 
 // writeTag writes a PGN tag pair to the builder.
 // It escapes backslashes and double quotes per PGN specification, and strips
 // carriage returns and newlines, which are not permitted inside tag values.
 func writeTag(sb *strings.Builder, name, value string) {
-	escaped := strings.ReplaceAll(value, `\`, `\\`)
-	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
-	escaped = strings.ReplaceAll(escaped, "\r", "")
-	escaped = strings.ReplaceAll(escaped, "\n", "")
-	sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", name, escaped))
+	sb.WriteString(chesspgn.FormatTag(name, value))
+	sb.WriteString("\n")
 }
 
 // tagValue returns the value or "?" if empty.
@@ -0,0 +1,45 @@
+package pgn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// clockCommand matches the [%clk] command of a comment.
+var clockCommand = regexp.MustCompile(`\[%clk\s+(\d+):(\d{1,2}):(\d{1,2}(?:\.\d+)?)\]`)
+
+// ClockComment returns the [%clk] command with the remaining time of a
+// player after a move, in the "H:MM:SS" format (e.g. "[%clk 1:29:57]").
+// Tenths of a second are written when the time has them.
+func ClockComment(d time.Duration) string {
+	d = max(0, d).Truncate(100 * time.Millisecond)
+
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := d % time.Minute
+
+	if seconds%time.Second == 0 {
+		return fmt.Sprintf("[%%clk %d:%02d:%02d]", hours, minutes, int(seconds/time.Second))
+	}
+
+	return fmt.Sprintf("[%%clk %d:%02d:%04.1f]", hours, minutes, seconds.Seconds())
+}
+
+// ParseClockComment returns the remaining time of the [%clk] command of a
+// comment, and false if the comment has no such command.
+func ParseClockComment(comment string) (time.Duration, bool) {
+	m := clockCommand.FindStringSubmatch(comment)
+	if m == nil {
+		return 0, false
+	}
+
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), true
+}
@@ -0,0 +1,336 @@
+package pgn
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// suffixNAGs are the NAGs of the move suffix annotations.
+var suffixNAGs = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// Game is a PGN game: its tags and the tree of its moves, including the
+// comments, NAGs and variations.
+type Game struct {
+	// Tags are the tag pairs of the game, in order.
+	Tags PGNTags
+	// Root is the root of the move tree. It has no move; its comments are the
+	// comments before the first move of the game.
+	Root *Node
+	// Result is the game termination marker, or ResultOngoing if the game
+	// does not have one.
+	Result string
+	// Line is the line of the stream where the game starts, if the game was
+	// read from a stream.
+	Line int
+}
+
+// NewGame returns an empty game without tags or moves.
+func NewGame() *Game {
+	return &Game{Tags: PGNTags{}, Root: &Node{}, Result: ResultOngoing}
+}
+
+// ParseGame parses the first game of a PGN string.
+func ParseGame(pgn string) (*Game, error) {
+	return NewReader(strings.NewReader(pgn)).Read()
+}
+
+// Moves returns the moves of the mainline.
+func (g *Game) Moves() []string {
+	var moves []string
+	for _, n := range g.Root.Mainline() {
+		moves = append(moves, n.Move)
+	}
+
+	return moves
+}
+
+// String returns the game in PGN format: its tags in order, and the move
+// text with every comment, NAG and variation.
+func (g *Game) String() string {
+	var sb strings.Builder
+	for _, tag := range g.Tags {
+		sb.WriteString(FormatTag(tag.Name, tag.Value))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(g.MoveText())
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// MoveText returns the move text of the game, ending with the game
+// termination marker. Lines are wrapped at 80 columns without breaking
+// comments.
+//
+// Moves are numbered from the FEN tag, if present.
+func (g *Game) MoveText() string {
+	w := moveTextWriter{number: 1}
+	if fields := strings.Fields(g.Tags.Get("FEN")); len(fields) == 6 {
+		w.black = fields[1] == "b"
+		if n, err := strconv.Atoi(fields[5]); err == nil && n > 0 {
+			w.number = n
+		}
+	}
+
+	w.comments(g.Root.CommentsAfter)
+	w.line(g.Root, true)
+
+	result := g.Result
+	if result == "" {
+		result = ResultOngoing
+	}
+	w.tokens = append(w.tokens, result)
+
+	return wrapTokens(w.tokens, 80)
+}
+
+// Node is a move of a game tree.
+//
+// The first child of a node is the move played after it in the line the
+// node belongs to. The rest of the children are variations: alternatives to
+// the first child.
+type Node struct {
+	// Move is the move in the notation used in the move text, without
+	// suffix annotations, which are read as NAGs. It is empty for the root.
+	Move string
+	// CommentsBefore are the comments written before the move.
+	CommentsBefore []string
+	// CommentsAfter are the comments written after the move.
+	CommentsAfter []string
+	// NAGs are the Numeric Annotation Glyphs of the move (e.g. 1 for "!").
+	NAGs []int
+	// Children are the moves that can be played after this one. The first
+	// one continues the line, the rest are variations.
+	Children []*Node
+	// Parent is the previous move, or nil for the root.
+	Parent *Node
+}
+
+// AddVariation adds a move after the node and returns it. If the node
+// already has children, the new move is a variation.
+func (n *Node) AddVariation(move string) *Node {
+	child := &Node{Move: move, Parent: n}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// RemoveVariation removes a child of the node with all its moves.
+func (n *Node) RemoveVariation(child *Node) {
+	i := slices.Index(n.Children, child)
+	if i == -1 {
+		return
+	}
+
+	n.Children = slices.Delete(n.Children, i, i+1)
+	child.Parent = nil
+}
+
+// PromoteVariation makes a child of the node its first child, so it
+// continues the line instead of being a variation.
+func (n *Node) PromoteVariation(child *Node) {
+	i := slices.Index(n.Children, child)
+	if i <= 0 {
+		return
+	}
+
+	copy(n.Children[1:i+1], n.Children[:i])
+	n.Children[0] = child
+}
+
+// Next returns the first child of the node, or nil if it has no children.
+func (n *Node) Next() *Node {
+	if len(n.Children) == 0 {
+		return nil
+	}
+
+	return n.Children[0]
+}
+
+// Mainline returns the nodes that follow the node through the first
+// children, not including the node itself.
+func (n *Node) Mainline() []*Node {
+	var nodes []*Node
+	for next := n.Next(); next != nil; next = next.Next() {
+		nodes = append(nodes, next)
+	}
+
+	return nodes
+}
+
+// Path returns the nodes from the first move of the game up to the node,
+// both included. It is empty for the root.
+func (n *Node) Path() []*Node {
+	var nodes []*Node
+	for node := n; node.Parent != nil; node = node.Parent {
+		nodes = append(nodes, node)
+	}
+
+	slices.Reverse(nodes)
+	return nodes
+}
+
+// Moves returns the moves from the first move of the game up to the node,
+// both included. They can be replayed to reach the position after the node.
+func (n *Node) Moves() []string {
+	var moves []string
+	for _, node := range n.Path() {
+		moves = append(moves, node.Move)
+	}
+
+	return moves
+}
+
+// Ply returns the number of half moves from the root to the node.
+func (n *Node) Ply() int {
+	ply := 0
+	for node := n; node.Parent != nil; node = node.Parent {
+		ply++
+	}
+
+	return ply
+}
+
+// splitSuffix splits a move from its suffix annotation, if it has a known
+// one.
+func splitSuffix(symbol string) (string, int) {
+	move := strings.TrimRight(symbol, "!?")
+	if nag, ok := suffixNAGs[symbol[len(move):]]; ok && move != "" {
+		return move, nag
+	}
+
+	return symbol, 0
+}
+
+// moveTextWriter writes the tokens of the move text of a game tree.
+type moveTextWriter struct {
+	tokens []string
+	// number is the move number of the next move.
+	number int
+	// black is true if the next move is a black move.
+	black bool
+	// forceNumber is true if the next move must be numbered even if it is a
+	// black move, because something was written after the previous move.
+	forceNumber bool
+}
+
+// line writes the moves after the node, following the first children, with
+// the variations of each move right after it.
+func (w *moveTextWriter) line(n *Node, first bool) {
+	w.forceNumber = w.forceNumber || first
+
+	for len(n.Children) > 0 {
+		main := n.Children[0]
+		number, black := w.number, w.black
+		w.move(main)
+
+		for _, variation := range n.Children[1:] {
+			start := len(w.tokens)
+			w.number, w.black, w.forceNumber = number, black, true
+			w.move(variation)
+			w.line(variation, false)
+			w.tokens[start] = "(" + w.tokens[start]
+			w.tokens[len(w.tokens)-1] += ")"
+		}
+
+		if len(n.Children) > 1 {
+			w.number, w.black = number, black
+			w.advance()
+			w.forceNumber = true
+		}
+
+		n = main
+	}
+}
+
+// move writes a move with its number, comments and NAGs, and advances to
+// the next move.
+func (w *moveTextWriter) move(n *Node) {
+	if !w.black {
+		w.tokens = append(w.tokens, fmt.Sprintf("%d.", w.number))
+	} else if w.forceNumber || len(n.CommentsBefore) > 0 {
+		w.tokens = append(w.tokens, fmt.Sprintf("%d...", w.number))
+	}
+
+	w.comments(n.CommentsBefore)
+	w.tokens = append(w.tokens, n.Move)
+	for _, nag := range n.NAGs {
+		w.tokens = append(w.tokens, "$"+strconv.Itoa(nag))
+	}
+
+	w.forceNumber = false
+	w.comments(n.CommentsAfter)
+	w.advance()
+}
+
+// comments writes comments. A move written after them must be numbered.
+func (w *moveTextWriter) comments(comments []string) {
+	for _, comment := range comments {
+		if strings.Contains(comment, "}") {
+			// A brace comment cannot contain a closing brace, but a rest of
+			// line comment can. The line break is kept by wrapTokens.
+			w.tokens = append(w.tokens, "; "+comment+"\n")
+		} else {
+			w.tokens = append(w.tokens, "{"+comment+"}")
+		}
+		w.forceNumber = true
+	}
+}
+
+// advance moves to the next half move.
+func (w *moveTextWriter) advance() {
+	if w.black {
+		w.number++
+	}
+	w.black = !w.black
+}
+
+// wrapTokens joins tokens with spaces, wrapping lines at the given width.
+// Tokens are never broken, and a token ending with a line break ends the
+// line.
+func wrapTokens(tokens []string, maxWidth int) string {
+	var sb strings.Builder
+	lineLen := 0
+
+	for _, token := range tokens {
+		switch {
+		case lineLen == 0:
+		case lineLen+1+len(token) > maxWidth:
+			sb.WriteString("\n")
+			lineLen = 0
+		default:
+			sb.WriteString(" ")
+			lineLen++
+		}
+
+		sb.WriteString(token)
+		lineLen += len(token)
+		if strings.HasSuffix(token, "\n") {
+			lineLen = 0
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// FormatTag returns a tag pair in PGN format (e.g. `[Event "Open"]`).
+// It escapes backslashes and double quotes per PGN specification, and strips
+// carriage returns and newlines, which are not permitted inside tag values.
+func FormatTag(name, value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	escaped = strings.ReplaceAll(escaped, "\r", "")
+	escaped = strings.ReplaceAll(escaped, "\n", "")
+
+	return fmt.Sprintf("[%s \"%s\"]", name, escaped)
+}
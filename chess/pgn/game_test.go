@@ -0,0 +1,122 @@
+package pgn_test
+
+import (
+	"strings"
+	"testing"
+
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const annotatedGame = `[Event "Lesson"]
+[Annotator "Coach"]
+[Result "1-0"]
+
+{The Scholar's mate.} 1. e4 e5 2. Bc4 {Aiming at f7.} 2... Nc6 (2... Nf6 $1
+{is the best defense.} 3. d3 (3. Qf3 Bc5) 3... Bc5) 3. Qh5 Nf6 $4 (3... g6 {stops
+the mate.} 4. Qf3 Nf6) 4. Qxf7# 1-0
+`
+
+func TestGame(t *testing.T) {
+	t.Run("Tree of an annotated game", func(t *testing.T) {
+		game, err := chesspgn.ParseGame(annotatedGame)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"The Scholar's mate."}, game.Root.CommentsAfter)
+		assert.Equal(t, []string{"e4", "e5", "Bc4", "Nc6", "Qh5", "Nf6", "Qxf7#"}, game.Moves())
+		assert.Equal(t, chesspgn.ResultWhiteWins, game.Result)
+
+		bc4 := game.Root.Mainline()[2]
+		assert.Equal(t, "Bc4", bc4.Move)
+		assert.Equal(t, []string{"Aiming at f7."}, bc4.CommentsAfter)
+		require.Len(t, bc4.Children, 2)
+
+		nf6 := bc4.Children[1]
+		assert.Equal(t, "Nf6", nf6.Move)
+		assert.Equal(t, []int{1}, nf6.NAGs)
+		assert.Equal(t, []string{"is the best defense."}, nf6.CommentsAfter)
+		assert.Equal(t, []string{"d3", "Qf3"}, []string{nf6.Children[0].Move, nf6.Children[1].Move})
+		assert.Equal(t, []string{"e4", "e5", "Bc4", "Nf6", "Qf3", "Bc5"}, nf6.Children[1].Next().Moves())
+		assert.Equal(t, 6, nf6.Children[1].Next().Ply())
+
+		blunder := game.Root.Mainline()[5]
+		assert.Equal(t, []int{4}, blunder.NAGs)
+		assert.Equal(t, "stops\nthe mate.", blunder.Parent.Children[1].CommentsAfter[0])
+	})
+
+	t.Run("Serialization round-trips", func(t *testing.T) {
+		game, err := chesspgn.ParseGame(annotatedGame)
+		require.NoError(t, err)
+
+		again, err := chesspgn.ParseGame(game.String())
+		require.NoError(t, err)
+
+		assert.Equal(t, game.String(), again.String())
+		assert.Equal(t, game.Tags, again.Tags)
+		assert.Equal(t, game.Root.Mainline()[2].Children[1].Moves(), again.Root.Mainline()[2].Children[1].Moves())
+	})
+
+	t.Run("Move text", func(t *testing.T) {
+		game, err := chesspgn.ParseGame(`1. e4 {A comment} e5 2. Nf3!? (2. f4 exf4?) Nc6 *`)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1. e4 {A comment} 1... e5 2. Nf3 $5 (2. f4 exf4 $2) 2... Nc6 *", game.MoveText())
+	})
+
+	t.Run("Comments before a move", func(t *testing.T) {
+		game, err := chesspgn.ParseGame(`1. e4 e5 (1... {Or} c5 {Sicilian}) 2. {Develop} Nf3 *`)
+		require.NoError(t, err)
+
+		e5 := game.Root.Next().Next()
+		assert.Equal(t, []string{"Or"}, e5.Parent.Children[1].CommentsBefore)
+		assert.Equal(t, []string{"Sicilian"}, e5.Parent.Children[1].CommentsAfter)
+		assert.Equal(t, []string{"Develop"}, e5.Next().CommentsBefore)
+		assert.Equal(t, "1. e4 e5 (1... {Or} c5 {Sicilian}) 2. {Develop} Nf3 *", game.MoveText())
+	})
+
+	t.Run("Numbering from the FEN tag", func(t *testing.T) {
+		game, err := chesspgn.ParseGame(`[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"]
+
+12... Kd7 13. e4 *`)
+		require.NoError(t, err)
+
+		assert.Equal(t, "12... Kd7 13. e4 *", game.MoveText())
+	})
+
+	t.Run("Editing the tree", func(t *testing.T) {
+		game := chesspgn.NewGame()
+		game.Tags.Set("Event", "Edited")
+
+		e4 := game.Root.AddVariation("e4")
+		e5 := e4.AddVariation("e5")
+		c5 := e4.AddVariation("c5")
+		c5.CommentsAfter = []string{"Sharper."}
+		e5.AddVariation("Nf3")
+
+		assert.Equal(t, "1. e4 e5 (1... c5 {Sharper.}) 2. Nf3 *", game.MoveText())
+
+		e4.PromoteVariation(c5)
+		assert.Equal(t, "1. e4 c5 {Sharper.} (1... e5 2. Nf3) *", game.MoveText())
+
+		e4.RemoveVariation(e5)
+		game.Result = chesspgn.ResultDraw
+		assert.Equal(t, "[Event \"Edited\"]\n\n1. e4 c5 {Sharper.} 1/2-1/2\n", game.String())
+	})
+
+	t.Run("Long lines are wrapped without breaking comments", func(t *testing.T) {
+		game := chesspgn.NewGame()
+		node := game.Root
+		for range 10 {
+			node = node.AddVariation("Nf3").AddVariation("Nf6").AddVariation("Ng1").AddVariation("Ng8")
+		}
+		node.CommentsAfter = []string{"A long comment that would be broken if it were wrapped as the rest of the tokens."}
+
+		for _, line := range strings.Split(game.MoveText(), "\n") {
+			if line[0] != '{' {
+				assert.LessOrEqual(t, len(line), 80)
+			}
+		}
+		assert.Contains(t, game.MoveText(), node.CommentsAfter[0])
+	})
+}
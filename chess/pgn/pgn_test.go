@@ -23,13 +23,13 @@ func TestParse(t *testing.T) {
 		tags, moves, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Test", tags.Event)
-		assert.Equal(t, "Internet", tags.Site)
-		assert.Equal(t, "2026.03.17", tags.Date)
-		assert.Equal(t, "1", tags.Round)
-		assert.Equal(t, "Alice", tags.White)
-		assert.Equal(t, "Bob", tags.Black)
-		assert.Equal(t, "1-0", tags.Result)
+		assert.Equal(t, "Test", tags.Get("Event"))
+		assert.Equal(t, "Internet", tags.Get("Site"))
+		assert.Equal(t, "2026.03.17", tags.Get("Date"))
+		assert.Equal(t, "1", tags.Get("Round"))
+		assert.Equal(t, "Alice", tags.Get("White"))
+		assert.Equal(t, "Bob", tags.Get("Black"))
+		assert.Equal(t, "1-0", tags.Get("Result"))
 
 		expectedMoves := []string{"e2e4", "e7e5", "f1c4", "b8c6", "d1h5", "g8f6", "h5f7"}
 		assert.Equal(t, expectedMoves, moves)
@@ -44,13 +44,38 @@ func TestParse(t *testing.T) {
 		tags, moves, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
 
-		assert.Equal(t, "?", tags.Event)
-		assert.Equal(t, "*", tags.Result)
+		assert.Equal(t, "?", tags.Get("Event"))
+		assert.Equal(t, "*", tags.Get("Result"))
 
 		expectedMoves := []string{"e2e4", "e7e5", "g1f3", "b8c6"}
 		assert.Equal(t, expectedMoves, moves)
 	})
 
+	t.Run("Move numbers attached to moves", func(t *testing.T) {
+		pgn := `[Event "?"]
+
+1.e4 e5 2.Nf3 2...Nc6 *
+`
+		_, moves, err := chesspgn.Parse(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"e4", "e5", "Nf3", "Nc6"}, moves)
+	})
+
+	t.Run("SetUp and FEN tags", func(t *testing.T) {
+		pgn := `[Event "?"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e4 *
+`
+		tags, _, err := chesspgn.Parse(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1", tags.Get("SetUp"))
+		assert.Equal(t, "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", tags.Get("FEN"))
+	})
+
 	t.Run("PGN with semicolon comments", func(t *testing.T) {
 		pgn := `[Event "?"]
 [Result "*"]
@@ -79,8 +104,8 @@ func TestParse(t *testing.T) {
 		tags, moves, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
 
-		assert.Equal(t, "?", tags.Event)
-		assert.Equal(t, "*", tags.Result)
+		assert.Equal(t, "?", tags.Get("Event"))
+		assert.Equal(t, "*", tags.Get("Result"))
 		assert.Empty(t, moves)
 	})
 
@@ -89,6 +114,6 @@ func TestParse(t *testing.T) {
 		pgn := "[Event \"He said \\\"hello\\\"\"]\n[Result \"*\"]\n\n*\n"
 		tags, _, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
-		assert.Equal(t, "He said \"hello\"", tags.Event)
+		assert.Equal(t, "He said \"hello\"", tags.Get("Event"))
 	})
 }
@@ -0,0 +1,230 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// movePattern matches the symbols that look like a move in SAN or UCI
+// notation. It is used to tell the move text of a game without tags apart
+// from the text between games.
+var movePattern = regexp.MustCompile(
+	`^([KQRBN]?[a-h]?[1-8]?x?[a-h][1-8](=?[QRBNqrbn])?|[O0]-[O0](-[O0])?)[+#]?[!?]*$`,
+)
+
+// Reader reads games one at a time from a PGN stream, such as a database
+// export with thousands of games.
+//
+// It only keeps the game being read in memory. It tolerates byte order
+// marks, CRLF line breaks, `%` escape lines and any text between games.
+type Reader struct {
+	s *scanner
+	// pending is a token read ahead that belongs to the next game.
+	pending *token
+	// recovering is set after a syntax error, so the next call to Read
+	// skips the rest of the malformed game.
+	recovering bool
+	// inMoveText is set once the move text of the game being read starts.
+	inMoveText bool
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{s: newScanner(r)}
+}
+
+// Read returns the next game of the stream, or io.EOF when there are no more
+// games.
+//
+// Malformed games return a *SyntaxError with the line and column of the
+// problem. The reader then skips the rest of the game, up to its termination
+// marker or the next line starting with a tag after its move text, so the
+// following games can still be read.
+func (r *Reader) Read() (*Game, error) {
+	if r.recovering {
+		r.recovering = false
+		r.pending = nil
+		r.s.skipGame(r.inMoveText)
+	}
+
+	game, err := r.read()
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		r.recovering = true
+	}
+
+	return game, err
+}
+
+// read reads the next game of the stream.
+func (r *Reader) read() (*Game, error) {
+	game := NewGame()
+	game.Result = ""
+	b := treeBuilder{current: game.Root}
+	started := false
+	r.inMoveText = false
+
+	for {
+		if !started && !r.atGameStart() {
+			continue
+		}
+
+		t, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if !started && t.kind != tokenEOF {
+			started = true
+			game.Line = t.line
+		}
+
+		switch t.kind {
+		case tokenEOF:
+			if !started {
+				return nil, io.EOF
+			}
+			if len(b.stack) > 0 {
+				return nil, errorf(t.line, t.column, "unterminated variation")
+			}
+			return game.finish(), nil
+		case tokenTag:
+			if r.inMoveText {
+				// The previous game had no termination marker.
+				r.pending = &t
+				return game.finish(), nil
+			}
+			game.Tags.Set(t.name, t.value)
+		case tokenResult:
+			if len(b.stack) > 0 {
+				continue
+			}
+			game.Result = t.value
+			return game, nil
+		default:
+			r.inMoveText = true
+			if err := b.add(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// treeBuilder builds a game tree from the tokens of the move text.
+type treeBuilder struct {
+	// current is the last move read.
+	current *Node
+	// stack holds the moves the open variations are alternatives to.
+	stack []*Node
+	// before are the comments read before the next move.
+	before []string
+	// variationStart is true if no move has been read since the beginning
+	// of the current variation.
+	variationStart bool
+	// afterNumber is true if the last token read is a move number.
+	afterNumber bool
+}
+
+// add adds a token of the move text to the tree.
+func (b *treeBuilder) add(t token) error {
+	afterNumber := b.afterNumber
+	b.afterNumber = false
+
+	switch t.kind {
+	case tokenMoveNumber:
+		b.afterNumber = true
+	case tokenSymbol:
+		move, nag := splitSuffix(t.value)
+		node := b.current.AddVariation(move)
+		node.CommentsBefore = b.before
+		if nag != 0 {
+			node.NAGs = append(node.NAGs, nag)
+		}
+		b.current, b.before, b.variationStart = node, nil, false
+	case tokenComment:
+		switch {
+		case b.variationStart || afterNumber:
+			b.before = append(b.before, t.value)
+			b.afterNumber = afterNumber
+		default:
+			b.current.CommentsAfter = append(b.current.CommentsAfter, t.value)
+		}
+	case tokenNAG:
+		nag, err := strconv.Atoi(t.value)
+		if err != nil || nag > 255 {
+			return errorf(t.line, t.column, "invalid NAG $%s", t.value)
+		}
+		if b.current.Parent == nil || b.variationStart {
+			return errorf(t.line, t.column, "NAG before the first move")
+		}
+		b.current.NAGs = append(b.current.NAGs, nag)
+	case tokenOpenVariation:
+		if b.current.Parent == nil || b.variationStart {
+			return errorf(t.line, t.column, "variation before the first move")
+		}
+		// The variation is an alternative to the last move.
+		b.stack = append(b.stack, b.current)
+		b.current, b.variationStart = b.current.Parent, true
+	case tokenCloseVariation:
+		if len(b.stack) == 0 {
+			return errorf(t.line, t.column, "unexpected %q", ')')
+		}
+		b.current = b.stack[len(b.stack)-1]
+		b.stack = b.stack[:len(b.stack)-1]
+		b.before, b.variationStart = nil, false
+	}
+
+	return nil
+}
+
+// atGameStart reports whether the next token can start a game. Otherwise,
+// it discards the current line, as it is text between games.
+func (r *Reader) atGameStart() bool {
+	if r.pending != nil {
+		return true
+	}
+
+	r.s.skipSpaces()
+	ch, ok := r.s.read()
+	if !ok {
+		return true
+	}
+	r.s.unread()
+
+	switch {
+	case ch == '[' || ch == '{' || ch == ';' || ch == '*':
+		return true
+	case isSymbolStart(ch):
+		t, err := r.s.next()
+		if err == nil && (t.kind != tokenSymbol || movePattern.MatchString(t.value)) {
+			r.pending = &t
+			return true
+		}
+	}
+
+	r.s.skipLine()
+	return false
+}
+
+// next returns the next token, starting with the pending one.
+func (r *Reader) next() (token, error) {
+	if r.pending != nil {
+		t := *r.pending
+		r.pending = nil
+		return t, nil
+	}
+
+	return r.s.next()
+}
+
+// finish completes a game that has been read without its termination
+// marker.
+func (g *Game) finish() *Game {
+	if g.Result == "" {
+		g.Result = ResultOngoing
+	}
+
+	return g
+}
@@ -0,0 +1,253 @@
+package pgn_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads every game of a PGN, failing on any error.
+func readAll(t *testing.T, pgn string) []*chesspgn.Game {
+	t.Helper()
+
+	r := chesspgn.NewReader(strings.NewReader(pgn))
+	var games []*chesspgn.Game
+	for {
+		game, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return games
+		}
+		require.NoError(t, err)
+		games = append(games, game)
+	}
+}
+
+func TestReader(t *testing.T) {
+	t.Run("Several games", func(t *testing.T) {
+		pgn := `[Event "First"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+
+[Event "Second"]
+[Result "1/2-1/2"]
+
+1. d4 {A comment with [brackets]} d5 (1... Nf6 2. c4) 2. c4 $1 1/2-1/2
+`
+		games := readAll(t, pgn)
+		require.Len(t, games, 2)
+
+		assert.Equal(t, "First", games[0].Tags.Get("Event"))
+		assert.Equal(t, []string{"e4", "e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#"}, games[0].Moves())
+		assert.Equal(t, chesspgn.ResultWhiteWins, games[0].Result)
+		assert.Equal(t, 1, games[0].Line)
+
+		assert.Equal(t, "Second", games[1].Tags.Get("Event"))
+		assert.Equal(t, []string{"d4", "d5", "c4"}, games[1].Moves())
+		assert.Equal(t, chesspgn.ResultDraw, games[1].Result)
+		assert.Equal(t, 6, games[1].Line)
+	})
+
+	t.Run("Byte order mark, CRLF and escape lines", func(t *testing.T) {
+		pgn := "\uFEFF% Exported by some tool\r\n" +
+			"[Event \"Test\"]\r\n" +
+			"[Result \"0-1\"]\r\n" +
+			"\r\n" +
+			"1. f3 e5 2. g4\r\n" +
+			"% 2... Qh4# is the only mate\r\n" +
+			"Qh4# 0-1\r\n"
+
+		games := readAll(t, pgn)
+		require.Len(t, games, 1)
+
+		assert.Equal(t, chesspgn.PGNTags{
+			{Name: "Event", Value: "Test"},
+			{Name: "Result", Value: "0-1"},
+		}, games[0].Tags)
+		assert.Equal(t, []string{"f3", "e5", "g4", "Qh4#"}, games[0].Moves())
+		assert.Equal(t, 2, games[0].Line)
+	})
+
+	t.Run("Text between games", func(t *testing.T) {
+		pgn := `Downloaded from the club archive.
+Games of round 1:
+
+[Event "First"]
+
+1. e4 *
+
+Thanks for reading!
+The next game is a short one.
+
+1. d4 d5 *
+`
+		games := readAll(t, pgn)
+		require.Len(t, games, 2)
+
+		assert.Equal(t, []string{"e4"}, games[0].Moves())
+		assert.Equal(t, chesspgn.PGNTags{}, games[1].Tags)
+		assert.Equal(t, []string{"d4", "d5"}, games[1].Moves())
+		assert.Equal(t, 11, games[1].Line)
+	})
+
+	t.Run("Game without termination marker", func(t *testing.T) {
+		pgn := `[Event "First"]
+
+1. e4 e5
+
+[Event "Second"]
+
+1. d4 d5`
+
+		games := readAll(t, pgn)
+		require.Len(t, games, 2)
+
+		assert.Equal(t, []string{"e4", "e5"}, games[0].Moves())
+		assert.Equal(t, chesspgn.ResultOngoing, games[0].Result)
+		assert.Equal(t, "Second", games[1].Tags.Get("Event"))
+		assert.Equal(t, []string{"d4", "d5"}, games[1].Moves())
+	})
+
+	t.Run("Empty stream", func(t *testing.T) {
+		r := chesspgn.NewReader(strings.NewReader("\n\n"))
+
+		_, err := r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestReader_SyntaxError(t *testing.T) {
+	tests := []struct {
+		name   string
+		pgn    string
+		line   int
+		column int
+	}{
+		{
+			name:   "Unterminated tag value",
+			pgn:    "[Event \"Test\"]\n[Site \"Somewhere]\n\n1. e4 *\n",
+			line:   2,
+			column: 7,
+		},
+		{
+			name:   "Missing closing bracket",
+			pgn:    "[Event \"Test\"\n\n1. e4 *\n",
+			line:   1,
+			column: 1,
+		},
+		{
+			name:   "Unterminated comment",
+			pgn:    "[Event \"Test\"]\n\n1. e4 {Never closed e5 *\n",
+			line:   3,
+			column: 7,
+		},
+		{
+			name:   "Unbalanced variation",
+			pgn:    "[Event \"Test\"]\n\n1. e4 e5 ) 2. Nf3 *\n",
+			line:   3,
+			column: 10,
+		},
+		{
+			name:   "Unexpected character",
+			pgn:    "[Event \"Test\"]\n\n1. e4 <e5> *\n",
+			line:   3,
+			column: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := chesspgn.NewReader(strings.NewReader(tt.pgn)).Read()
+
+			var syntaxErr *chesspgn.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+			assert.Equal(t, tt.line, syntaxErr.Line)
+			assert.Equal(t, tt.column, syntaxErr.Column)
+		})
+	}
+
+	t.Run("Reading continues with the next game", func(t *testing.T) {
+		pgn := `[Event "Broken"]
+
+1. e4 e5 ) 2. Nf3 Nc6 *
+
+[Event "Fine"]
+
+1. d4 *
+`
+		r := chesspgn.NewReader(strings.NewReader(pgn))
+
+		_, err := r.Read()
+		var syntaxErr *chesspgn.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+		assert.Equal(t, "pgn: line 3, column 10: unexpected ')'", err.Error())
+
+		game, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, "Fine", game.Tags.Get("Event"))
+		assert.Equal(t, []string{"d4"}, game.Moves())
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Errors in the tags skip the whole game", func(t *testing.T) {
+		pgn := `[Event "A"
+[Site "B"]
+[Result "*"]
+
+1. e4 *
+
+[Event "C" oops]
+[Site "D"]
+
+1. c4 c5
+2. Nc3 1-0
+
+[Event "Fine"]
+
+1. d4 *
+`
+		r := chesspgn.NewReader(strings.NewReader(pgn))
+
+		for range 2 {
+			_, err := r.Read()
+			var syntaxErr *chesspgn.SyntaxError
+			require.ErrorAs(t, err, &syntaxErr)
+		}
+
+		game, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, "Fine", game.Tags.Get("Event"))
+		assert.Equal(t, []string{"d4"}, game.Moves())
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("Errors in a game without termination marker", func(t *testing.T) {
+		pgn := `[Event "Broken"]
+
+1. e4 ) e5
+2. Nf3
+
+[Event "Fine"]
+
+1. d4 *
+`
+		r := chesspgn.NewReader(strings.NewReader(pgn))
+
+		_, err := r.Read()
+		var syntaxErr *chesspgn.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr)
+
+		game, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, "Fine", game.Tags.Get("Event"))
+	})
+}
@@ -0,0 +1,431 @@
+package pgn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// byteOrderMark is the Unicode byte order mark some editors write at the
+// beginning of the files.
+const byteOrderMark = '\uFEFF'
+
+// SyntaxError is returned when a PGN is malformed. Line and Column point to
+// the character where the problem was found, starting at 1. Columns count
+// characters, not bytes.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("pgn: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// tokenKind is the kind of a PGN token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenTag
+	tokenSymbol
+	tokenMoveNumber
+	tokenComment
+	tokenNAG
+	tokenOpenVariation
+	tokenCloseVariation
+	tokenResult
+)
+
+// token is a lexical unit of a PGN.
+type token struct {
+	kind tokenKind
+	// name is the name of a tag.
+	name string
+	// value is the value of a tag, the text of a symbol or a comment, the
+	// number of a NAG or the game termination marker.
+	value        string
+	line, column int
+}
+
+// scanner splits a PGN stream into tokens, keeping track of the position
+// of each one. It reads the stream one character at a time, so its memory
+// usage does not depend on the size of the input.
+type scanner struct {
+	r *bufio.Reader
+
+	// line and column are the position of the last read character.
+	line, column int
+	// newline is set when the last read character is a line break.
+	newline bool
+	// prev holds the position before the last read character, restored by
+	// unread.
+	prevLine, prevColumn int
+	prevNewline          bool
+	// eof is set once the underlying reader is exhausted.
+	eof bool
+	// err is the first error returned by the underlying reader.
+	err error
+}
+
+// newScanner returns a scanner reading from r.
+func newScanner(r io.Reader) *scanner {
+	return &scanner{r: bufio.NewReader(r), line: 1}
+}
+
+// read returns the next character of the stream. It skips the `%` escape
+// lines and returns false at the end of the stream.
+func (s *scanner) read() (rune, bool) {
+	for {
+		ch, ok := s.readRaw()
+		if !ok {
+			return 0, false
+		}
+
+		if ch == '%' && s.column == 1 {
+			s.skipLine()
+			continue
+		}
+
+		if ch == byteOrderMark && s.column == 1 {
+			// The next character is the actual start of the line.
+			s.column = 0
+			continue
+		}
+
+		return ch, true
+	}
+}
+
+// readRaw returns the next character of the stream without skipping escape
+// lines.
+func (s *scanner) readRaw() (rune, bool) {
+	if s.eof {
+		return 0, false
+	}
+
+	ch, _, err := s.r.ReadRune()
+	if err != nil {
+		s.eof = true
+		if !errors.Is(err, io.EOF) {
+			s.err = err
+		}
+		return 0, false
+	}
+
+	s.prevLine, s.prevColumn, s.prevNewline = s.line, s.column, s.newline
+	if s.newline {
+		s.line++
+		s.column = 0
+	}
+	s.column++
+	s.newline = ch == '\n'
+
+	return ch, true
+}
+
+// unread puts back the last character read. It can only be called once
+// after each read.
+func (s *scanner) unread() {
+	_ = s.r.UnreadRune()
+	s.line, s.column, s.newline = s.prevLine, s.prevColumn, s.prevNewline
+}
+
+// skipLine discards the rest of the current line, including the line break.
+func (s *scanner) skipLine() {
+	for {
+		ch, ok := s.readRaw()
+		if !ok || ch == '\n' {
+			return
+		}
+	}
+}
+
+// errorf returns a syntax error at the given position.
+func errorf(line, column int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isSpace reports whether ch separates tokens.
+func isSpace(ch rune) bool {
+	return ch == byteOrderMark || unicode.IsSpace(ch)
+}
+
+// isSymbolStart reports whether ch can start a symbol token.
+func isSymbolStart(ch rune) bool {
+	return ch < unicode.MaxASCII && (unicode.IsLetter(ch) || unicode.IsDigit(ch))
+}
+
+// isSymbolContinuation reports whether ch can be part of a symbol token.
+// Besides the characters defined by the specification, it accepts the
+// suffix annotations ("!", "?") commonly attached to the moves.
+func isSymbolContinuation(ch rune) bool {
+	return isSymbolStart(ch) || strings.ContainsRune("_+#=:-/!?", ch)
+}
+
+// next returns the next token of the stream.
+func (s *scanner) next() (token, error) {
+	ch, ok := s.read()
+	for ok && isSpace(ch) {
+		ch, ok = s.read()
+	}
+
+	if !ok {
+		if s.err != nil {
+			return token{}, s.err
+		}
+		return token{kind: tokenEOF, line: s.line, column: s.column + 1}, nil
+	}
+
+	t := token{line: s.line, column: s.column}
+	switch {
+	case ch == '[':
+		return s.tag(t)
+	case ch == '{':
+		return s.braceComment(t)
+	case ch == ';':
+		t.kind = tokenComment
+		t.value = strings.TrimSpace(s.restOfLine())
+		return t, nil
+	case ch == '(':
+		t.kind = tokenOpenVariation
+		return t, nil
+	case ch == ')':
+		t.kind = tokenCloseVariation
+		return t, nil
+	case ch == '*':
+		t.kind = tokenResult
+		t.value = ResultOngoing
+		return t, nil
+	case ch == '$':
+		t.kind = tokenNAG
+		t.value = s.readWhile(unicode.IsDigit)
+		if t.value == "" {
+			return token{}, errorf(t.line, t.column, "NAG without number")
+		}
+		return t, nil
+	case ch == '.':
+		// Stray periods, like the ones of "1 ... e5", belong to the move
+		// number.
+		s.readWhile(func(r rune) bool { return r == '.' })
+		t.kind = tokenMoveNumber
+		return t, nil
+	case isSymbolStart(ch):
+		return s.symbol(t, ch), nil
+	}
+
+	return token{}, errorf(t.line, t.column, "unexpected character %q", ch)
+}
+
+// tag reads a tag pair. The opening bracket has already been read.
+func (s *scanner) tag(t token) (token, error) {
+	t.kind = tokenTag
+	s.skipSpaces()
+
+	t.name = s.readWhile(func(r rune) bool {
+		return r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	})
+	if t.name == "" {
+		return token{}, s.unexpected("tag name")
+	}
+
+	s.skipSpaces()
+	ch, ok := s.read()
+	if !ok || ch != '"' {
+		s.unreadIf(ok)
+		return token{}, s.unexpected("tag value")
+	}
+
+	value, err := s.quoted()
+	if err != nil {
+		return token{}, err
+	}
+	t.value = value
+
+	s.skipSpaces()
+	ch, ok = s.read()
+	if !ok || ch != ']' {
+		s.unreadIf(ok)
+		return token{}, errorf(t.line, t.column, "unterminated tag %s", t.name)
+	}
+
+	return t, nil
+}
+
+// quoted reads a string token. The opening quote has already been read.
+func (s *scanner) quoted() (string, error) {
+	line, column := s.line, s.column
+
+	var sb strings.Builder
+	for {
+		ch, ok := s.read()
+		if !ok || ch == '\n' {
+			return "", errorf(line, column, "unterminated string")
+		}
+
+		switch ch {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			escaped, ok := s.read()
+			if !ok {
+				return "", errorf(line, column, "unterminated string")
+			}
+			if escaped != '"' && escaped != '\\' {
+				sb.WriteRune(ch)
+			}
+			sb.WriteRune(escaped)
+		case '\r':
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+}
+
+// braceComment reads a brace comment. The opening brace has already been
+// read.
+func (s *scanner) braceComment(t token) (token, error) {
+	t.kind = tokenComment
+
+	var sb strings.Builder
+	for {
+		ch, ok := s.read()
+		if !ok {
+			return token{}, errorf(t.line, t.column, "unterminated comment")
+		}
+
+		if ch == '}' {
+			break
+		}
+		if ch != '\r' {
+			sb.WriteRune(ch)
+		}
+	}
+
+	t.value = strings.TrimSpace(sb.String())
+	return t, nil
+}
+
+// symbol reads a symbol token starting with ch. Integers followed by periods
+// are move numbers; the game termination markers are results.
+func (s *scanner) symbol(t token, ch rune) token {
+	var sb strings.Builder
+	sb.WriteRune(ch)
+	sb.WriteString(s.readWhile(isSymbolContinuation))
+	t.value = sb.String()
+
+	switch t.value {
+	case ResultWhiteWins, ResultBlackWins, ResultDraw:
+		t.kind = tokenResult
+		return t
+	}
+
+	t.kind = tokenSymbol
+	if strings.Trim(t.value, "0123456789") == "" {
+		if dots := s.readWhile(func(r rune) bool { return r == '.' }); dots != "" {
+			t.kind = tokenMoveNumber
+		}
+	}
+
+	return t
+}
+
+// restOfLine reads the characters up to the end of the line, discarding
+// the line break.
+func (s *scanner) restOfLine() string {
+	var sb strings.Builder
+	for {
+		ch, ok := s.readRaw()
+		if !ok || ch == '\n' {
+			return sb.String()
+		}
+		sb.WriteRune(ch)
+	}
+}
+
+// readWhile reads the characters that satisfy f.
+func (s *scanner) readWhile(f func(rune) bool) string {
+	var sb strings.Builder
+	for {
+		ch, ok := s.read()
+		if !ok {
+			return sb.String()
+		}
+		if !f(ch) {
+			s.unread()
+			return sb.String()
+		}
+		sb.WriteRune(ch)
+	}
+}
+
+// skipSpaces discards the characters that separate tokens.
+func (s *scanner) skipSpaces() {
+	s.readWhile(isSpace)
+}
+
+// skipGame discards the rest of a malformed game: up to the end of the line
+// with its termination marker, or up to the next line starting with a tag
+// after its move text. inMoveText is set if the move text of the game has
+// started; otherwise, the rest of the current line belongs to the tags.
+func (s *scanner) skipGame(inMoveText bool) {
+	if !s.atLineStart() && !inMoveText {
+		s.skipLine()
+	}
+
+	for {
+		ch, ok := s.read()
+		if !ok {
+			return
+		}
+		s.unread()
+
+		if ch == '[' && s.atLineStart() {
+			if inMoveText {
+				return
+			}
+			s.skipLine()
+			continue
+		}
+
+		line := s.restOfLine()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		inMoveText = true
+		for _, field := range strings.Fields(line) {
+			switch field {
+			case ResultWhiteWins, ResultBlackWins, ResultDraw, ResultOngoing:
+				return
+			}
+		}
+	}
+}
+
+// atLineStart reports whether the next character starts a line.
+func (s *scanner) atLineStart() bool {
+	return s.newline || s.column == 0
+}
+
+// unreadIf puts back the last character read if ok is true.
+func (s *scanner) unreadIf(ok bool) {
+	if ok {
+		s.unread()
+	}
+}
+
+// unexpected returns a syntax error at the next character of the stream.
+func (s *scanner) unexpected(expected string) *SyntaxError {
+	ch, ok := s.read()
+	if !ok {
+		return errorf(s.line, s.column+1, "unexpected end of input, expected %s", expected)
+	}
+
+	return errorf(s.line, s.column, "unexpected character %q, expected %s", ch, expected)
+}
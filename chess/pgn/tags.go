@@ -0,0 +1,212 @@
+package pgn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTagNotFound is returned by the typed accessors when the tag is not
+	// present.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrUnknownTagValue is returned by the typed accessors when the value of
+	// the tag is unknown ("?") or not applicable ("-").
+	ErrUnknownTagValue = errors.New("unknown tag value")
+)
+
+// SevenTagRoster are the names of the seven tags every PGN game must have,
+// in the order they are exported.
+var SevenTagRoster = [7]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Tag is a PGN tag pair.
+type Tag struct {
+	// Name is the name of the tag (e.g. "Event").
+	Name string
+	// Value is the value of the tag, without quotes or escapes.
+	Value string
+}
+
+// PGNTags is an ordered collection of PGN tag pairs.
+//
+// Tag names are case sensitive, as defined by the PGN specification.
+// A nil PGNTags is an empty collection.
+type PGNTags []Tag
+
+// Get returns the value of a tag, or an empty string if it is not present.
+func (t PGNTags) Get(name string) string {
+	value, _ := t.Lookup(name)
+	return value
+}
+
+// Lookup returns the value of a tag and whether it is present.
+func (t PGNTags) Lookup(name string) (string, bool) {
+	for _, tag := range t {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of a tag. If the tag is already present, its value is
+// replaced keeping its position. Otherwise, it is appended.
+func (t *PGNTags) Set(name, value string) {
+	for i, tag := range *t {
+		if tag.Name == name {
+			(*t)[i].Value = value
+			return
+		}
+	}
+
+	*t = append(*t, Tag{Name: name, Value: value})
+}
+
+// Delete removes a tag if it is present.
+func (t *PGNTags) Delete(name string) {
+	for i, tag := range *t {
+		if tag.Name == name {
+			*t = append((*t)[:i], (*t)[i+1:]...)
+			return
+		}
+	}
+}
+
+// WhiteElo returns the value of the WhiteElo tag.
+func (t PGNTags) WhiteElo() (int, error) {
+	return t.Int("WhiteElo")
+}
+
+// BlackElo returns the value of the BlackElo tag.
+func (t PGNTags) BlackElo() (int, error) {
+	return t.Int("BlackElo")
+}
+
+// Date returns the value of the Date tag.
+func (t PGNTags) Date() (Date, error) {
+	value, err := t.known("Date")
+	if err != nil {
+		return Date{}, err
+	}
+
+	return ParseDate(value)
+}
+
+// EventDate returns the value of the EventDate tag.
+func (t PGNTags) EventDate() (Date, error) {
+	value, err := t.known("EventDate")
+	if err != nil {
+		return Date{}, err
+	}
+
+	return ParseDate(value)
+}
+
+// TimeControl returns the value of the TimeControl tag.
+func (t PGNTags) TimeControl() (TimeControl, error) {
+	value, ok := t.Lookup("TimeControl")
+	if !ok {
+		return TimeControl{}, fmt.Errorf("%w: TimeControl", ErrTagNotFound)
+	}
+
+	return ParseTimeControl(value)
+}
+
+// Int returns the value of a tag as an integer.
+//
+// It returns ErrTagNotFound if the tag is not present, ErrUnknownTagValue if
+// its value is "?" or "-", or an error if it is not an integer.
+func (t PGNTags) Int(name string) (int, error) {
+	value, err := t.known(name)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s tag: %q is not an integer", name, value)
+	}
+
+	return n, nil
+}
+
+// known returns the value of a tag, or an error if the tag is not present or
+// its value is unknown. The value is always returned if the tag is present.
+func (t PGNTags) known(name string) (string, error) {
+	value, ok := t.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrTagNotFound, name)
+	}
+
+	if value == "?" || value == "-" || value == "" {
+		return value, fmt.Errorf("%w: %s", ErrUnknownTagValue, name)
+	}
+
+	return value, nil
+}
+
+// Date is a PGN date in the "YYYY.MM.DD" format. Unknown parts are written
+// as question marks (e.g. "2026.??.??") and are represented by zero values.
+type Date struct {
+	// Year is the year of the date, or 0 if it is unknown.
+	Year int
+	// Month is the month of the date, or 0 if it is unknown.
+	Month time.Month
+	// Day is the day of the date, or 0 if it is unknown.
+	Day int
+}
+
+// ParseDate parses a PGN date in the "YYYY.MM.DD" format.
+func ParseDate(s string) (Date, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || len(parts[0]) != 4 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return Date{}, fmt.Errorf("invalid date: %s", s)
+	}
+
+	var values [3]int
+	for i, part := range parts {
+		if strings.Trim(part, "?") == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Date{}, fmt.Errorf("invalid date: %s", s)
+		}
+
+		values[i] = n
+	}
+
+	d := Date{Year: values[0], Month: time.Month(values[1]), Day: values[2]}
+	if d.Month > time.December || d.Day > 31 {
+		return Date{}, fmt.Errorf("invalid date: %s", s)
+	}
+
+	return d, nil
+}
+
+// String returns the date in the "YYYY.MM.DD" format.
+func (d Date) String() string {
+	part := func(n, width int) string {
+		if n == 0 {
+			return strings.Repeat("?", width)
+		}
+
+		return fmt.Sprintf("%0*d", width, n)
+	}
+
+	return part(d.Year, 4) + "." + part(int(d.Month), 2) + "." + part(d.Day, 2)
+}
+
+// Time returns the date as a time.Time in UTC. It returns false if any of
+// the parts of the date is unknown.
+func (d Date) Time() (time.Time, bool) {
+	if d.Year == 0 || d.Month == 0 || d.Day == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC), true
+}
@@ -0,0 +1,202 @@
+package pgn_test
+
+import (
+	"testing"
+	"time"
+
+	chesspgn "github.com/RchrdHndrcks/gochess/v2/chess/pgn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGNTags(t *testing.T) {
+	t.Run("Every tag is kept in order", func(t *testing.T) {
+		pgn := `[Event "Open"]
+[Site "?"]
+[ECO "C20"]
+[WhiteElo "2100"]
+[Annotator "Someone"]
+[Result "*"]
+
+*
+`
+		tags, _, err := chesspgn.Parse(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, chesspgn.PGNTags{
+			{Name: "Event", Value: "Open"},
+			{Name: "Site", Value: "?"},
+			{Name: "ECO", Value: "C20"},
+			{Name: "WhiteElo", Value: "2100"},
+			{Name: "Annotator", Value: "Someone"},
+			{Name: "Result", Value: "*"},
+		}, tags)
+	})
+
+	t.Run("Get, Set and Delete", func(t *testing.T) {
+		var tags chesspgn.PGNTags
+
+		tags.Set("Event", "Open")
+		tags.Set("ECO", "C20")
+		tags.Set("Event", "Closed")
+
+		value, ok := tags.Lookup("Event")
+		assert.True(t, ok)
+		assert.Equal(t, "Closed", value)
+		assert.Equal(t, "C20", tags.Get("ECO"))
+		assert.Equal(t, "", tags.Get("Missing"))
+		assert.Equal(t, "Event", tags[0].Name)
+
+		tags.Delete("Event")
+		_, ok = tags.Lookup("Event")
+		assert.False(t, ok)
+		assert.Len(t, tags, 1)
+	})
+
+	t.Run("Elo", func(t *testing.T) {
+		tags := chesspgn.PGNTags{
+			{Name: "WhiteElo", Value: "2100"},
+			{Name: "BlackElo", Value: "-"},
+		}
+
+		elo, err := tags.WhiteElo()
+		require.NoError(t, err)
+		assert.Equal(t, 2100, elo)
+
+		_, err = tags.BlackElo()
+		assert.ErrorIs(t, err, chesspgn.ErrUnknownTagValue)
+
+		_, err = chesspgn.PGNTags{}.WhiteElo()
+		assert.ErrorIs(t, err, chesspgn.ErrTagNotFound)
+
+		_, err = chesspgn.PGNTags{{Name: "WhiteElo", Value: "high"}}.WhiteElo()
+		assert.Error(t, err)
+	})
+
+	t.Run("Dates", func(t *testing.T) {
+		tags := chesspgn.PGNTags{
+			{Name: "Date", Value: "2026.03.17"},
+			{Name: "EventDate", Value: "2026.??.??"},
+		}
+
+		date, err := tags.Date()
+		require.NoError(t, err)
+		assert.Equal(t, chesspgn.Date{Year: 2026, Month: time.March, Day: 17}, date)
+		tm, ok := date.Time()
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2026, time.March, 17, 0, 0, 0, 0, time.UTC), tm)
+
+		eventDate, err := tags.EventDate()
+		require.NoError(t, err)
+		assert.Equal(t, chesspgn.Date{Year: 2026}, eventDate)
+		assert.Equal(t, "2026.??.??", eventDate.String())
+		_, ok = eventDate.Time()
+		assert.False(t, ok)
+
+		_, err = chesspgn.PGNTags{{Name: "Date", Value: "?"}}.Date()
+		assert.ErrorIs(t, err, chesspgn.ErrUnknownTagValue)
+
+		for _, invalid := range []string{"2026-03-17", "2026.13.01", "26.03.17", "2026.ab.01"} {
+			_, err = chesspgn.ParseDate(invalid)
+			assert.Error(t, err, invalid)
+		}
+	})
+}
+
+func TestParseTimeControl(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected chesspgn.TimeControl
+	}{
+		{
+			value: "300",
+			expected: chesspgn.TimeControl{Periods: []chesspgn.TimeControlPeriod{
+				{Base: 300 * time.Second},
+			}},
+		},
+		{
+			value: "180+2",
+			expected: chesspgn.TimeControl{Periods: []chesspgn.TimeControlPeriod{
+				{Base: 180 * time.Second, Increment: 2 * time.Second},
+			}},
+		},
+		{
+			value: "40/5400+30:1800+30",
+			expected: chesspgn.TimeControl{Periods: []chesspgn.TimeControlPeriod{
+				{Moves: 40, Base: 5400 * time.Second, Increment: 30 * time.Second},
+				{Base: 1800 * time.Second, Increment: 30 * time.Second},
+			}},
+		},
+		{
+			value: "*60",
+			expected: chesspgn.TimeControl{Periods: []chesspgn.TimeControlPeriod{
+				{Base: 60 * time.Second, Sandclock: true},
+			}},
+		},
+		{
+			value:    "-",
+			expected: chesspgn.TimeControl{Untimed: true},
+		},
+		{
+			value: "0.5+0.25",
+			expected: chesspgn.TimeControl{Periods: []chesspgn.TimeControlPeriod{
+				{Base: 500 * time.Millisecond, Increment: 250 * time.Millisecond},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tc, err := chesspgn.ParseTimeControl(tt.value)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, tc)
+			assert.Equal(t, tt.value, tc.String())
+		})
+	}
+
+	t.Run("Base and increment", func(t *testing.T) {
+		tc, err := chesspgn.PGNTags{{Name: "TimeControl", Value: "180+2"}}.TimeControl()
+		require.NoError(t, err)
+
+		assert.Equal(t, 3*time.Minute, tc.Base())
+		assert.Equal(t, 2*time.Second, tc.Increment())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := chesspgn.ParseTimeControl("?")
+		assert.ErrorIs(t, err, chesspgn.ErrUnknownTagValue)
+
+		_, err = chesspgn.PGNTags{}.TimeControl()
+		assert.ErrorIs(t, err, chesspgn.ErrTagNotFound)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, invalid := range []string{"abc", "40/", "0/300", "300+", "*", "1e3", "-5"} {
+			_, err := chesspgn.ParseTimeControl(invalid)
+			assert.Error(t, err, invalid)
+		}
+	})
+}
+
+func TestClockComment(t *testing.T) {
+	tests := []struct {
+		d       time.Duration
+		comment string
+	}{
+		{90*time.Minute - 3*time.Second, "[%clk 1:29:57]"},
+		{9*time.Second + 500*time.Millisecond, "[%clk 0:00:09.5]"},
+		{0, "[%clk 0:00:00]"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.comment, chesspgn.ClockComment(tt.d))
+
+		d, ok := chesspgn.ParseClockComment("[%eval 0.3] " + tt.comment)
+		require.True(t, ok)
+		assert.Equal(t, tt.d, d)
+	}
+
+	_, ok := chesspgn.ParseClockComment("No clock here")
+	assert.False(t, ok)
+}
@@ -0,0 +1,176 @@
+package pgn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeControl is the time control of a game, as described by the PGN
+// TimeControl tag.
+type TimeControl struct {
+	// Periods are the periods of the time control, in the order they are
+	// played. The last period lasts until the end of the game.
+	Periods []TimeControlPeriod
+	// Untimed is true if the game has no time control ("-").
+	Untimed bool
+}
+
+// TimeControlPeriod is a period of a time control.
+type TimeControlPeriod struct {
+	// Moves is the number of moves to play in the period, or 0 if the period
+	// lasts until the end of the game.
+	Moves int
+	// Base is the time added to the clock at the start of the period.
+	Base time.Duration
+	// Increment is the time added to the clock after every move.
+	Increment time.Duration
+	// Sandclock is true if the period is played with an hourglass: the time
+	// used by a player is added to the clock of the opponent.
+	Sandclock bool
+}
+
+// ParseTimeControl parses the value of a PGN TimeControl tag.
+//
+// The value is a list of periods separated by colons. Every period is one of:
+//
+//	40/5400  40 moves in 5400 seconds
+//	300      300 seconds for the rest of the game
+//	180+2    180 seconds plus 2 seconds per move
+//	*60      a sandclock of 60 seconds
+//
+// Periods with a move count may also have an increment (e.g. "40/5400+30").
+// A "-" value is an untimed game. It returns an error wrapping
+// ErrUnknownTagValue if the value is "?".
+func ParseTimeControl(s string) (TimeControl, error) {
+	switch s {
+	case "?", "":
+		return TimeControl{}, fmt.Errorf("%w: TimeControl", ErrUnknownTagValue)
+	case "-":
+		return TimeControl{Untimed: true}, nil
+	}
+
+	var tc TimeControl
+	for _, field := range strings.Split(s, ":") {
+		p, err := parseTimeControlPeriod(field)
+		if err != nil {
+			return TimeControl{}, fmt.Errorf("invalid time control %q: %w", s, err)
+		}
+
+		tc.Periods = append(tc.Periods, p)
+	}
+
+	return tc, nil
+}
+
+// parseTimeControlPeriod parses a single period of a TimeControl tag.
+func parseTimeControlPeriod(s string) (TimeControlPeriod, error) {
+	var p TimeControlPeriod
+
+	if rest, ok := strings.CutPrefix(s, "*"); ok {
+		seconds, err := parseSeconds(rest)
+		if err != nil {
+			return p, err
+		}
+
+		p.Base, p.Sandclock = seconds, true
+		return p, nil
+	}
+
+	if moves, rest, ok := strings.Cut(s, "/"); ok {
+		n, err := strconv.Atoi(moves)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("invalid moves count: %s", moves)
+		}
+
+		p.Moves, s = n, rest
+	}
+
+	base, increment, hasIncrement := strings.Cut(s, "+")
+	seconds, err := parseSeconds(base)
+	if err != nil {
+		return p, err
+	}
+	p.Base = seconds
+
+	if hasIncrement {
+		if p.Increment, err = parseSeconds(increment); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+// parseSeconds parses a non-negative number of seconds, which may have a
+// fractional part.
+func parseSeconds(s string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil || seconds < 0 || strings.ContainsAny(s, "eEnN") {
+		return 0, fmt.Errorf("invalid seconds: %s", s)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Base returns the base time of the first period, or 0 if there are no periods.
+func (tc TimeControl) Base() time.Duration {
+	if len(tc.Periods) == 0 {
+		return 0
+	}
+
+	return tc.Periods[0].Base
+}
+
+// Increment returns the increment of the first period, or 0 if there are no
+// periods.
+func (tc TimeControl) Increment() time.Duration {
+	if len(tc.Periods) == 0 {
+		return 0
+	}
+
+	return tc.Periods[0].Increment
+}
+
+// String returns the time control in the PGN TimeControl tag format.
+func (tc TimeControl) String() string {
+	if tc.Untimed {
+		return "-"
+	}
+
+	if len(tc.Periods) == 0 {
+		return "?"
+	}
+
+	periods := make([]string, len(tc.Periods))
+	for i, p := range tc.Periods {
+		periods[i] = p.String()
+	}
+
+	return strings.Join(periods, ":")
+}
+
+// String returns the period in the PGN TimeControl tag format.
+func (p TimeControlPeriod) String() string {
+	if p.Sandclock {
+		return "*" + formatSeconds(p.Base)
+	}
+
+	var s string
+	if p.Moves > 0 {
+		s = strconv.Itoa(p.Moves) + "/"
+	}
+
+	s += formatSeconds(p.Base)
+	if p.Increment > 0 {
+		s += "+" + formatSeconds(p.Increment)
+	}
+
+	return s
+}
+
+// formatSeconds formats a duration as a number of seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -26,7 +26,7 @@ func TestPGN(t *testing.T) {
 		assert.Contains(t, pgn, `[Result "*"]`)
 		parsedTags, parsedMoves, parseErr := chesspgn.Parse(pgn)
 		require.NoError(t, parseErr)
-		assert.Equal(t, chesspgn.ResultOngoing, parsedTags.Result)
+		assert.Equal(t, chesspgn.ResultOngoing, parsedTags.Get("Result"))
 		assert.Empty(t, parsedMoves)
 	})
 
@@ -35,12 +35,12 @@ func TestPGN(t *testing.T) {
 		require.NoError(t, err)
 
 		tags := chesspgn.PGNTags{
-			Event: "Test Tournament",
-			Site:  "Internet",
-			Date:  "2026.03.17",
-			Round: "1",
-			White: "Player1",
-			Black: "Player2",
+			{Name: "Event", Value: "Test Tournament"},
+			{Name: "Site", Value: "Internet"},
+			{Name: "Date", Value: "2026.03.17"},
+			{Name: "Round", Value: "1"},
+			{Name: "White", Value: "Player1"},
+			{Name: "Black", Value: "Player2"},
 		}
 		pgn := c.PGN(tags)
 
@@ -68,8 +68,8 @@ func TestPGN(t *testing.T) {
 
 		parsedTags, parsedMoves, parseErr := chesspgn.Parse(pgn)
 		require.NoError(t, parseErr)
-		assert.Equal(t, chesspgn.ResultWhiteWins, parsedTags.Result)
-		assert.Equal(t, []string{"e2e4", "e7e5", "f1c4", "b8c6", "d1h5", "g8f6", "h5f7"}, parsedMoves)
+		assert.Equal(t, chesspgn.ResultWhiteWins, parsedTags.Get("Result"))
+		assert.Equal(t, []string{"e4", "e5", "Bc4", "Nc6", "Qh5", "Nf6", "Qxf7#"}, parsedMoves)
 	})
 
 	t.Run("Line wrapping", func(t *testing.T) {
@@ -88,8 +88,8 @@ func TestPGN(t *testing.T) {
 		require.NoError(t, err)
 
 		tags := chesspgn.PGNTags{
-			Event: "line1\nline2",
-			Site:  "cr\r\ntest",
+			{Name: "Event", Value: "line1\nline2"},
+			{Name: "Site", Value: "cr\r\ntest"},
 		}
 		pgn := c.PGN(tags)
 
@@ -104,8 +104,8 @@ func TestPGN(t *testing.T) {
 		require.NoError(t, err)
 
 		tags := chesspgn.PGNTags{
-			Event: "He said \"hello\"",
-			Site:  "path\\to\\file",
+			{Name: "Event", Value: "He said \"hello\""},
+			{Name: "Site", Value: "path\\to\\file"},
 		}
 		pgn := c.PGN(tags)
 
@@ -115,8 +115,8 @@ func TestPGN(t *testing.T) {
 		// Roundtrip: parse back and verify unescaped values.
 		parsedTags, _, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
-		assert.Equal(t, "He said \"hello\"", parsedTags.Event)
-		assert.Equal(t, "path\\to\\file", parsedTags.Site)
+		assert.Equal(t, "He said \"hello\"", parsedTags.Get("Event"))
+		assert.Equal(t, "path\\to\\file", parsedTags.Get("Site"))
 	})
 }
 
@@ -131,19 +131,291 @@ func TestPGNRoundtrip(t *testing.T) {
 		}
 
 		tags := chesspgn.PGNTags{
-			Event: "Roundtrip Test",
-			White: "W",
-			Black: "B",
+			{Name: "Event", Value: "Roundtrip Test"},
+			{Name: "White", Value: "W"},
+			{Name: "Black", Value: "B"},
 		}
 		pgn := c.PGN(tags)
 
 		parsedTags, parsedMoves, err := chesspgn.Parse(pgn)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Roundtrip Test", parsedTags.Event)
-		assert.Equal(t, "W", parsedTags.White)
-		assert.Equal(t, "B", parsedTags.Black)
-		assert.Equal(t, "*", parsedTags.Result)
+		assert.Equal(t, "Roundtrip Test", parsedTags.Get("Event"))
+		assert.Equal(t, "W", parsedTags.Get("White"))
+		assert.Equal(t, "B", parsedTags.Get("Black"))
+		assert.Equal(t, "*", parsedTags.Get("Result"))
+		assert.Equal(t, []string{"e4", "e5", "Nf3", "Nc6", "Bc4", "Bc5"}, parsedMoves)
+
+		replayed, _, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+		assert.Equal(t, c.FEN(), replayed.FEN())
+	})
+
+	t.Run("UCI move text", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		playedMoves := []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1c4", "f8c5"}
+		for _, m := range playedMoves {
+			require.NoError(t, c.MakeMove(m))
+		}
+
+		pgn := c.PGN(chesspgn.PGNTags{}, chess.WithUCIMoveText())
+
+		_, parsedMoves, err := chesspgn.Parse(pgn)
+		require.NoError(t, err)
 		assert.Equal(t, playedMoves, parsedMoves)
 	})
 }
+
+func TestPGN_MoveText(t *testing.T) {
+	t.Run("Check suffixes", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+		for _, m := range []string{"e2e4", "f7f6", "d2d4", "e8f7", "d1h5", "g7g6"} {
+			require.NoError(t, c.MakeMove(m))
+		}
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+
+		assert.Contains(t, pgn, "1. e4 f6 2. d4 Kf7 3. Qh5+ g6 *")
+	})
+
+	t.Run("Black to move in a set up position", func(t *testing.T) {
+		fen := "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"
+		c, err := chess.New(chess.WithFEN(fen))
+		require.NoError(t, err)
+		for _, m := range []string{"e8d7", "e2e4", "d7e6"} {
+			require.NoError(t, c.MakeMove(m))
+		}
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+
+		assert.Contains(t, pgn, `[SetUp "1"]`)
+		assert.Contains(t, pgn, `[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"]`)
+		assert.Contains(t, pgn, "12... Kd7 13. e4 Ke6 *")
+
+		replayed, _, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+		assert.Equal(t, c.FEN(), replayed.FEN())
+	})
+
+	t.Run("Set up position without moves", func(t *testing.T) {
+		fen := "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"
+		c, err := chess.New(chess.WithFEN(fen))
+		require.NoError(t, err)
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+
+		assert.Contains(t, pgn, `[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]`)
+	})
+
+	t.Run("Initial position has no SetUp tag", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+		require.NoError(t, c.MakeMove("e2e4"))
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+
+		assert.NotContains(t, pgn, "SetUp")
+		assert.NotContains(t, pgn, "FEN")
+	})
+
+	t.Run("Chess960 castling", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960(), chess.WithFEN("rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1"))
+		require.NoError(t, err)
+		require.NoError(t, c.MakeMove("b1h1"))
+		require.NoError(t, c.MakeMove("b8a8"))
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+
+		assert.Contains(t, pgn, "1. O-O O-O-O *")
+	})
+}
+
+func TestFromPGN(t *testing.T) {
+	t.Run("SAN move text", func(t *testing.T) {
+		pgn := `[Event "Scholar's mate"]
+[White "W"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6?? 4. Qxf7# 1-0
+`
+		c, tags, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Scholar's mate", tags.Get("Event"))
+		assert.Equal(t, "r1bqkb1r/pppp1Qpp/2n2n2/4p3/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 0 4", c.FEN())
+		assert.True(t, c.IsCheckmate())
+		assert.Equal(t, chesspgn.ResultWhiteWins, c.Outcome().Result)
+	})
+
+	t.Run("UCI move text", func(t *testing.T) {
+		original, err := chess.New()
+		require.NoError(t, err)
+		for _, m := range []string{"e2e4", "e7e5", "g1f3", "b8c6"} {
+			require.NoError(t, original.MakeMove(m))
+		}
+
+		c, _, err := chess.FromPGN(original.PGN(chesspgn.PGNTags{}, chess.WithUCIMoveText()))
+		require.NoError(t, err)
+
+		assert.Equal(t, original.FEN(), c.FEN())
+	})
+
+	t.Run("SetUp and FEN tags", func(t *testing.T) {
+		pgn := `[Event "?"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 12"]
+
+12... Kd7 13. e4 *
+`
+		c, _, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, "8/3k4/8/8/4P3/8/8/4K3 b - e3 0 13", c.FEN())
+	})
+
+	t.Run("Options are applied", func(t *testing.T) {
+		pgn := `[Event "?"]
+[SetUp "1"]
+[FEN "rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1"]
+
+1. O-O O-O-O *
+`
+		c, _, err := chess.FromPGN(pgn, chess.WithChess960())
+		require.NoError(t, err)
+
+		assert.Equal(t, "2kr3r/8/8/8/8/8/8/R4RK1 w - - 2 2", c.FEN())
+	})
+
+	t.Run("Illegal move reports ply and token", func(t *testing.T) {
+		pgn := `[Event "?"]
+
+1. e4 e5 2. Nf3 Ke6 *
+`
+		c, _, err := chess.FromPGN(pgn)
+		require.Error(t, err)
+		assert.Nil(t, c)
+
+		var moveErr *chess.PGNMoveError
+		require.ErrorAs(t, err, &moveErr)
+		assert.Equal(t, 4, moveErr.Ply)
+		assert.Equal(t, "Ke6", moveErr.Token)
+		assert.Contains(t, err.Error(), `ply 4: invalid move "Ke6"`)
+	})
+
+	t.Run("SetUp without FEN", func(t *testing.T) {
+		_, _, err := chess.FromPGN("[SetUp \"1\"]\n\n1. e4 *\n")
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid FEN", func(t *testing.T) {
+		_, _, err := chess.FromPGN("[SetUp \"1\"]\n[FEN \"invalid\"]\n\n1. e4 *\n")
+		assert.Error(t, err)
+	})
+
+	t.Run("FEN with a short row", func(t *testing.T) {
+		_, _, err := chess.FromPGN("[SetUp \"1\"]\n[FEN \"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKB w KQkq - 0 1\"]\n\n1. e4 *\n")
+		assert.Error(t, err)
+	})
+}
+
+func TestFromPGNGame(t *testing.T) {
+	game, err := chesspgn.ParseGame(`[Event "?"]
+
+1. e4 e5 (1... c5 2. Nf3 {Open Sicilian} (2. c3 d5) 2... d6) 2. Nf3 *
+`)
+	require.NoError(t, err)
+
+	t.Run("Mainline", func(t *testing.T) {
+		c, err := chess.FromPGNGame(game, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", c.FEN())
+	})
+
+	t.Run("Nested variation", func(t *testing.T) {
+		// Arrange
+		c5 := game.Root.Next().Children[1]
+		d5 := c5.Children[1].Next()
+		require.Equal(t, "d5", d5.Move)
+
+		// Act
+		c, err := chess.FromPGNGame(game, d5)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "rnbqkbnr/pp2pppp/8/2pp4/4P3/2P5/PP1P1PPP/RNBQKBNR w KQkq d6 0 3", c.FEN())
+	})
+
+	t.Run("Illegal move in a variation", func(t *testing.T) {
+		game := chesspgn.NewGame()
+		e4 := game.Root.AddVariation("e4")
+		e4.AddVariation("e5")
+		bad := e4.AddVariation("Ke7")
+
+		_, err := chess.FromPGNGame(game, bad)
+
+		var moveErr *chess.PGNMoveError
+		require.ErrorAs(t, err, &moveErr)
+		assert.Equal(t, 2, moveErr.Ply)
+		assert.Equal(t, "Ke7", moveErr.Token)
+	})
+}
+
+func TestPGN_Tags(t *testing.T) {
+	t.Run("Every tag round-trips", func(t *testing.T) {
+		pgn := `[Event "Open"]
+[Site "Club"]
+[Date "2026.03.17"]
+[Round "3"]
+[White "W"]
+[Black "B"]
+[Result "1-0"]
+[ECO "C20"]
+[WhiteElo "2100"]
+[BlackElo "1950"]
+[TimeControl "180+2"]
+[Termination "normal"]
+
+1. e4 e5 1-0
+`
+		c, tags, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+
+		assert.Equal(t, pgn, c.PGN(tags))
+	})
+
+	t.Run("Game tags replace the provided ones", func(t *testing.T) {
+		c, err := chess.New()
+		require.NoError(t, err)
+
+		pgn := c.PGN(chesspgn.PGNTags{
+			{Name: "FEN", Value: "4k3/8/8/8/8/8/8/4K3 w - - 0 1"},
+			{Name: "SetUp", Value: "1"},
+			{Name: "Annotator", Value: "A"},
+		})
+
+		assert.NotContains(t, pgn, "FEN")
+		assert.NotContains(t, pgn, "SetUp")
+		assert.Contains(t, pgn, "[Result \"*\"]\n[Annotator \"A\"]\n")
+	})
+
+	t.Run("Chess960 variant round-trips", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960Position(0))
+		require.NoError(t, err)
+		require.NoError(t, c.MakeMove("e2e4"))
+
+		pgn := c.PGN(chesspgn.PGNTags{})
+		assert.Contains(t, pgn, `[Variant "Chess960"]`)
+		assert.Contains(t, pgn, `[FEN "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1"]`)
+
+		replayed, tags, err := chess.FromPGN(pgn)
+		require.NoError(t, err)
+		assert.True(t, replayed.IsChess960())
+		assert.Equal(t, c.FEN(), replayed.FEN())
+		assert.Equal(t, pgn, replayed.PGN(tags))
+	})
+}
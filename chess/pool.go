@@ -0,0 +1,213 @@
+package chess
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type (
+	// WorkerPool is a pool of long-lived goroutines that computes Perft and
+	// PerftDivide in parallel.
+	//
+	// A pool can be shared by several games with the WithWorkerPool option,
+	// even from different goroutines. Every worker keeps its own copy of the
+	// last position it worked on and, when the next one comes from the same
+	// game, it only unmakes and makes the moves that differ instead of
+	// cloning the game again.
+	//
+	// The workers run until the pool is closed or its context is done. The
+	// games using a closed pool compute perft sequentially.
+	//
+	// The pool is only used by perft: the legal moves of MakeMove,
+	// UnmakeMove and LoadPosition are generated sequentially, without
+	// goroutines or copies of the game.
+	WorkerPool struct {
+		workers int
+		tasks   chan perftTask
+		// done is closed when the pool is closed.
+		done      chan struct{}
+		closeOnce sync.Once
+		// stop releases the context of the pool.
+		stop func() bool
+		wg   sync.WaitGroup
+	}
+
+	// perftTask is the perft of a root move of a game.
+	perftTask struct {
+		game    *Chess
+		move    Move
+		depth   int
+		results chan<- perftResult
+	}
+
+	// perftResult is the number of leaf nodes below a root move.
+	perftResult struct {
+		move  Move
+		nodes uint64
+	}
+)
+
+// parallelMinNodes is the estimated number of leaf nodes below which perft
+// runs sequentially, since smaller trees take less time than handing the
+// moves to the workers.
+const parallelMinNodes = 1 << 15
+
+// NewWorkerPool starts a pool with the given number of workers. If the
+// number of workers is less or equal to 0, the pool uses one worker per
+// CPU available to the Go scheduler.
+//
+// The workers stop when the context is done or Close is called.
+func NewWorkerPool(ctx context.Context, workers int) *WorkerPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	p := &WorkerPool{
+		workers: workers,
+		tasks:   make(chan perftTask),
+		done:    make(chan struct{}),
+	}
+
+	p.stop = context.AfterFunc(ctx, p.shutdown)
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+
+	return p
+}
+
+// Workers returns the number of workers of the pool.
+func (p *WorkerPool) Workers() int {
+	return p.workers
+}
+
+// Close stops the workers and waits for them to finish their current
+// tasks. Closing a closed pool does nothing.
+func (p *WorkerPool) Close() {
+	p.stop()
+	p.shutdown()
+	p.wg.Wait()
+}
+
+// shutdown tells the workers to stop.
+func (p *WorkerPool) shutdown() {
+	p.closeOnce.Do(func() { close(p.done) })
+}
+
+// work runs the tasks of the pool until it is closed.
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+
+	// game is the copy of the last position the worker worked on.
+	var game *Chess
+	for {
+		select {
+		case <-p.done:
+			return
+		case t := <-p.tasks:
+			if game == nil || !game.syncTo(t.game) {
+				cloned := t.game.clone()
+				game = &cloned
+			}
+
+			t.results <- perftResult{move: t.move, nodes: game.perftMove(t.move, t.depth)}
+		}
+	}
+}
+
+// perftDivide adds the number of leaf nodes at the given depth below each
+// legal move of a game to divide, handing the moves to the workers.
+//
+// The game must not change until it returns. If the pool is closed, the
+// moves not taken by a worker are computed sequentially once the workers
+// are done with the game.
+func (p *WorkerPool) perftDivide(c *Chess, depth int, divide map[string]uint64) {
+	results := make(chan perftResult, len(c.moves))
+
+	var pending []Move
+	for _, m := range c.moves {
+		select {
+		case p.tasks <- perftTask{game: c, move: m, depth: depth, results: results}:
+		case <-p.done:
+			pending = append(pending, m)
+		}
+	}
+
+	for range len(c.moves) - len(pending) {
+		r := <-results
+		divide[r.move.UCI()] = r.nodes
+	}
+
+	for _, m := range pending {
+		divide[m.UCI()] = c.perftMove(m, depth)
+	}
+}
+
+// perftWorkers returns the number of workers to compute the perft of the
+// current position at the given depth, or 1 to compute it sequentially.
+//
+// The size of the tree is estimated from the number of legal moves of the
+// current position.
+func (c *Chess) perftWorkers(depth int) int {
+	if _, ok := c.board.(Cloner); !ok {
+		return 1
+	}
+
+	nodes := 1
+	for range depth {
+		nodes *= len(c.moves)
+		if nodes >= parallelMinNodes {
+			break
+		}
+	}
+
+	if nodes < parallelMinNodes {
+		return 1
+	}
+
+	workers := c.config.Parallelism
+	if c.config.WorkerPool != nil {
+		workers = c.config.WorkerPool.workers
+	}
+
+	return min(workers, len(c.moves))
+}
+
+// syncTo brings a copy made with clone to the position of src, unmaking the
+// moves that src doesn't share with it and making the new ones.
+//
+// It returns false if the positions can't be synchronized, e.g. because
+// they come from different games or a position was loaded in src.
+func (c *Chess) syncTo(src *Chess) bool {
+	if c.config.Chess960 != src.config.Chess960 || c.castleKingFiles != src.castleKingFiles {
+		return false
+	}
+
+	common := 0
+	for common < len(c.history) && common < len(src.history) &&
+		c.history[common].move == src.history[common].move &&
+		c.history[common].hash == src.history[common].hash {
+		common++
+	}
+
+	for len(c.history) > common {
+		c.unmakeMove()
+	}
+
+	want := src.hash
+	if common < len(src.history) {
+		want = src.history[common].hash
+	}
+
+	if c.hash != want {
+		return false
+	}
+
+	for _, ctx := range src.history[common:] {
+		c.makeMove(ctx.move)
+	}
+
+	return c.hash == src.hash
+}
@@ -0,0 +1,121 @@
+package chess_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWorkerPool(t *testing.T) {
+	pool := chess.NewWorkerPool(context.Background(), 3)
+	defer pool.Close()
+	assert.Equal(t, 3, pool.Workers())
+
+	def := chess.NewWorkerPool(context.Background(), 0)
+	defer def.Close()
+	assert.Positive(t, def.Workers())
+}
+
+func TestWorkerPool(t *testing.T) {
+	const kiwipete = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+	t.Run("Perft", func(t *testing.T) {
+		pool := chess.NewWorkerPool(context.Background(), 4)
+		defer pool.Close()
+
+		c, err := chess.New(chess.WithFEN(kiwipete), chess.WithWorkerPool(pool))
+		require.NoError(t, err)
+		fen := c.FEN()
+
+		assert.Equal(t, uint64(97862), c.Perft(3))
+		assert.Equal(t, fen, c.FEN())
+
+		sequential, err := chess.New(chess.WithFEN(kiwipete), chess.WithParallelism(1))
+		require.NoError(t, err)
+		assert.Equal(t, sequential.PerftDivide(3), c.PerftDivide(3))
+	})
+
+	t.Run("Moves between calls", func(t *testing.T) {
+		pool := chess.NewWorkerPool(context.Background(), 4)
+		defer pool.Close()
+
+		c, err := chess.New(chess.WithWorkerPool(pool))
+		require.NoError(t, err)
+		sequential, err := chess.New(chess.WithParallelism(1))
+		require.NoError(t, err)
+
+		// check compares the perft of the game with the sequential one after
+		// every change, so the workers catch up with the moves in between.
+		check := func(change func(c *chess.Chess)) {
+			t.Helper()
+
+			change(c)
+			change(sequential)
+			require.Equal(t, sequential.FEN(), c.FEN())
+			assert.Equal(t, sequential.Perft(4), c.Perft(4), c.FEN())
+		}
+
+		check(func(*chess.Chess) {})
+		check(func(c *chess.Chess) { require.NoError(t, c.MakeMove("e2e4")) })
+		check(func(c *chess.Chess) { require.NoError(t, c.MakeMove("d7d5")) })
+		check(func(c *chess.Chess) { c.UnmakeMove() })
+		check(func(c *chess.Chess) { require.NoError(t, c.MakeMove("c7c5")) })
+		check(func(c *chess.Chess) { require.NoError(t, c.LoadPosition(kiwipete)) })
+		check(func(c *chess.Chess) { require.NoError(t, c.MakeMove("e1g1")) })
+	})
+
+	t.Run("Shared by games", func(t *testing.T) {
+		pool := chess.NewWorkerPool(context.Background(), 4)
+		defer pool.Close()
+
+		tests := []struct {
+			fen   string
+			depth int
+			want  uint64
+		}{
+			{kiwipete, 3, 97862},
+			{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 4, 197281},
+			{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", 3, 89890},
+		}
+
+		var wg sync.WaitGroup
+		for _, tt := range tests {
+			c, err := chess.New(chess.WithFEN(tt.fen), chess.WithWorkerPool(pool))
+			require.NoError(t, err)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, tt.want, c.Perft(tt.depth), tt.fen)
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		pool := chess.NewWorkerPool(context.Background(), 4)
+		c, err := chess.New(chess.WithFEN(kiwipete), chess.WithWorkerPool(pool))
+		require.NoError(t, err)
+
+		pool.Close()
+		pool.Close()
+		assert.Equal(t, uint64(97862), c.Perft(3))
+	})
+
+	t.Run("Context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		pool := chess.NewWorkerPool(ctx, 4)
+		defer pool.Close()
+
+		c, err := chess.New(chess.WithFEN(kiwipete), chess.WithWorkerPool(pool))
+		require.NoError(t, err)
+
+		cancel()
+		assert.Equal(t, uint64(97862), c.Perft(3))
+	})
+}
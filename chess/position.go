@@ -0,0 +1,240 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+// Position is an immutable chess position: the pieces on the board, the
+// color to move, the castling rights, the en passant square and the move
+// counters, without the history of the game.
+//
+// Positions are comparable values, so they can be compared with == and used
+// as map keys, and they are safe to share between goroutines. Two positions
+// are equal when all their FEN fields are equal. Use Hash to compare
+// positions regardless of the move counters and the en passant squares that
+// can't be captured.
+//
+// The zero value is not a valid position. Use NewPosition or Chess.Position
+// to get one.
+type Position struct {
+	// board are the pieces of the squares, indexed by gochess.BitIndex.
+	board [64]gochess.Piece
+	turn  gochess.Piece
+	// castles are the castles that are available.
+	castles castlingRights
+	// castleKingFiles are the files where the kings must be to castle,
+	// indexed by color index. The file of a color without castles is
+	// always 4, so equal positions have equal files.
+	castleKingFiles [2]int
+	// enPassantSquare is the en passant square, or an empty string if there
+	// is none.
+	enPassantSquare string
+	halfMoves       int
+	movesCount      uint64
+	chess960        bool
+	hash            uint64
+}
+
+// NewPosition returns the position described by a FEN string.
+//
+// The options configure how the FEN string is read, e.g. WithChess960 reads
+// the castling field with the Chess960 rules. It returns an error if the
+// FEN string is invalid.
+func NewPosition(FEN string, opts ...Option) (Position, error) {
+	c, err := New(slices.Concat(opts, []Option{WithFEN(FEN)})...)
+	if err != nil {
+		return Position{}, err
+	}
+
+	return c.Position(), nil
+}
+
+// Position returns the current position of the game.
+func (c *Chess) Position() Position {
+	p := Position{
+		turn:            c.turn,
+		castles:         c.castles,
+		castleKingFiles: c.castleKingFiles,
+		enPassantSquare: c.enPassantSquare,
+		halfMoves:       c.halfMoves,
+		movesCount:      c.movesCount,
+		chess960:        c.config.Chess960,
+		hash:            c.hash,
+	}
+
+	for i := range p.board {
+		p.board[i] = c.bits.PieceAt(i)
+	}
+
+	for ci, rights := range p.castles {
+		if rights == [2]int{noCastle, noCastle} {
+			p.castleKingFiles[ci] = 4
+		}
+	}
+
+	return p
+}
+
+// game returns a game in the position, without history and without the
+// legal moves generated.
+func (p Position) game() *Chess {
+	c := &Chess{
+		turn:            p.turn,
+		castles:         p.castles,
+		castleKingFiles: p.castleKingFiles,
+		enPassantSquare: p.enPassantSquare,
+		halfMoves:       p.halfMoves,
+		movesCount:      p.movesCount,
+		config:          config{Parallelism: 1, Chess960: p.chess960},
+	}
+
+	b, _ := gochess.NewBitBoard()
+	for i, piece := range p.board {
+		if piece != gochess.Empty {
+			b.Set(i, piece)
+		}
+	}
+
+	if king := b.Pieces(gochess.White | gochess.King); king != 0 {
+		whitePos := gochess.BitCoordinate(bits.TrailingZeros64(king))
+		c.whiteKingPosition = &whitePos
+	}
+
+	if king := b.Pieces(gochess.Black | gochess.King); king != 0 {
+		blackPos := gochess.BitCoordinate(bits.TrailingZeros64(king))
+		c.blackKingPosition = &blackPos
+	}
+
+	c.setBoard(newBitBoardAdapter(b))
+	return c
+}
+
+// valid returns true if the position was built by NewPosition or
+// Chess.Position, which is not the case of the zero value.
+func (p Position) valid() bool {
+	return p.turn != gochess.Empty
+}
+
+// FEN returns the FEN string of the position, or an empty string for the
+// zero value.
+func (p Position) FEN() string {
+	if !p.valid() {
+		return ""
+	}
+
+	return p.game().calculateFEN()
+}
+
+// String returns the FEN string of the position.
+func (p Position) String() string {
+	return p.FEN()
+}
+
+// Turn returns the color to move.
+func (p Position) Turn() gochess.Piece {
+	return p.turn
+}
+
+// Hash returns the Zobrist hash of the position. It matches Chess.Hash and
+// the keys of the Polyglot opening books.
+func (p Position) Hash() uint64 {
+	return p.hash
+}
+
+// PieceAt returns the piece in a square, or gochess.Empty if the square is
+// empty or outside the board.
+func (p Position) PieceAt(square gochess.Coordinate) gochess.Piece {
+	if !onBoard(square.X, square.Y) {
+		return gochess.Empty
+	}
+
+	return p.board[gochess.BitIndex(square)]
+}
+
+// Moves returns the legal moves of the position.
+func (p Position) Moves() []Move {
+	if !p.valid() {
+		return nil
+	}
+
+	return p.game().legalMoves(nil, allMoves)
+}
+
+// ParseMove returns the legal Move described by a UCI string (e.g. "e2e4").
+//
+// It returns an error if the string is not valid UCI or the move is not
+// legal in the position.
+func (p Position) ParseMove(uci string) (Move, error) {
+	origin, target, promotion, err := parseUCI(uci)
+	if err != nil || !p.valid() {
+		return Move{}, fmt.Errorf("move is not legal: %s", uci)
+	}
+
+	c := p.game()
+	c.moves = c.legalMoves(nil, allMoves)
+	m, ok := c.findMove(origin, target, promotion)
+	if !ok {
+		return Move{}, fmt.Errorf("move is not legal: %s", uci)
+	}
+
+	return m, nil
+}
+
+// Play returns the position after a move. The position itself is not
+// modified.
+//
+// Only the From, To and Promotion fields of the move are used to look for
+// it in the legal moves, as Chess.PlayMove does. It panics if the move is
+// not legal, so moves that don't come from Moves or ParseMove must be
+// checked first.
+func (p Position) Play(m Move) Position {
+	if !p.valid() {
+		panic(fmt.Sprintf("chess: move %s played in the zero position", m.UCI()))
+	}
+
+	c := p.game()
+	c.moves = c.legalMoves(nil, allMoves)
+	legal, ok := c.findMove(m.From, m.To, m.Promotion)
+	if !ok {
+		panic(fmt.Sprintf("chess: illegal move %s in position %s", m.UCI(), c.calculateFEN()))
+	}
+
+	c.makeMove(legal)
+	return c.Position()
+}
+
+// IsCheck returns if the color to move is in check.
+func (p Position) IsCheck() bool {
+	return p.valid() && p.game().isCheck()
+}
+
+// IsCheckmate returns if the color to move is in checkmate.
+func (p Position) IsCheckmate() bool {
+	return p.IsCheck() && len(p.Moves()) == 0
+}
+
+// IsStalemate returns if the color to move is in stalemate.
+func (p Position) IsStalemate() bool {
+	return p.valid() && !p.IsCheck() && len(p.Moves()) == 0
+}
+
+// Game returns a new game starting from the position.
+//
+// The options are applied before the position is loaded. The Chess960
+// rules are enabled if the position follows them.
+func (p Position) Game(opts ...Option) (*Chess, error) {
+	if !p.valid() {
+		return nil, errors.New("invalid position: zero value")
+	}
+
+	if p.chess960 {
+		opts = slices.Concat(opts, []Option{WithChess960()})
+	}
+
+	return New(slices.Concat(opts, []Option{WithFEN(p.FEN())})...)
+}
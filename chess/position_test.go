@@ -0,0 +1,155 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPosition(t *testing.T) {
+	t.Run("FEN", func(t *testing.T) {
+		fens := []string{
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 12 40",
+		}
+
+		for _, fen := range fens {
+			p, err := chess.NewPosition(fen)
+			require.NoError(t, err)
+			assert.Equal(t, fen, p.FEN())
+			assert.Equal(t, fen, p.String())
+		}
+	})
+
+	t.Run("Invalid FEN", func(t *testing.T) {
+		_, err := chess.NewPosition("8/8/8/8/8/8/8/8 w - - 0 1")
+		assert.Error(t, err)
+	})
+
+	t.Run("Chess960", func(t *testing.T) {
+		fen := "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9"
+		p, err := chess.NewPosition(fen, chess.WithChess960())
+		require.NoError(t, err)
+
+		c, err := p.Game()
+		require.NoError(t, err)
+		assert.True(t, c.IsChess960())
+		assert.Equal(t, p, c.Position())
+	})
+
+	t.Run("Zero value", func(t *testing.T) {
+		var p chess.Position
+		assert.Empty(t, p.FEN())
+		assert.Empty(t, p.Moves())
+		assert.False(t, p.IsCheck())
+		assert.False(t, p.IsStalemate())
+		assert.Panics(t, func() { p.Play(chess.Move{}) })
+
+		_, err := p.ParseMove("e2e4")
+		assert.Error(t, err)
+		_, err = p.Game()
+		assert.Error(t, err)
+	})
+}
+
+func TestPosition(t *testing.T) {
+	start, err := chess.NewPosition("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+
+	t.Run("Accessors", func(t *testing.T) {
+		assert.Equal(t, gochess.White, start.Turn())
+		assert.Equal(t, gochess.White|gochess.King, start.PieceAt(gochess.Coor(4, 7)))
+		assert.Equal(t, gochess.Empty, start.PieceAt(gochess.Coor(4, 4)))
+		assert.Equal(t, gochess.Empty, start.PieceAt(gochess.Coor(8, 0)))
+		assert.Len(t, start.Moves(), 20)
+
+		c, err := chess.New()
+		require.NoError(t, err)
+		assert.Equal(t, c.Hash(), start.Hash())
+		assert.Equal(t, start, c.Position())
+	})
+
+	t.Run("Play", func(t *testing.T) {
+		m, err := start.ParseMove("e2e4")
+		require.NoError(t, err)
+
+		next := start.Play(m)
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", next.FEN())
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", start.FEN())
+
+		// Only the squares and the promotion of the move are used.
+		assert.Equal(t, next, start.Play(chess.Move{From: m.From, To: m.To}))
+
+		c, err := chess.New()
+		require.NoError(t, err)
+		require.NoError(t, c.MakeMove("e2e4"))
+		assert.Equal(t, c.Position(), next)
+		assert.Equal(t, c.Hash(), next.Hash())
+
+		_, err = start.ParseMove("e2e5")
+		assert.Error(t, err)
+		assert.Panics(t, func() { start.Play(chess.Move{From: m.From, To: gochess.Coor(4, 3)}) })
+	})
+
+	t.Run("Map key", func(t *testing.T) {
+		// The same position reached by two move orders.
+		play := func(moves ...string) chess.Position {
+			p := start
+			for _, uci := range moves {
+				m, err := p.ParseMove(uci)
+				require.NoError(t, err)
+				p = p.Play(m)
+			}
+			return p
+		}
+
+		seen := map[chess.Position]int{}
+		seen[play("g1f3", "g8f6", "b1c3", "b8c6")]++
+		seen[play("b1c3", "b8c6", "g1f3", "g8f6")]++
+		seen[play("g1f3", "g8f6", "b1c3", "b8c6", "f3g1", "f6g8", "g1f3", "g8f6")]++
+
+		assert.Len(t, seen, 2)
+		assert.Equal(t, 2, seen[play("b1c3", "b8c6", "g1f3", "g8f6")])
+	})
+
+	t.Run("Checks", func(t *testing.T) {
+		mate, err := chess.NewPosition("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+		require.NoError(t, err)
+		assert.True(t, mate.IsCheck())
+		assert.True(t, mate.IsCheckmate())
+		assert.False(t, mate.IsStalemate())
+
+		stalemate, err := chess.NewPosition("k7/8/1QK5/8/8/8/8/8 b - - 0 1")
+		require.NoError(t, err)
+		assert.False(t, stalemate.IsCheck())
+		assert.False(t, stalemate.IsCheckmate())
+		assert.True(t, stalemate.IsStalemate())
+	})
+
+	t.Run("Lost castling rights", func(t *testing.T) {
+		p, err := chess.NewPosition("4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1")
+		require.NoError(t, err)
+
+		for _, uci := range []string{"e1d1", "e8d8", "d1e1", "d8e8"} {
+			m, err := p.ParseMove(uci)
+			require.NoError(t, err)
+			p = p.Play(m)
+		}
+
+		want, err := chess.NewPosition("4k3/8/8/8/8/8/8/R3K2R w - - 4 3")
+		require.NoError(t, err)
+		assert.Equal(t, want, p)
+	})
+
+	t.Run("Game", func(t *testing.T) {
+		c, err := start.Game()
+		require.NoError(t, err)
+		assert.Equal(t, start.FEN(), c.FEN())
+		assert.Empty(t, c.History())
+	})
+}
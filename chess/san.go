@@ -17,71 +17,75 @@ func (c *Chess) SAN(uciMove string) (string, error) {
 		return "", fmt.Errorf("invalid UCI move: %s", uciMove)
 	}
 
-	// Verify the move is legal.
-	found := false
-	for _, m := range c.AvailableMoves() {
-		if m == uciMove {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return "", fmt.Errorf("move is not legal: %s", uciMove)
+	m, err := c.ParseMove(uciMove)
+	if err != nil {
+		return "", err
 	}
 
-	origin, _ := AlgebraicToCoordinate(uciMove[:2])
-	target, _ := AlgebraicToCoordinate(uciMove[2:4])
+	return c.san(m), nil
+}
 
-	// Use FEN to get piece info, as the board may be temporarily modified
-	// by legal move calculations in sequential mode.
-	piece := pieceFromFEN(c.FEN(), origin)
-	pieceType := gochess.PieceType(piece)
+// MoveSAN converts a Move to Standard Algebraic Notation (like "Nf3").
+//
+// Only the From, To and Promotion fields of the move are used to look for
+// it in the legal moves. It returns an error if the move is not legal.
+func (c *Chess) MoveSAN(m Move) (string, error) {
+	legal, ok := c.findMove(m.From, m.To, m.Promotion)
+	if !ok {
+		return "", fmt.Errorf("move is not legal: %s", m.UCI())
+	}
 
-	var san string
+	return c.san(legal), nil
+}
 
-	// Handle castling.
-	isCastle := pieceType == gochess.King && castlesMoves[uciMove] == c.turn
-	if isCastle {
-		if target.X > origin.X {
-			san = "O-O"
-		} else {
-			san = "O-O-O"
+// san builds the SAN string of a legal move.
+func (c *Chess) san(m Move) string {
+	if m.IsCastle() {
+		if m.To.X > m.From.X {
+			return "O-O" + checkSuffix(c, m)
 		}
-		return san + checkSuffix(c, uciMove), nil
+		return "O-O-O" + checkSuffix(c, m)
 	}
 
-	// Determine capture.
-	targetPiece := pieceFromFEN(c.FEN(), target)
-	isCapture := targetPiece != gochess.Empty
-
-	// En passant is also a capture.
-	if pieceType == gochess.Pawn && c.enPassantSquare != "" && uciMove[2:4] == c.enPassantSquare {
-		isCapture = true
-	}
+	pieceType := gochess.PieceType(m.Piece)
 
+	var san string
 	if pieceType == gochess.Pawn {
-		san = pawnSAN(origin, target, isCapture, uciMove)
+		san = pawnSAN(m)
 	} else {
 		// Piece letter — reuse gochess.PieceNames (uppercase = white-colored pieces).
 		san = gochess.PieceNames[pieceType|gochess.White]
 
 		// Disambiguation.
-		san += disambiguation(c, piece, origin, target)
+		san += disambiguation(c, m)
 
-		if isCapture {
+		if m.IsCapture() {
 			san += "x"
 		}
 
-		san += CoordinateToAlgebraic(target)
+		san += CoordinateToAlgebraic(m.To)
 	}
 
-	return san + checkSuffix(c, uciMove), nil
+	return san + checkSuffix(c, m)
 }
 
 // FromSAN converts a SAN string (like "Nf3") to a UCI move (like "g1f3").
 //
 // The SAN must correspond to a legal move in the current position.
 func (c *Chess) FromSAN(san string) (string, error) {
+	m, err := c.ParseSAN(san)
+	if err != nil {
+		return "", err
+	}
+
+	return m.UCI(), nil
+}
+
+// ParseSAN returns the legal Move described by a SAN string (like "Nf3").
+//
+// The SAN must correspond to a legal move in the current position.
+func (c *Chess) ParseSAN(san string) (Move, error) {
+	c.refresh()
 	san = strings.TrimRight(san, "+#")
 
 	// Handle castling.
@@ -93,7 +97,7 @@ func (c *Chess) FromSAN(san string) (string, error) {
 	}
 
 	if len(san) == 0 {
-		return "", fmt.Errorf("invalid SAN: empty string")
+		return Move{}, fmt.Errorf("invalid SAN: empty string")
 	}
 
 	if unicode.IsUpper(rune(san[0])) && san[0] != 'O' {
@@ -104,60 +108,40 @@ func (c *Chess) FromSAN(san string) (string, error) {
 }
 
 // pawnSAN builds the SAN string for a pawn move.
-func pawnSAN(origin, target gochess.Coordinate, isCapture bool, uciMove string) string {
+func pawnSAN(m Move) string {
 	var san string
 
-	if isCapture {
-		san += string(rune('a' + origin.X))
+	if m.IsCapture() {
+		san += string(rune('a' + m.From.X))
 		san += "x"
 	}
 
-	san += CoordinateToAlgebraic(target)
+	san += CoordinateToAlgebraic(m.To)
 
 	// Promotion.
-	if len(uciMove) == 5 {
-		promoChar := strings.ToUpper(uciMove[4:5])
-		san += "=" + promoChar
+	if m.IsPromotion() {
+		san += "=" + gochess.PieceNames[gochess.PieceType(m.Promotion)|gochess.White]
 	}
 
 	return san
 }
 
 // disambiguation returns the disambiguation string needed for a piece move.
-func disambiguation(c *Chess, piece gochess.Piece, origin, target gochess.Coordinate) string {
-	pieceType := gochess.PieceType(piece)
-	targetAlg := CoordinateToAlgebraic(target)
-	fen := c.FEN()
-
+func disambiguation(c *Chess, m Move) string {
 	sameFile := false
 	sameRank := false
 	ambiguous := false
 
-	for _, m := range c.AvailableMoves() {
-		if len(m) < 4 {
-			continue
-		}
-
-		mOrigin, _ := AlgebraicToCoordinate(m[:2])
-		if mOrigin == origin {
-			continue
-		}
-
-		if m[2:4] != targetAlg {
-			continue
-		}
-
-		mPiece := pieceFromFEN(fen, mOrigin)
-		mPieceType := gochess.PieceType(mPiece)
-		if mPieceType != pieceType {
+	for _, other := range c.moves {
+		if other.From == m.From || other.To != m.To || other.Piece != m.Piece {
 			continue
 		}
 
 		ambiguous = true
-		if mOrigin.X == origin.X {
+		if other.From.X == m.From.X {
 			sameFile = true
 		}
-		if mOrigin.Y == origin.Y {
+		if other.From.Y == m.From.Y {
 			sameRank = true
 		}
 	}
@@ -167,65 +151,60 @@ func disambiguation(c *Chess, piece gochess.Piece, origin, target gochess.Coordi
 	}
 
 	if sameFile && sameRank {
-		return CoordinateToAlgebraic(origin)
+		return CoordinateToAlgebraic(m.From)
 	}
 
 	if sameFile {
-		return fmt.Sprintf("%d", 8-origin.Y)
+		return fmt.Sprintf("%d", 8-m.From.Y)
 	}
 
-	return string(rune('a' + origin.X))
+	return string(rune('a' + m.From.X))
 }
 
 // checkSuffix determines if a move results in check or checkmate.
-func checkSuffix(c *Chess, uciMove string) string {
-	// Create a fresh game from the current FEN to avoid any shared board
-	// pointer issue with clone().
-	cloned, err := New(WithFEN(c.actualFEN), WithParallelism(1))
-	if err != nil || cloned == nil {
+//
+// The move is made and unmade on the game itself, so the Chess960 rules
+// and the custom boards are respected.
+func checkSuffix(c *Chess, m Move) string {
+	c.makeMove(m)
+	defer c.unmakeMove()
+
+	if !c.isCheck() {
 		return ""
 	}
-	_ = cloned.MakeMove(uciMove)
 
-	if cloned.IsCheckmate() {
+	if len(c.legalMoves(make([]Move, 0, 64), allMoves)) == 0 {
 		return "#"
 	}
 
-	if cloned.IsCheck() {
-		return "+"
-	}
-
-	return ""
+	return "+"
 }
 
-// findCastleMove finds the castling UCI move from available moves.
-func findCastleMove(c *Chess, kingside bool) (string, error) {
-	for _, m := range c.AvailableMoves() {
-		if !c.isCastleMove(m) {
+// findCastleMove finds the castling move from available moves.
+func findCastleMove(c *Chess, kingside bool) (Move, error) {
+	for _, m := range c.moves {
+		if !m.IsCastle() {
 			continue
 		}
 
-		origin, _ := AlgebraicToCoordinate(m[:2])
-		target, _ := AlgebraicToCoordinate(m[2:4])
-
-		if kingside && target.X > origin.X {
+		if kingside && m.To.X > m.From.X {
 			return m, nil
 		}
-		if !kingside && target.X < origin.X {
+		if !kingside && m.To.X < m.From.X {
 			return m, nil
 		}
 	}
 
-	return "", fmt.Errorf("castling move not available")
+	return Move{}, fmt.Errorf("castling move not available")
 }
 
 // parsePieceMoveSAN parses SAN for non-pawn pieces (e.g., "Nf3", "Raxe1", "R1e1").
-func parsePieceMoveSAN(c *Chess, san string) (string, error) {
+func parsePieceMoveSAN(c *Chess, san string) (Move, error) {
 	pieceChar := san[0]
 	// Reuse gochess.Pieces; strip color to get bare piece type.
 	p, ok := gochess.Pieces[string(pieceChar)]
 	if !ok || p == gochess.Empty {
-		return "", fmt.Errorf("invalid piece in SAN: %c", pieceChar)
+		return Move{}, fmt.Errorf("invalid piece in SAN: %c", pieceChar)
 	}
 	pieceType := gochess.PieceType(p)
 
@@ -233,10 +212,13 @@ func parsePieceMoveSAN(c *Chess, san string) (string, error) {
 	rest = strings.ReplaceAll(rest, "x", "")
 
 	if len(rest) < 2 {
-		return "", fmt.Errorf("invalid SAN: %s", san)
+		return Move{}, fmt.Errorf("invalid SAN: %s", san)
 	}
 
-	targetAlg := rest[len(rest)-2:]
+	target, err := AlgebraicToCoordinate(rest[len(rest)-2:])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid SAN: %s", san)
+	}
 	disambig := rest[:len(rest)-2]
 
 	var fileDisambig int = -1
@@ -250,46 +232,37 @@ func parsePieceMoveSAN(c *Chess, san string) (string, error) {
 		}
 	}
 
-	fen := c.FEN()
-	for _, m := range c.AvailableMoves() {
-		if m[2:4] != targetAlg {
+	for _, m := range c.moves {
+		if m.To != target || gochess.PieceType(m.Piece) != pieceType {
 			continue
 		}
 
-		mOrigin, _ := AlgebraicToCoordinate(m[:2])
-		mPiece := pieceFromFEN(fen, mOrigin)
-		mPieceType := gochess.PieceType(mPiece)
-
-		if mPieceType != pieceType {
+		if fileDisambig >= 0 && m.From.X != fileDisambig {
 			continue
 		}
 
-		if fileDisambig >= 0 && mOrigin.X != fileDisambig {
-			continue
-		}
-
-		if rankDisambig >= 0 && mOrigin.Y != rankDisambig {
+		if rankDisambig >= 0 && m.From.Y != rankDisambig {
 			continue
 		}
 
 		return m, nil
 	}
 
-	return "", fmt.Errorf("no matching move found for SAN: %s", san)
+	return Move{}, fmt.Errorf("no matching move found for SAN: %s", san)
 }
 
 // parsePawnMoveSAN parses SAN for pawn moves (e.g., "e4", "exd5", "e8=Q").
-func parsePawnMoveSAN(c *Chess, san string) (string, error) {
+func parsePawnMoveSAN(c *Chess, san string) (Move, error) {
 	var fileDisambig int = -1
-	var promotion string
+	promotion := gochess.Empty
 	isCaptureSAN := false
 
 	// Check for promotion.
 	if idx := strings.Index(san, "="); idx >= 0 {
 		if idx+1 >= len(san) {
-			return "", fmt.Errorf("invalid SAN: promotion piece missing after '=': %s", san)
+			return Move{}, fmt.Errorf("invalid SAN: promotion piece missing after '=': %s", san)
 		}
-		promotion = strings.ToLower(san[idx+1 : idx+2])
+		promotion = gochess.PiecesWithoutColor[san[idx+1:idx+2]]
 		san = san[:idx]
 	}
 
@@ -297,7 +270,7 @@ func parsePawnMoveSAN(c *Chess, san string) (string, error) {
 	parts := strings.Split(san, "x")
 	if len(parts) == 2 {
 		if len(parts[0]) == 0 {
-			return "", fmt.Errorf("invalid capture SAN: missing file before 'x': %s", san)
+			return Move{}, fmt.Errorf("invalid capture SAN: missing file before 'x': %s", san)
 		}
 		fileDisambig = int(parts[0][0] - 'a')
 		isCaptureSAN = true
@@ -306,44 +279,34 @@ func parsePawnMoveSAN(c *Chess, san string) (string, error) {
 
 	targetAlg := san
 	if len(targetAlg) != 2 {
-		return "", fmt.Errorf("invalid pawn move SAN: %s", san)
+		return Move{}, fmt.Errorf("invalid pawn move SAN: %s", san)
 	}
 
-	fen := c.FEN()
-	for _, m := range c.AvailableMoves() {
-		if m[2:4] != targetAlg {
+	for _, m := range c.moves {
+		if CoordinateToAlgebraic(m.To) != targetAlg {
 			continue
 		}
 
-		mOrigin, _ := AlgebraicToCoordinate(m[:2])
-		mPiece := pieceFromFEN(fen, mOrigin)
-		mPieceType := gochess.PieceType(mPiece)
-
-		if mPieceType != gochess.Pawn {
+		if gochess.PieceType(m.Piece) != gochess.Pawn {
 			continue
 		}
 
-		if fileDisambig >= 0 && mOrigin.X != fileDisambig {
+		if fileDisambig >= 0 && m.From.X != fileDisambig {
 			continue
 		}
 
 		// A non-capture SAN (no 'x') must not match diagonal moves (captures/en passant).
-		mTarget, _ := AlgebraicToCoordinate(m[2:4])
-		if !isCaptureSAN && mOrigin.X != mTarget.X {
+		if !isCaptureSAN && m.From.X != m.To.X {
 			continue
 		}
 
 		// Check promotion match.
-		if promotion != "" {
-			if len(m) != 5 || m[4:5] != promotion {
-				continue
-			}
-		} else if len(m) == 5 {
+		if gochess.PieceType(m.Promotion) != promotion {
 			continue
 		}
 
 		return m, nil
 	}
 
-	return "", fmt.Errorf("no matching move found for pawn SAN: %s", targetAlg)
+	return Move{}, fmt.Errorf("no matching move found for pawn SAN: %s", targetAlg)
 }
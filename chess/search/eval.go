@@ -0,0 +1,191 @@
+package search
+
+import (
+	"math/bits"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// Evaluator evaluates the positions reached by a search.
+type Evaluator interface {
+	// Evaluate returns the score of the position of the game in centipawns,
+	// from the point of view of the side to move: positive scores are good
+	// for the side to move.
+	//
+	// It is only called for positions that are not checkmate or stalemate,
+	// and it must not modify the game.
+	Evaluate(game *chess.Chess) int
+}
+
+// EvaluatorFunc is an adapter to use ordinary functions as evaluators.
+type EvaluatorFunc func(game *chess.Chess) int
+
+// Evaluate returns f(game).
+func (f EvaluatorFunc) Evaluate(game *chess.Chess) int {
+	return f(game)
+}
+
+// DefaultEvaluator evaluates the positions by their material and
+// piece-square tables.
+//
+// It uses the values and tables of Tomasz Michniewski's simplified
+// evaluation function. The king table is interpolated between the middle
+// game and the endgame by the non-pawn material left on the board.
+type DefaultEvaluator struct{}
+
+// pieceValues are the values of the pieces in centipawns, indexed by piece
+// type.
+var pieceValues = [...]int{
+	gochess.Pawn:   100,
+	gochess.Knight: 320,
+	gochess.Bishop: 330,
+	gochess.Rook:   500,
+	gochess.Queen:  900,
+	gochess.King:   0,
+}
+
+// phaseWeights are the weights of the pieces in the game phase, indexed by
+// piece type. The phase is maxPhase with all the pieces on the board and 0
+// when only kings and pawns are left.
+var phaseWeights = [...]int{
+	gochess.Knight: 1,
+	gochess.Bishop: 1,
+	gochess.Rook:   2,
+	gochess.Queen:  4,
+	gochess.King:   0,
+}
+
+// maxPhase is the phase of the starting position.
+const maxPhase = 24
+
+// The piece-square tables are written from the point of view of white, with
+// the eighth rank first, so the square with bit index i is the entry i^56
+// for white pieces and the entry i for black pieces.
+var (
+	pawnTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+
+	knightTable = [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	}
+
+	bishopTable = [64]int{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	}
+
+	rookTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	}
+
+	queenTable = [64]int{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	}
+
+	kingMiddleGameTable = [64]int{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	}
+
+	kingEndgameTable = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+)
+
+// pieceTables are the piece-square tables of the pieces other than the
+// king, indexed by piece type.
+var pieceTables = [...]*[64]int{
+	gochess.Pawn:   &pawnTable,
+	gochess.Knight: &knightTable,
+	gochess.Bishop: &bishopTable,
+	gochess.Rook:   &rookTable,
+	gochess.Queen:  &queenTable,
+}
+
+// Evaluate returns the material and piece-square table balance of the
+// position from the point of view of the side to move.
+func (DefaultEvaluator) Evaluate(game *chess.Chess) int {
+	var score, phase int
+	for _, color := range []gochess.Piece{gochess.White, gochess.Black} {
+		sign, flip := 1, 56
+		if color == gochess.Black {
+			sign, flip = -1, 0
+		}
+
+		for pt := gochess.Pawn; pt <= gochess.Queen; pt++ {
+			table := pieceTables[pt]
+			for set := game.Pieces(color | pt); set != 0; set &= set - 1 {
+				score += sign * (pieceValues[pt] + table[bits.TrailingZeros64(set)^flip])
+				phase += phaseWeights[pt]
+			}
+		}
+	}
+
+	phase = min(phase, maxPhase)
+	for _, color := range []gochess.Piece{gochess.White, gochess.Black} {
+		sign, flip := 1, 56
+		if color == gochess.Black {
+			sign, flip = -1, 0
+		}
+
+		for set := game.Pieces(color | gochess.King); set != 0; set &= set - 1 {
+			i := bits.TrailingZeros64(set) ^ flip
+			score += sign * (kingMiddleGameTable[i]*phase + kingEndgameTable[i]*(maxPhase-phase)) / maxPhase
+		}
+	}
+
+	if game.Turn() == gochess.Black {
+		return -score
+	}
+
+	return score
+}
@@ -0,0 +1,64 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultEvaluator(t *testing.T) {
+	evaluate := func(t *testing.T, fen string) int {
+		t.Helper()
+
+		game, err := chess.New(chess.WithFEN(fen))
+		require.NoError(t, err)
+
+		return search.DefaultEvaluator{}.Evaluate(game)
+	}
+
+	t.Run("Starting position", func(t *testing.T) {
+		assert.Zero(t, evaluate(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"))
+	})
+
+	t.Run("Side to move", func(t *testing.T) {
+		white := evaluate(t, "4k3/8/8/8/8/8/8/3QK3 w - - 0 1")
+		black := evaluate(t, "4k3/8/8/8/8/8/8/3QK3 b - - 0 1")
+
+		assert.Greater(t, white, 800)
+		assert.Equal(t, -white, black)
+	})
+
+	t.Run("Mirrored positions", func(t *testing.T) {
+		positions := [][2]string{
+			{
+				"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+				"rnbqkb1r/pppp1ppp/5n2/4p3/4P3/2N5/PPPP1PPP/R1BQKBNR b KQkq - 2 3",
+			},
+			{
+				"8/5k2/8/3p4/8/2N5/5K2/8 w - - 0 1",
+				"8/5k2/2n5/8/3P4/8/5K2/8 b - - 0 1",
+			},
+		}
+
+		for _, p := range positions {
+			assert.Equal(t, evaluate(t, p[0]), evaluate(t, p[1]), p[0])
+		}
+	})
+
+	t.Run("Piece-square tables", func(t *testing.T) {
+		center := evaluate(t, "4k3/8/8/8/3N4/8/8/4K3 w - - 0 1")
+		corner := evaluate(t, "4k3/8/8/8/8/8/8/N3K3 w - - 0 1")
+
+		assert.Greater(t, center, corner)
+	})
+
+	t.Run("The king is centralized in the endgame", func(t *testing.T) {
+		center := evaluate(t, "4k3/pppppppp/8/8/3K4/8/PPPPPPPP/8 w - - 0 1")
+		corner := evaluate(t, "4k3/pppppppp/8/8/8/8/PPPPPPPP/6K1 w - - 0 1")
+
+		assert.Greater(t, center, corner)
+	})
+}
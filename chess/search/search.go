@@ -0,0 +1,654 @@
+// Package search chooses moves for chess games with an iterative deepening
+// alpha-beta search.
+//
+// The search tracks the principal variation, resolves the captures of the
+// leaves with a quiescence search, extends the lines where the side to move
+// is in check and scores the checkmates by their distance. The positions are
+// scored by an Evaluator, which defaults to a material and piece-square
+// table evaluation.
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+const (
+	// MaxDepth is the maximum depth of a search, in plies.
+	MaxDepth = 64
+	// MateScore is the score of checkmating the opponent right now. A
+	// checkmate in n plies scores MateScore-n for the side that mates and
+	// -(MateScore-n) for the side that is mated.
+	MateScore = 100000
+
+	// maxPly is the maximum number of plies from the root, including the
+	// check extensions and the quiescence search.
+	maxPly = 2 * MaxDepth
+	// infinity is greater than any score.
+	infinity = MateScore + 1
+	// checkInterval is the number of nodes between the checks of the time
+	// and the context of the search.
+	checkInterval = 1024
+)
+
+// Limits are the limits of a search. Zero values mean no limit.
+//
+// The first iteration, of depth one, is always completed so the search has
+// a move to return.
+type Limits struct {
+	// Depth is the maximum depth of the search, in plies.
+	Depth int
+	// Nodes is the maximum number of nodes to search.
+	Nodes uint64
+	// Time is the maximum duration of the search.
+	Time time.Duration
+}
+
+// Result is the result of a search.
+type Result struct {
+	// Move is the best move found.
+	Move chess.Move
+	// Score is the score of the position in centipawns, from the point of
+	// view of the side to move.
+	Score int
+	// Mate is the number of moves to checkmate when the search found one:
+	// positive if the side to move mates and negative if it is mated.
+	// It is 0 otherwise.
+	Mate int
+	// Depth is the depth of the last completed iteration.
+	Depth int
+	// PV is the principal variation, starting with Move.
+	PV []chess.Move
+	// Nodes is the number of nodes searched.
+	Nodes uint64
+	// Time is the duration of the search.
+	Time time.Duration
+}
+
+// Option is a function that configures a searcher.
+type Option func(*Searcher)
+
+// WithEvaluator sets the evaluator of the positions. By default, the
+// searcher uses a DefaultEvaluator.
+func WithEvaluator(e Evaluator) Option {
+	return func(s *Searcher) {
+		s.evaluator = e
+	}
+}
+
+// WithTranspositionTable sets a transposition table to cache the searched
+// positions. The table is kept between searches and can be shared by several
+// searchers. By default, the searcher doesn't use a transposition table.
+func WithTranspositionTable(tt *TranspositionTable) Option {
+	return func(s *Searcher) {
+		s.tt = tt
+	}
+}
+
+// WithProgress sets a function that is called with the result of every
+// completed iteration of the searches.
+func WithProgress(f func(Result)) Option {
+	return func(s *Searcher) {
+		s.progress = f
+	}
+}
+
+// Searcher searches the best moves of chess positions.
+//
+// A Searcher keeps no state between searches other than its transposition
+// table, and can run several of them at the same time.
+type Searcher struct {
+	evaluator Evaluator
+	tt        *TranspositionTable
+	progress  func(Result)
+}
+
+// New creates a new searcher.
+func New(opts ...Option) *Searcher {
+	s := &Searcher{evaluator: DefaultEvaluator{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Search searches the best move of the current position of the game within
+// the limits. The game is not modified.
+//
+// The search deepens one ply at a time until a limit is reached, ctx is
+// done, a checkmate is found within the depth or MaxDepth is completed, and
+// returns the result of the last completed iteration.
+//
+// It returns chess.ErrGameOver if the game has ended.
+func (s *Searcher) Search(ctx context.Context, game *chess.Chess, limits Limits) (Result, error) {
+	return s.search(ctx, game, limits, s.progress)
+}
+
+// search runs Search, calling progress, if not nil, with the result of
+// every completed iteration.
+func (s *Searcher) search(ctx context.Context, game *chess.Chess, limits Limits, progress func(Result)) (Result, error) {
+	if o := game.Outcome(); o.IsOver() {
+		return Result{}, fmt.Errorf("%w: %s", chess.ErrGameOver, o.Termination)
+	}
+
+	st, err := newState(ctx, game, s.evaluator, s.tt, limits)
+	if err != nil {
+		return Result{}, err
+	}
+
+	maxDepth := MaxDepth
+	if limits.Depth > 0 {
+		maxDepth = min(limits.Depth, MaxDepth)
+	}
+
+	var result Result
+	for depth := 1; depth <= maxDepth; depth++ {
+		st.prevPV, st.rootDepth = result.PV, depth
+		score := st.negamax(depth, 0, -infinity, infinity)
+		if st.stopped {
+			break
+		}
+
+		result = Result{
+			Move:  st.pv[0][0],
+			Score: score,
+			Mate:  mateIn(score),
+			Depth: depth,
+			PV:    slices.Clone(st.pv[0]),
+			Nodes: st.nodes,
+			Time:  time.Since(st.start),
+		}
+
+		if progress != nil {
+			progress(result)
+		}
+
+		// A checkmate within the depth can't be improved by searching deeper.
+		if result.Mate != 0 && MateScore-abs(score) <= depth {
+			break
+		}
+
+		st.canStop = true
+		if st.expired() {
+			break
+		}
+	}
+
+	result.Nodes = st.nodes
+	result.Time = time.Since(st.start)
+	return result, nil
+}
+
+// position is a position of the game or the search path, kept to detect
+// repetitions.
+type position struct {
+	// hash is the Zobrist hash of the position.
+	hash uint64
+	// halfMoves is the number of half moves since the last capture or pawn
+	// move.
+	halfMoves int
+}
+
+// next returns the position reached from p by playing m, whose hash is
+// given.
+func (p position) next(m chess.Move, hash uint64) position {
+	if m.IsCapture() || gochess.PieceType(m.Piece) == gochess.Pawn {
+		return position{hash: hash}
+	}
+
+	return position{hash: hash, halfMoves: p.halfMoves + 1}
+}
+
+// halfMoves returns the half move clock of a FEN string.
+func halfMoves(fen string) int {
+	fields := strings.Fields(fen)
+	if len(fields) < 5 {
+		return 0
+	}
+
+	n, _ := strconv.Atoi(fields[4])
+	return n
+}
+
+// state is the state of a running search.
+type state struct {
+	ctx       context.Context
+	game      *chess.Chess
+	evaluator Evaluator
+	tt        *TranspositionTable
+	limits    Limits
+	start     time.Time
+
+	nodes uint64
+	// rootDepth is the depth of the current iteration.
+	rootDepth int
+	// canStop is true once the first iteration is completed.
+	canStop bool
+	// stopped is true when the search was interrupted by a limit.
+	stopped bool
+
+	// positions are the positions of the game and the search path, the
+	// current one last.
+	positions []position
+	// pv are the principal variations found from each ply.
+	pv [maxPly + 1][]chess.Move
+	// prevPV is the principal variation of the previous iteration, which
+	// is searched first.
+	prevPV []chess.Move
+	// killers are the quiet moves that caused the last beta cutoffs of
+	// each ply.
+	killers [maxPly + 1][2]chess.Move
+	// moves and scored are the buffers of the moves of each ply and their
+	// ordering scores, reused by every node of the ply.
+	moves  [maxPly + 1][]chess.Move
+	scored [maxPly + 1][]scoredMove
+}
+
+// newState returns the state of a search of the game. The search runs on a
+// copy of the game without clock, so the game and its clock are not
+// modified.
+func newState(ctx context.Context, game *chess.Chess, e Evaluator, tt *TranspositionTable, limits Limits) (*state, error) {
+	opts := []chess.Option{chess.WithParallelism(1), chess.WithFEN(game.StartingFEN())}
+	if game.IsChess960() {
+		opts = append(opts, chess.WithChess960())
+	}
+
+	g, err := chess.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy the game: %w", err)
+	}
+
+	positions := make([]position, 1, len(game.History())+maxPly+1)
+	positions[0] = position{hash: g.Hash(), halfMoves: halfMoves(g.FEN())}
+	for _, m := range game.History() {
+		if err := g.PlayMove(m); err != nil {
+			return nil, fmt.Errorf("failed to copy the game: %w", err)
+		}
+
+		positions = append(positions, positions[len(positions)-1].next(m, g.Hash()))
+	}
+
+	return &state{
+		ctx:       ctx,
+		game:      g,
+		evaluator: e,
+		tt:        tt,
+		limits:    limits,
+		start:     time.Now(),
+		positions: positions,
+	}, nil
+}
+
+// negamax searches the position to the given depth and returns its score
+// from the point of view of the side to move.
+func (s *state) negamax(depth, ply, alpha, beta int) int {
+	s.pv[ply] = s.pv[ply][:0]
+	inCheck := s.game.Checkers() != 0
+	if ply > 0 && s.isDraw(ply, inCheck) {
+		return 0
+	}
+
+	// Checks are extended up to twice the depth of the iteration, so the
+	// lines of checks answered by checks can't grow without limit.
+	if inCheck && ply < 2*s.rootDepth {
+		depth++
+	}
+
+	if depth <= 0 {
+		return s.quiesce(ply, alpha, beta)
+	}
+
+	s.nodes++
+	if s.shouldStop() {
+		return 0
+	}
+
+	if ply > 0 {
+		// A shorter checkmate was already found: this node can't improve
+		// the bounds.
+		alpha = max(alpha, -MateScore+ply)
+		beta = min(beta, MateScore-ply-1)
+		if alpha >= beta {
+			return alpha
+		}
+	}
+
+	if ply >= maxPly {
+		return s.evaluator.Evaluate(s.game)
+	}
+
+	hash := s.game.Hash()
+	var hashMove chess.Move
+	if s.tt != nil {
+		if e, ok := s.tt.Probe(hash); ok {
+			hashMove = e.Move
+			score := scoreFromTable(e.Score, ply)
+			if ply > 0 && e.Depth >= depth &&
+				(e.Bound == BoundExact ||
+					e.Bound == BoundLower && score >= beta ||
+					e.Bound == BoundUpper && score <= alpha) {
+				return score
+			}
+		}
+	}
+
+	moves := s.orderMoves(ply, hashMove, (*chess.Chess).GenerateMoves)
+	if len(moves) == 0 {
+		if inCheck {
+			return -MateScore + ply
+		}
+		return 0
+	}
+
+	alphaOrig := alpha
+	best, bestMove := -infinity, chess.Move{}
+	for _, m := range moves {
+		s.play(m)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		s.unmake()
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best, bestMove = score, m
+		}
+
+		if score > alpha {
+			alpha = score
+			s.pv[ply] = append(append(s.pv[ply][:0], m), s.pv[ply+1]...)
+		}
+
+		if alpha >= beta {
+			if !m.IsCapture() && !m.IsPromotion() && m != s.killers[ply][0] {
+				s.killers[ply][1] = s.killers[ply][0]
+				s.killers[ply][0] = m
+			}
+
+			break
+		}
+	}
+
+	if s.tt != nil {
+		bound := BoundExact
+		if best <= alphaOrig {
+			bound = BoundUpper
+		} else if best >= beta {
+			bound = BoundLower
+		}
+
+		s.tt.Store(Entry{Key: hash, Move: bestMove, Score: scoreToTable(best, ply), Depth: depth, Bound: bound})
+	}
+
+	return best
+}
+
+// quiesce searches the captures and promotions of the position until it is
+// quiet, and returns its score from the point of view of the side to move.
+// When the side to move is in check, every evasion is searched.
+//
+// Only the captures and promotions are generated, so a stalemate is only
+// found when the side to move can't stand pat and has no captures.
+func (s *state) quiesce(ply, alpha, beta int) int {
+	s.pv[ply] = s.pv[ply][:0]
+	s.nodes++
+	if s.shouldStop() {
+		return 0
+	}
+
+	if ply >= maxPly {
+		return s.evaluator.Evaluate(s.game)
+	}
+
+	inCheck := s.game.Checkers() != 0
+	generate := tacticalMoves
+	best := -infinity
+	if inCheck {
+		generate = (*chess.Chess).GenerateMoves
+	} else {
+		best = s.evaluator.Evaluate(s.game)
+		if best >= beta {
+			return best
+		}
+
+		alpha = max(alpha, best)
+	}
+
+	moves := s.orderMoves(ply, chess.Move{}, generate)
+	if len(moves) == 0 {
+		if inCheck {
+			return -MateScore + ply
+		}
+
+		if s.moves[ply] = s.game.GenerateQuietMoves(s.moves[ply][:0]); len(s.moves[ply]) == 0 {
+			return 0
+		}
+
+		return best
+	}
+
+	for _, m := range moves {
+		s.play(m)
+		score := -s.quiesce(ply+1, -beta, -alpha)
+		s.unmake()
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+		}
+
+		if score > alpha {
+			alpha = score
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return best
+}
+
+// play plays a legal move and records the new position.
+func (s *state) play(m chess.Move) {
+	s.game.Push(m)
+	s.positions = append(s.positions, s.positions[len(s.positions)-1].next(m, s.game.Hash()))
+}
+
+// unmake unmakes the last move played by play.
+func (s *state) unmake() {
+	s.game.Pop()
+	s.positions = s.positions[:len(s.positions)-1]
+}
+
+// isDraw returns true if the position at the given ply is drawn by the
+// fifty-move rule, insufficient material or the repetition of a previous
+// position. Stalemates are found when the moves of the position are
+// generated.
+func (s *state) isDraw(ply int, inCheck bool) bool {
+	if s.isRepetition() || s.game.IsInsufficientMaterial() {
+		return true
+	}
+
+	// A checkmate on the move that completes the fifty moves still wins.
+	if !s.game.IsFiftyMoveRule() {
+		return false
+	}
+
+	s.moves[ply] = s.game.GenerateMoves(s.moves[ply][:0])
+	return !inCheck || len(s.moves[ply]) > 0
+}
+
+// isRepetition returns true if the position occurred before, in the game
+// or in the search path, since the last capture or pawn move.
+func (s *state) isRepetition() bool {
+	n := len(s.positions) - 1
+	current := s.positions[n]
+	for i := n - 2; i >= 0 && i >= n-current.halfMoves; i -= 2 {
+		if s.positions[i].hash == current.hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldStop returns true if the search must stop because a limit was
+// reached or the context is done. Once it returns true, it always does.
+func (s *state) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+
+	if !s.canStop {
+		return false
+	}
+
+	s.stopped = (s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes) ||
+		(s.nodes%checkInterval == 0 && s.expired())
+	return s.stopped
+}
+
+// expired returns true if the time of the search is over or its context is
+// done.
+func (s *state) expired() bool {
+	return s.ctx.Err() != nil || (s.limits.Time > 0 && time.Since(s.start) >= s.limits.Time)
+}
+
+// orderMoves returns the legal moves of the position at the given ply
+// generated by generate in the order they are searched: the move of the previous principal
+// variation, the move of the transposition table, the captures and
+// promotions by most valuable victim and least valuable attacker, the
+// killer moves and the rest of the moves.
+//
+// The moves are kept in the buffer of the ply, so they are valid until the
+// next call for the same ply.
+func (s *state) orderMoves(ply int, hashMove chess.Move, generate func(*chess.Chess, []chess.Move) []chess.Move) []chess.Move {
+	var pvMove chess.Move
+	if ply < len(s.prevPV) {
+		pvMove = s.prevPV[ply]
+	}
+
+	moves := generate(s.game, s.moves[ply][:0])
+	scored := slices.Grow(s.scored[ply][:0], len(moves))[:len(moves)]
+	s.moves[ply], s.scored[ply] = moves, scored
+	for i, m := range moves {
+		var score int
+		switch {
+		case m == pvMove:
+			score = 1 << 21
+		case m == hashMove:
+			score = 1 << 20
+		case m.IsCapture() || m.IsPromotion():
+			victim := pieceValues[gochess.PieceType(m.Captured)] + pieceValues[gochess.PieceType(m.Promotion)]
+			score = 1<<16 + victim*8 - int(gochess.PieceType(m.Piece))
+		case m == s.killers[ply][0]:
+			score = 1 << 15
+		case m == s.killers[ply][1]:
+			score = 1<<15 - 1
+		}
+
+		scored[i] = scoredMove{move: m, score: score}
+	}
+
+	slices.SortStableFunc(scored, func(a, b scoredMove) int {
+		return b.score - a.score
+	})
+
+	for i, sm := range scored {
+		moves[i] = sm.move
+	}
+
+	return moves
+}
+
+// tacticalMoves appends the legal captures and promotions of the game to
+// dst. The quiet moves are only generated when a pawn can promote.
+func tacticalMoves(game *chess.Chess, dst []chess.Move) []chess.Move {
+	dst = game.GenerateCaptures(dst)
+
+	// The pawns promote from the seventh rank, or the second one for black.
+	promotionRank := uint64(0x00FF000000000000)
+	if game.Turn() == gochess.Black {
+		promotionRank = 0x000000000000FF00
+	}
+
+	if game.Pieces(game.Turn()|gochess.Pawn)&promotionRank == 0 {
+		return dst
+	}
+
+	n := len(dst)
+	dst = game.GenerateQuietMoves(dst)
+	moves := dst[:n]
+	for _, m := range dst[n:] {
+		if m.IsPromotion() {
+			moves = append(moves, m)
+		}
+	}
+
+	return moves
+}
+
+// scoredMove is a move and its ordering score.
+type scoredMove struct {
+	move  chess.Move
+	score int
+}
+
+// mateIn returns the number of moves to checkmate of a score, or 0 if the
+// score is not a checkmate score.
+func mateIn(score int) int {
+	switch {
+	case score >= MateScore-maxPly:
+		return (MateScore - score + 1) / 2
+	case score <= -MateScore+maxPly:
+		return -(MateScore + score) / 2
+	default:
+		return 0
+	}
+}
+
+// scoreToTable returns the score to store in a transposition table for a
+// score found at the given ply. Checkmate scores are stored relative to the
+// position instead of the root.
+func scoreToTable(score, ply int) int {
+	switch {
+	case score >= MateScore-maxPly:
+		return score + ply
+	case score <= -MateScore+maxPly:
+		return score - ply
+	default:
+		return score
+	}
+}
+
+// scoreFromTable is the inverse of scoreToTable.
+func scoreFromTable(score, ply int) int {
+	switch {
+	case score >= MateScore-maxPly:
+		return score - ply
+	case score <= -MateScore+maxPly:
+		return score + ply
+	default:
+		return score
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
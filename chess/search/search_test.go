@@ -0,0 +1,241 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/clock"
+	"github.com/RchrdHndrcks/gochess/v2/chess/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uciMoves returns the UCI notation of the moves.
+func uciMoves(moves []chess.Move) []string {
+	s := make([]string, len(moves))
+	for i, m := range moves {
+		s[i] = m.UCI()
+	}
+
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("Mate in one", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 4})
+		require.NoError(t, err)
+
+		assert.Equal(t, "a1a8", result.Move.UCI())
+		assert.Equal(t, 1, result.Mate)
+		assert.Equal(t, search.MateScore-1, result.Score)
+		assert.Equal(t, []string{"a1a8"}, uciMoves(result.PV))
+		assert.Equal(t, 1, result.Depth, "the search stops once the mate is proven")
+	})
+
+	t.Run("Mate in two", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("7k/8/8/8/8/8/R7/1R4K1 w - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 5})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.Mate)
+		assert.Equal(t, search.MateScore-3, result.Score)
+		require.Len(t, result.PV, 3)
+		for _, m := range result.PV {
+			require.NoError(t, game.PlayMove(m))
+		}
+		assert.True(t, game.IsCheckmate())
+	})
+
+	t.Run("Mated in one", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("k7/8/1K6/8/8/8/8/7R b - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 4})
+		require.NoError(t, err)
+
+		assert.Equal(t, "a8b8", result.Move.UCI())
+		assert.Equal(t, -1, result.Mate)
+		assert.Equal(t, -search.MateScore+2, result.Score)
+		assert.Equal(t, []string{"a8b8", "h1h8"}, uciMoves(result.PV))
+	})
+
+	t.Run("Captures a hanging piece", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 3})
+		require.NoError(t, err)
+
+		assert.Equal(t, "d2d5", result.Move.UCI())
+		assert.Greater(t, result.Score, 300)
+		assert.Zero(t, result.Mate)
+	})
+
+	t.Run("Quiescence avoids losing captures", func(t *testing.T) {
+		// The pawn on d5 is defended by the pawn on e6: taking it with the
+		// queen loses the queen.
+		game, err := chess.New(chess.WithFEN("4k3/8/4p3/3p4/8/8/3Q4/4K3 w - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 1})
+		require.NoError(t, err)
+
+		assert.NotEqual(t, "d2d5", result.Move.UCI())
+	})
+
+	t.Run("Stalemates are draws", func(t *testing.T) {
+		// Taking the rook with the queen stalemates the black king.
+		game, err := chess.New(chess.WithFEN("k7/2r5/8/8/8/8/8/K1Q5 w - - 0 1"))
+		require.NoError(t, err)
+
+		for depth := 1; depth <= 3; depth++ {
+			result, err := search.New().Search(context.Background(), game, search.Limits{Depth: depth})
+			require.NoError(t, err)
+
+			assert.NotEqual(t, "c1c7", result.Move.UCI(), depth)
+			assert.Greater(t, result.Score, 300, depth)
+		}
+	})
+
+	t.Run("Check extensions are limited", func(t *testing.T) {
+		// The checks can be answered by checks.
+		game, err := chess.New(chess.WithFEN("5K2/3R1Q2/8/2k5/qq6/8/8/3Q4 w - - 0 1"))
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 1})
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(result.PV), 2)
+	})
+
+	t.Run("Depth limit and progress", func(t *testing.T) {
+		game, err := chess.New()
+		require.NoError(t, err)
+
+		var depths []int
+		s := search.New(search.WithProgress(func(r search.Result) {
+			depths = append(depths, r.Depth)
+		}))
+
+		result, err := s.Search(context.Background(), game, search.Limits{Depth: 3})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Depth)
+		assert.Equal(t, []int{1, 2, 3}, depths)
+		assert.Len(t, result.PV, 3)
+		assert.Equal(t, result.PV[0], result.Move)
+		assert.Positive(t, result.Nodes)
+	})
+
+	t.Run("Node limit", func(t *testing.T) {
+		game, err := chess.New()
+		require.NoError(t, err)
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Nodes: 2000})
+		require.NoError(t, err)
+
+		assert.LessOrEqual(t, result.Nodes, uint64(2000))
+		assert.GreaterOrEqual(t, result.Depth, 1)
+		assert.Contains(t, game.AvailableMoves(), result.Move.UCI())
+	})
+
+	t.Run("Time limit", func(t *testing.T) {
+		game, err := chess.New()
+		require.NoError(t, err)
+
+		start := time.Now()
+		result, err := search.New().Search(context.Background(), game, search.Limits{Time: 50 * time.Millisecond})
+		require.NoError(t, err)
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.GreaterOrEqual(t, result.Depth, 1)
+	})
+
+	t.Run("Context cancellation", func(t *testing.T) {
+		game, err := chess.New()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result, err := search.New().Search(ctx, game, search.Limits{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Depth, "the first iteration is always completed")
+		assert.Contains(t, game.AvailableMoves(), result.Move.UCI())
+	})
+
+	t.Run("Custom evaluator", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"))
+		require.NoError(t, err)
+
+		var calls int
+		s := search.New(search.WithEvaluator(search.EvaluatorFunc(func(*chess.Chess) int {
+			calls++
+			return 0
+		})))
+
+		result, err := s.Search(context.Background(), game, search.Limits{Depth: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, "a1a8", result.Move.UCI())
+		assert.Positive(t, calls)
+	})
+
+	t.Run("Repetitions are draws", func(t *testing.T) {
+		// Black is a queen down, but Kh8 repeats a position of the game, so
+		// the search scores it as a draw.
+		game, err := chess.New(chess.WithFEN("6k1/8/6K1/8/8/8/8/1Q6 b - - 0 1"))
+		require.NoError(t, err)
+		for _, m := range []string{"g8h8", "b1c1", "h8g8", "c1b1"} {
+			require.NoError(t, game.MakeMove(m))
+		}
+
+		result, err := search.New().Search(context.Background(), game, search.Limits{Depth: 1})
+		require.NoError(t, err)
+
+		assert.Equal(t, "g8h8", result.Move.UCI())
+		assert.Zero(t, result.Score)
+	})
+
+	t.Run("The game is not modified", func(t *testing.T) {
+		clk, err := clock.New(clock.SuddenDeath(time.Minute), clock.WithTimeSource(clock.NewManualTime(time.Now()).Now))
+		require.NoError(t, err)
+		game, err := chess.New(chess.WithClock(clk))
+		require.NoError(t, err)
+		require.NoError(t, game.MakeMove("e2e4"))
+		fen := game.FEN()
+		remaining := clk.Remaining(gochess.Black)
+
+		_, err = search.New().Search(context.Background(), game, search.Limits{Depth: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, fen, game.FEN())
+		assert.Len(t, game.History(), 1)
+		assert.Equal(t, remaining, clk.Remaining(gochess.Black))
+		assert.Equal(t, gochess.Black, clk.Turn())
+	})
+
+	t.Run("Game over", func(t *testing.T) {
+		game, err := chess.New(chess.WithFEN("k7/1Q6/1K6/8/8/8/8/8 b - - 0 1"))
+		require.NoError(t, err)
+
+		_, err = search.New().Search(context.Background(), game, search.Limits{Depth: 1})
+		assert.ErrorIs(t, err, chess.ErrGameOver)
+
+		game, err = chess.New()
+		require.NoError(t, err)
+		require.NoError(t, game.Resign(gochess.White))
+
+		_, err = search.New().Search(context.Background(), game, search.Limits{Depth: 1})
+		assert.ErrorIs(t, err, chess.ErrGameOver)
+	})
+}
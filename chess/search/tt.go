@@ -0,0 +1,121 @@
+package search
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// Bound is the kind of score stored in a transposition table entry.
+type Bound uint8
+
+const (
+	// BoundExact is the bound of scores inside the search window.
+	BoundExact Bound = iota
+	// BoundLower is the bound of scores that failed high: the real score is
+	// at least the stored one.
+	BoundLower
+	// BoundUpper is the bound of scores that failed low: the real score is
+	// at most the stored one.
+	BoundUpper
+)
+
+// Entry is an entry of a transposition table.
+type Entry struct {
+	// Key is the hash of the position, as returned by chess.Chess.Hash.
+	Key uint64
+	// Move is the best move found in the position, if any.
+	Move chess.Move
+	// Score is the score of the position.
+	Score int
+	// Depth is the depth the position was searched to.
+	Depth int
+	// Bound is the kind of score.
+	Bound Bound
+}
+
+// shards is the number of locks of a transposition table.
+const shards = 256
+
+// TranspositionTable is a fixed size cache of searched positions, indexed by
+// their hashes.
+//
+// Every hash maps to a single slot, so storing an entry replaces the entry
+// of another position in the same slot. An entry of the same position is
+// only replaced by entries searched at least as deep. It is safe for
+// concurrent use by multiple goroutines.
+type TranspositionTable struct {
+	mu      [shards]sync.Mutex
+	entries []Entry
+	used    []bool
+	mask    uint64
+}
+
+// NewTranspositionTable creates a transposition table that uses at most
+// size bytes (e.g. 64<<20 for 64 MiB). The number of entries is rounded down
+// to a power of two and is at least one.
+func NewTranspositionTable(size int) *TranspositionTable {
+	entrySize := int(unsafe.Sizeof(Entry{})) + 1
+	n := 1
+	for n*2*entrySize <= size {
+		n *= 2
+	}
+
+	return &TranspositionTable{
+		entries: make([]Entry, n),
+		used:    make([]bool, n),
+		mask:    uint64(n - 1),
+	}
+}
+
+// Len returns the number of entries the table can hold.
+func (t *TranspositionTable) Len() int {
+	return len(t.entries)
+}
+
+// Probe returns the entry of the position with the given hash, if it is in
+// the table.
+func (t *TranspositionTable) Probe(key uint64) (Entry, bool) {
+	i := key & t.mask
+	mu := &t.mu[i%shards]
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !t.used[i] || t.entries[i].Key != key {
+		return Entry{}, false
+	}
+
+	return t.entries[i], true
+}
+
+// Store stores an entry in the table.
+func (t *TranspositionTable) Store(e Entry) {
+	i := e.Key & t.mask
+	mu := &t.mu[i%shards]
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if t.used[i] && t.entries[i].Key == e.Key && t.entries[i].Depth > e.Depth {
+		return
+	}
+
+	t.entries[i] = e
+	t.used[i] = true
+}
+
+// Clear removes all the entries of the table.
+func (t *TranspositionTable) Clear() {
+	for i := range t.mu {
+		t.mu[i].Lock()
+	}
+
+	clear(t.entries)
+	clear(t.used)
+
+	for i := range t.mu {
+		t.mu[i].Unlock()
+	}
+}
@@ -0,0 +1,108 @@
+package search_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranspositionTable(t *testing.T) {
+	t.Run("Size", func(t *testing.T) {
+		assert.Equal(t, 1, search.NewTranspositionTable(0).Len())
+
+		tt := search.NewTranspositionTable(1 << 20)
+		assert.Positive(t, tt.Len())
+		assert.Zero(t, tt.Len()&(tt.Len()-1), "the number of entries is a power of two")
+		assert.Less(t, tt.Len(), 1<<20)
+	})
+
+	t.Run("Probe and store", func(t *testing.T) {
+		tt := search.NewTranspositionTable(1 << 16)
+
+		_, ok := tt.Probe(42)
+		assert.False(t, ok)
+
+		e := search.Entry{Key: 42, Score: 15, Depth: 3, Bound: search.BoundLower}
+		tt.Store(e)
+
+		got, ok := tt.Probe(42)
+		require.True(t, ok)
+		assert.Equal(t, e, got)
+
+		_, ok = tt.Probe(42 + uint64(tt.Len()))
+		assert.False(t, ok, "another position in the same slot")
+	})
+
+	t.Run("Replacement", func(t *testing.T) {
+		tt := search.NewTranspositionTable(1 << 16)
+
+		tt.Store(search.Entry{Key: 7, Depth: 5})
+		tt.Store(search.Entry{Key: 7, Depth: 2})
+		got, _ := tt.Probe(7)
+		assert.Equal(t, 5, got.Depth, "shallower entries of the same position don't replace deeper ones")
+
+		tt.Store(search.Entry{Key: 7 + uint64(tt.Len()), Depth: 1})
+		_, ok := tt.Probe(7)
+		assert.False(t, ok, "entries of other positions always replace")
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		tt := search.NewTranspositionTable(1 << 16)
+		tt.Store(search.Entry{Key: 0, Depth: 1})
+
+		tt.Clear()
+
+		_, ok := tt.Probe(0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		tt := search.NewTranspositionTable(1 << 12)
+
+		var wg sync.WaitGroup
+		for g := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range 1000 {
+					key := uint64(g*1000 + i)
+					tt.Store(search.Entry{Key: key, Depth: i})
+					if e, ok := tt.Probe(key); ok {
+						assert.Equal(t, key, e.Key)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		tt := search.NewTranspositionTable(16 << 20)
+		s := search.New(search.WithTranspositionTable(tt))
+
+		game, err := chess.New(chess.WithFEN("7k/8/8/8/8/8/R7/1R4K1 w - - 0 1"))
+		require.NoError(t, err)
+
+		first, err := s.Search(context.Background(), game, search.Limits{Depth: 5})
+		require.NoError(t, err)
+		assert.Equal(t, 2, first.Mate)
+
+		second, err := s.Search(context.Background(), game, search.Limits{Depth: 5})
+		require.NoError(t, err)
+		assert.Equal(t, 2, second.Mate)
+		assert.Less(t, second.Nodes, first.Nodes, "the second search reuses the table")
+
+		game, err = chess.New()
+		require.NoError(t, err)
+		withTable, err := s.Search(context.Background(), game, search.Limits{Depth: 4})
+		require.NoError(t, err)
+		withoutTable, err := search.New().Search(context.Background(), game, search.Limits{Depth: 4})
+		require.NoError(t, err)
+		assert.Equal(t, withoutTable.Score, withTable.Score)
+	})
+}
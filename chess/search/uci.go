@@ -0,0 +1,132 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+)
+
+const (
+	// defaultMovesToGo is the number of moves the remaining time is split
+	// into when the time control has no moves to go.
+	defaultMovesToGo = 30
+	// moveOverhead is the time kept on the clock for the communication with
+	// the GUI.
+	moveOverhead = 50 * time.Millisecond
+)
+
+// UCISearcher adapts a Searcher to the uci.Searcher interface, so it can
+// run behind a uci.Server or a cecp.Server.
+type UCISearcher struct {
+	searcher *Searcher
+}
+
+var (
+	_ uci.Searcher = (*UCISearcher)(nil)
+	_ uci.NewGamer = (*UCISearcher)(nil)
+)
+
+// NewUCISearcher returns an adapter of the searcher to the uci.Searcher
+// interface.
+func NewUCISearcher(s *Searcher) *UCISearcher {
+	return &UCISearcher{searcher: s}
+}
+
+// Search searches the best move of the game within the UCI limits.
+//
+// The time of the search is MoveTime or, with a clock, a share of the time
+// left to the side to move. A side without time left searches one ply. Searches with Infinite or Ponder set have no
+// time limit and run until ctx is done or the search ends. Mate limits the
+// depth to the plies of the mate. SearchMoves is ignored.
+//
+// The result of every completed iteration is reported as an info, and
+// passed to the progress function of the searcher, if any.
+func (u *UCISearcher) Search(ctx context.Context, game *chess.Chess, limits uci.Limits, info func(uci.Info)) (uci.SearchResult, error) {
+	result, err := u.searcher.search(ctx, game, fromUCI(limits, game.Turn()), func(r Result) {
+		if u.searcher.progress != nil {
+			u.searcher.progress(r)
+		}
+		info(toInfo(r))
+	})
+	if err != nil {
+		return uci.SearchResult{}, err
+	}
+
+	r := uci.SearchResult{BestMove: result.Move.UCI(), Lines: []uci.Info{toInfo(result)}}
+	if len(result.PV) > 1 {
+		r.Ponder = result.PV[1].UCI()
+	}
+
+	return r, nil
+}
+
+// NewGame clears the transposition table of the searcher, if it has one.
+func (u *UCISearcher) NewGame() {
+	if u.searcher.tt != nil {
+		u.searcher.tt.Clear()
+	}
+}
+
+// fromUCI returns the limits of a search from the UCI limits of a search
+// of the given color.
+func fromUCI(limits uci.Limits, turn gochess.Piece) Limits {
+	l := Limits{Depth: limits.Depth, Nodes: limits.Nodes}
+	if limits.Mate > 0 && l.Depth == 0 {
+		l.Depth = 2*limits.Mate - 1
+	}
+
+	switch {
+	case limits.Infinite || limits.Ponder:
+	case limits.MoveTime > 0:
+		l.Time = limits.MoveTime
+	default:
+		left, increment := limits.WhiteTime, limits.WhiteIncrement
+		if turn == gochess.Black {
+			left, increment = limits.BlackTime, limits.BlackIncrement
+		}
+
+		if !limits.Clock {
+			break
+		}
+
+		if left <= 0 {
+			// The clock is empty or overdrawn: move as soon as possible.
+			l.Depth, l.Time = 1, time.Millisecond
+			break
+		}
+
+		movesToGo := limits.MovesToGo
+		if movesToGo <= 0 {
+			movesToGo = defaultMovesToGo
+		}
+
+		budget := left/time.Duration(movesToGo) + increment/2
+		// The time limit must not be zero, which means no limit.
+		l.Time = max(min(budget, left-moveOverhead), time.Millisecond)
+	}
+
+	return l
+}
+
+// toInfo returns the info of the result of a search.
+func toInfo(r Result) uci.Info {
+	score := &uci.Score{CP: r.Score}
+	if r.Mate != 0 {
+		score = &uci.Score{Mate: r.Mate, IsMate: true}
+	}
+
+	pv := make([]string, len(r.PV))
+	for i, m := range r.PV {
+		pv[i] = m.UCI()
+	}
+
+	info := uci.Info{Depth: r.Depth, Score: score, Nodes: r.Nodes, Time: r.Time, PV: pv}
+	if r.Time > 0 {
+		info.NPS = uint64(float64(r.Nodes) / r.Time.Seconds())
+	}
+
+	return info
+}
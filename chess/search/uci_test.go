@@ -0,0 +1,102 @@
+package search_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/search"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveUCI runs a uci.Server with the searcher and returns its output
+// lines.
+func serveUCI(t *testing.T, s *search.Searcher, commands ...string) []string {
+	t.Helper()
+
+	server := uci.NewServer("Gopher", "", search.NewUCISearcher(s))
+
+	var out strings.Builder
+	err := server.Serve(context.Background(), strings.NewReader(strings.Join(commands, "\n")+"\n"), &out)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestUCISearcher(t *testing.T) {
+	t.Run("Mate in one", func(t *testing.T) {
+		out := serveUCI(t, search.New(), "position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go depth 4")
+
+		require.Len(t, out, 2)
+		info, err := uci.ParseInfo(out[0])
+		require.NoError(t, err)
+		assert.Equal(t, &uci.Score{Mate: 1, IsMate: true}, info.Score)
+		assert.Equal(t, []string{"a1a8"}, info.PV)
+		assert.Equal(t, "bestmove a1a8", out[1])
+	})
+
+	t.Run("Ponder move and progress", func(t *testing.T) {
+		var depths []int
+		s := search.New(search.WithProgress(func(r search.Result) {
+			depths = append(depths, r.Depth)
+		}))
+
+		out := serveUCI(t, s, "position startpos moves e2e4", "go depth 3")
+
+		require.Len(t, out, 4)
+		assert.Equal(t, []int{1, 2, 3}, depths)
+		for i, line := range out[:3] {
+			info, err := uci.ParseInfo(line)
+			require.NoError(t, err)
+			assert.Equal(t, i+1, info.Depth)
+			assert.Len(t, info.PV, i+1)
+		}
+		assert.Regexp(t, `^bestmove \w{4} ponder \w{4}$`, out[3])
+	})
+
+	t.Run("Clock", func(t *testing.T) {
+		start := time.Now()
+		out := serveUCI(t, search.New(), "position startpos", "go wtime 1500 btime 1500")
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, strings.HasPrefix(out[len(out)-1], "bestmove "))
+	})
+
+	t.Run("Overdrawn clock", func(t *testing.T) {
+		start := time.Now()
+		out := serveUCI(t, search.New(), "position startpos moves e2e4 e7e5", "go wtime -50 btime 60000")
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.True(t, strings.HasPrefix(out[len(out)-1], "bestmove "))
+		require.Len(t, out, 2)
+		info, err := uci.ParseInfo(out[0])
+		require.NoError(t, err)
+		assert.Equal(t, 1, info.Depth)
+	})
+
+	t.Run("Stop ends an infinite search", func(t *testing.T) {
+		out := serveUCI(t, search.New(), "position startpos", "go infinite", "stop")
+
+		assert.True(t, strings.HasPrefix(out[len(out)-1], "bestmove "))
+	})
+
+	t.Run("New game clears the transposition table", func(t *testing.T) {
+		tt := search.NewTranspositionTable(1 << 20)
+		game, err := chess.New()
+		require.NoError(t, err)
+
+		u := search.NewUCISearcher(search.New(search.WithTranspositionTable(tt)))
+		_, err = u.Search(context.Background(), game, uci.Limits{Depth: 2}, func(uci.Info) {})
+		require.NoError(t, err)
+		_, ok := tt.Probe(game.Hash())
+		require.True(t, ok)
+
+		u.NewGame()
+		_, ok = tt.Probe(game.Hash())
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,278 @@
+package chess
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+)
+
+type (
+	// SyncGame is a Chess safe for concurrent use by multiple goroutines.
+	//
+	// The changes to the game are serialized with a lock, and the readers
+	// get immutable snapshots of the position, so many goroutines can read
+	// the game while another one plays its moves. Every played and undone
+	// move is sent to the subscribers of the game.
+	SyncGame struct {
+		mu   sync.RWMutex
+		game *Chess
+		// snapshot is the snapshot of the current position. It is replaced
+		// after every change.
+		snapshot *Snapshot
+		// subscribers are the channels of the subscriptions.
+		subscribers map[chan GameEvent]struct{}
+		// closed is true once the game is closed.
+		closed bool
+	}
+
+	// Snapshot is an immutable view of a game at a point in time. It is safe
+	// to share between goroutines.
+	Snapshot struct {
+		fen       string
+		turn      gochess.Piece
+		moves     []Move
+		history   []Move
+		check     bool
+		checkmate bool
+		stalemate bool
+		outcome   Outcome
+		hash      uint64
+	}
+
+	// GameEvent is a move played or undone in a SyncGame.
+	GameEvent struct {
+		// Type is the kind of change.
+		Type GameEventType
+		// Move is the move played or undone.
+		Move Move
+		// Ply is the number of moves played after the change.
+		Ply int
+		// Snapshot is the game after the change that emitted the event. When
+		// a change plays or undoes several moves, all their events share the
+		// snapshot of the end of the change.
+		Snapshot *Snapshot
+	}
+
+	// GameEventType is the kind of change of a GameEvent.
+	GameEventType int
+)
+
+const (
+	// EventMove is sent when a move is played.
+	EventMove GameEventType = iota + 1
+	// EventUndo is sent when a move is undone.
+	EventUndo
+)
+
+// String returns the name of the event type.
+func (t GameEventType) String() string {
+	switch t {
+	case EventMove:
+		return "move"
+	case EventUndo:
+		return "undo"
+	default:
+		return "unknown"
+	}
+}
+
+// NewSyncGame wraps a game to use it from several goroutines.
+//
+// The SyncGame takes ownership of the game: it must not be used directly
+// afterwards.
+func NewSyncGame(c *Chess) *SyncGame {
+	return &SyncGame{
+		game:        c,
+		snapshot:    newSnapshot(c),
+		subscribers: make(map[chan GameEvent]struct{}),
+	}
+}
+
+// Snapshot returns the snapshot of the current position.
+func (g *SyncGame) Snapshot() *Snapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.snapshot
+}
+
+// MakeMove plays a move in UCI notation, as Chess.MakeMove does.
+func (g *SyncGame) MakeMove(move string) error {
+	return g.Update(func(c *Chess) error {
+		return c.MakeMove(move)
+	})
+}
+
+// PlayMove plays a move, as Chess.PlayMove does.
+func (g *SyncGame) PlayMove(m Move) error {
+	return g.Update(func(c *Chess) error {
+		return c.PlayMove(m)
+	})
+}
+
+// UnmakeMove undoes the last move, as Chess.UnmakeMove does.
+func (g *SyncGame) UnmakeMove() {
+	_ = g.Update(func(c *Chess) error {
+		c.UnmakeMove()
+		return nil
+	})
+}
+
+// Update runs fn with exclusive access to the game and returns its error.
+// The game must not be retained after fn returns.
+//
+// Once fn returns, the snapshot is updated and an event is sent for every
+// move undone and played by fn, even if fn returns an error.
+func (g *SyncGame) Update(fn func(c *Chess) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	before := g.game.History()
+	err := fn(g.game)
+	after := g.game.History()
+
+	common := 0
+	for common < len(before) && common < len(after) && before[common] == after[common] {
+		common++
+	}
+
+	g.snapshot = newSnapshot(g.game)
+	for ply := len(before); ply > common; ply-- {
+		g.publish(GameEvent{Type: EventUndo, Move: before[ply-1], Ply: ply - 1, Snapshot: g.snapshot})
+	}
+
+	for ply := common + 1; ply <= len(after); ply++ {
+		g.publish(GameEvent{Type: EventMove, Move: after[ply-1], Ply: ply, Snapshot: g.snapshot})
+	}
+
+	return err
+}
+
+// Subscribe returns a channel that receives the events of the game, in
+// order, and a function to cancel the subscription.
+//
+// The channel holds up to buffer events. If a subscriber falls behind and
+// its channel is full, the subscription is canceled and the channel closed
+// instead of blocking the game or dropping events, so the subscriber can
+// subscribe again and start over from a new snapshot. The channel is also
+// closed when the subscription is canceled or the game is closed.
+func (g *SyncGame) Subscribe(buffer int) (<-chan GameEvent, func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ch := make(chan GameEvent, max(buffer, 0))
+	if g.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	g.subscribers[ch] = struct{}{}
+	return ch, func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		g.unsubscribe(ch)
+	}
+}
+
+// Close cancels all the subscriptions. The game can still be read and
+// changed, but new subscriptions are closed right away.
+func (g *SyncGame) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.closed = true
+	for ch := range g.subscribers {
+		g.unsubscribe(ch)
+	}
+}
+
+// publish sends an event to the subscribers. It must be called with the
+// lock held.
+func (g *SyncGame) publish(e GameEvent) {
+	for ch := range g.subscribers {
+		select {
+		case ch <- e:
+		default:
+			g.unsubscribe(ch)
+		}
+	}
+}
+
+// unsubscribe cancels a subscription, if it is still active. It must be
+// called with the lock held.
+func (g *SyncGame) unsubscribe(ch chan GameEvent) {
+	if _, ok := g.subscribers[ch]; !ok {
+		return
+	}
+
+	delete(g.subscribers, ch)
+	close(ch)
+}
+
+// newSnapshot returns the snapshot of the current position of a game.
+func newSnapshot(c *Chess) *Snapshot {
+	c.refresh()
+	return &Snapshot{
+		fen:       c.FEN(),
+		turn:      c.turn,
+		moves:     slices.Clone(c.moves),
+		history:   c.History(),
+		check:     c.check,
+		checkmate: c.checkmate,
+		stalemate: c.stalemate,
+		outcome:   c.Outcome(),
+		hash:      c.hash,
+	}
+}
+
+// FEN returns the FEN string of the position.
+func (s *Snapshot) FEN() string {
+	return s.fen
+}
+
+// Turn returns the color to move.
+func (s *Snapshot) Turn() gochess.Piece {
+	return s.turn
+}
+
+// Moves returns the legal moves of the position.
+func (s *Snapshot) Moves() []Move {
+	return slices.Clone(s.moves)
+}
+
+// History returns the moves played since the starting position, in order.
+func (s *Snapshot) History() []Move {
+	return slices.Clone(s.history)
+}
+
+// Ply returns the number of moves played since the starting position.
+func (s *Snapshot) Ply() int {
+	return len(s.history)
+}
+
+// IsCheck returns if the color to move is in check.
+func (s *Snapshot) IsCheck() bool {
+	return s.check
+}
+
+// IsCheckmate returns if the color to move is in checkmate.
+func (s *Snapshot) IsCheckmate() bool {
+	return s.checkmate
+}
+
+// IsStalemate returns if the color to move is in stalemate.
+func (s *Snapshot) IsStalemate() bool {
+	return s.stalemate
+}
+
+// Outcome returns the outcome of the game when the snapshot was taken.
+func (s *Snapshot) Outcome() Outcome {
+	return s.outcome
+}
+
+// Hash returns the Zobrist hash of the position.
+func (s *Snapshot) Hash() uint64 {
+	return s.hash
+}
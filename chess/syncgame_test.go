@@ -0,0 +1,232 @@
+package chess_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSyncGame(t *testing.T) *chess.SyncGame {
+	t.Helper()
+
+	c, err := chess.New()
+	require.NoError(t, err)
+	return chess.NewSyncGame(c)
+}
+
+func TestSyncGame(t *testing.T) {
+	t.Run("Snapshot", func(t *testing.T) {
+		g := newSyncGame(t)
+		start := g.Snapshot()
+
+		require.NoError(t, g.MakeMove("e2e4"))
+		s := g.Snapshot()
+
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", start.FEN())
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", s.FEN())
+		assert.Equal(t, gochess.Black, s.Turn())
+		assert.Len(t, s.Moves(), 20)
+		assert.Equal(t, 1, s.Ply())
+		assert.Equal(t, "e2e4", s.History()[0].UCI())
+		assert.False(t, s.IsCheck())
+		assert.False(t, s.Outcome().IsOver())
+		assert.NotEqual(t, start.Hash(), s.Hash())
+
+		// Snapshots are not affected by the changes of the game or of the
+		// slices they return.
+		s.Moves()[0] = chess.Move{}
+		g.UnmakeMove()
+		assert.Equal(t, 1, s.Ply())
+		assert.NotEqual(t, chess.Move{}, s.Moves()[0])
+		assert.Equal(t, start.FEN(), g.Snapshot().FEN())
+	})
+
+	t.Run("Illegal move", func(t *testing.T) {
+		g := newSyncGame(t)
+		ch, cancel := g.Subscribe(1)
+		defer cancel()
+
+		assert.Error(t, g.MakeMove("e2e5"))
+		assert.Equal(t, 0, g.Snapshot().Ply())
+		assert.Empty(t, ch)
+	})
+
+	t.Run("Checkmate", func(t *testing.T) {
+		g := newSyncGame(t)
+		for _, m := range []string{"f2f3", "e7e5", "g2g4", "d8h4"} {
+			require.NoError(t, g.MakeMove(m))
+		}
+
+		s := g.Snapshot()
+		assert.True(t, s.IsCheckmate())
+		assert.False(t, s.IsStalemate())
+		assert.Equal(t, gochess.Black, s.Outcome().Winner)
+		assert.Empty(t, s.Moves())
+	})
+}
+
+func TestSyncGame_Subscribe(t *testing.T) {
+	t.Run("Events", func(t *testing.T) {
+		g := newSyncGame(t)
+		ch, cancel := g.Subscribe(8)
+
+		require.NoError(t, g.MakeMove("e2e4"))
+		require.NoError(t, g.MakeMove("e7e5"))
+		g.UnmakeMove()
+
+		e := <-ch
+		assert.Equal(t, chess.EventMove, e.Type)
+		assert.Equal(t, "e2e4", e.Move.UCI())
+		assert.Equal(t, 1, e.Ply)
+		assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", e.Snapshot.FEN())
+
+		e = <-ch
+		assert.Equal(t, chess.EventMove, e.Type)
+		assert.Equal(t, "e7e5", e.Move.UCI())
+		assert.Equal(t, 2, e.Ply)
+
+		e = <-ch
+		assert.Equal(t, chess.EventUndo, e.Type)
+		assert.Equal(t, "e7e5", e.Move.UCI())
+		assert.Equal(t, 1, e.Ply)
+		assert.Equal(t, 1, e.Snapshot.Ply())
+
+		cancel()
+		cancel()
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		g := newSyncGame(t)
+		require.NoError(t, g.MakeMove("e2e4"))
+		ch, cancel := g.Subscribe(8)
+		defer cancel()
+
+		errFailed := errors.New("failed")
+		err := g.Update(func(c *chess.Chess) error {
+			c.UnmakeMove()
+			require.NoError(t, c.MakeMove("d2d4"))
+			require.NoError(t, c.MakeMove("d7d5"))
+			return errFailed
+		})
+		assert.ErrorIs(t, err, errFailed)
+
+		var events []string
+		for range 3 {
+			e := <-ch
+			events = append(events, e.Type.String()+" "+e.Move.UCI())
+			assert.Equal(t, 2, e.Snapshot.Ply())
+		}
+
+		assert.Equal(t, []string{"undo e2e4", "move d2d4", "move d7d5"}, events)
+	})
+
+	t.Run("Slow subscriber", func(t *testing.T) {
+		g := newSyncGame(t)
+		ch, cancel := g.Subscribe(1)
+		defer cancel()
+
+		require.NoError(t, g.MakeMove("e2e4"))
+		require.NoError(t, g.MakeMove("e7e5"))
+
+		e, ok := <-ch
+		require.True(t, ok)
+		assert.Equal(t, "e2e4", e.Move.UCI())
+
+		_, ok = <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		g := newSyncGame(t)
+		ch, _ := g.Subscribe(1)
+
+		g.Close()
+		_, ok := <-ch
+		assert.False(t, ok)
+
+		ch, _ = g.Subscribe(1)
+		_, ok = <-ch
+		assert.False(t, ok)
+
+		require.NoError(t, g.MakeMove("e2e4"))
+		assert.Equal(t, 1, g.Snapshot().Ply())
+	})
+
+	t.Run("Event type string", func(t *testing.T) {
+		assert.Equal(t, "move", chess.EventMove.String())
+		assert.Equal(t, "undo", chess.EventUndo.String())
+		assert.Equal(t, "unknown", chess.GameEventType(0).String())
+	})
+}
+
+// TestSyncGame_Concurrent plays and undoes moves while other goroutines read
+// snapshots and receive events. Run it with the race detector.
+func TestSyncGame_Concurrent(t *testing.T) {
+	g := newSyncGame(t)
+	moves := []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5", "a7a6", "b5a4", "g8f6", "e1g1"}
+
+	const readers = 8
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				s := g.Snapshot()
+				assert.Len(t, s.History(), s.Ply())
+				assert.NotEmpty(t, s.FEN())
+				assert.NotEmpty(t, s.Moves())
+			}
+		}()
+	}
+
+	const rounds = 50
+	ch, cancel := g.Subscribe(2 * rounds * len(moves))
+	received := make(chan int)
+	go func() {
+		count := 0
+		ply := 0
+		for e := range ch {
+			if e.Type == chess.EventMove {
+				ply++
+			} else {
+				ply--
+			}
+			assert.Equal(t, ply, e.Ply)
+			count++
+		}
+		received <- count
+	}()
+
+	for range rounds {
+		for _, m := range moves {
+			require.NoError(t, g.MakeMove(m))
+		}
+
+		for range moves {
+			g.UnmakeMove()
+		}
+	}
+
+	close(done)
+	wg.Wait()
+	cancel()
+
+	assert.Equal(t, 2*rounds*len(moves), <-received)
+	assert.Equal(t, 0, g.Snapshot().Ply())
+}
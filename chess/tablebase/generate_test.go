@@ -0,0 +1,745 @@
+package tablebase
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+var update = flag.Bool("update", false, "regenerate the tables of the testdata directory")
+
+// fixtures are the tables of the testdata directory. The tables reached by
+// promotions are generated first.
+var fixtures = []string{"KRvK", "KQvK", "KPvK"}
+
+// TestGenerateFixtures generates the tables of the testdata directory by
+// retrograde analysis.
+//
+// The tables are written in the Syzygy format with recursive pairing, a
+// canonical Huffman code and a zeroed checksum. Run it with
+// `go test -run TestGenerateFixtures -update`.
+func TestGenerateFixtures(t *testing.T) {
+	if !*update {
+		t.Skip("run with -update to regenerate the fixtures")
+	}
+
+	for _, name := range fixtures {
+		tb, err := Open("testdata")
+		require.NoError(t, err)
+
+		g := newGenerator(t, tb, name)
+		g.solve()
+
+		wdl := g.file(false)
+		dtz := g.file(true)
+		require.NoError(t, os.WriteFile(filepath.Join("testdata", name+wdlExtension), wdl, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join("testdata", name+dtzExtension), dtz, 0o644))
+		require.NoError(t, tb.Close())
+
+		tb, err = Open("testdata")
+		require.NoError(t, err)
+		g.verify(tb)
+		require.NoError(t, tb.Close())
+	}
+}
+
+// successor is a move of a generated position.
+type successor struct {
+	// key is the key of the position after the move, or -1 if the move
+	// changes the material.
+	key int
+	// wdl is the result after a move that changes the material.
+	wdl WDL
+	// zeroing is true for captures and pawn moves.
+	zeroing bool
+}
+
+// generated is a legal position of a generated table.
+type generated struct {
+	pos   position
+	moves []successor
+	mated bool
+	wdl   WDL
+	// dtz is the distance to zeroing of wins and losses, or -1 while it is
+	// unknown.
+	dtz int
+}
+
+// generator generates the files of a table with one piece of every kind.
+type generator struct {
+	t  *testing.T
+	tb *Tablebase
+	// table has the pieces and the groups of the files.
+	table *table
+	// pieces are the pieces of the table in the order of the files: the
+	// pawn first, then the white pieces and the black king.
+	pieces []int
+	// positions are the legal positions by key.
+	positions map[int]*generated
+}
+
+// newGenerator enumerates the legal positions of a table and their moves.
+// The tables of the material reached by captures and promotions are probed
+// from tb.
+func newGenerator(t *testing.T, tb *Tablebase, name string) *generator {
+	t.Helper()
+
+	tbl, ok := newTable(name, "", false)
+	require.True(t, ok)
+
+	w, _, _ := strings.Cut(name, "v")
+	pieces := []int{white | king, black | king}
+	for _, letter := range w[1:] {
+		pt := slices.Index(pieceLetters[:], string(letter))
+		if pt == pawn {
+			pieces = append([]int{white | pawn}, pieces...)
+			continue
+		}
+
+		pieces = slices.Insert(pieces, 1, white|pt)
+	}
+
+	g := &generator{t: t, tb: tb, table: tbl, pieces: pieces, positions: make(map[int]*generated)}
+
+	files := 1
+	if tbl.hasPawns {
+		files = 4
+	}
+
+	for file := range files {
+		for side := range 2 {
+			d := &pairsData{}
+			copy(d.pieces[:], pieces)
+			require.NoError(t, d.setGroups(tbl, [2]int{0, 0xf}, file))
+			tbl.pairs[side][file] = d
+		}
+	}
+
+	g.enumerate(make([]int, 0, len(pieces)))
+	t.Logf("%s: %d positions", name, len(g.positions))
+	return g
+}
+
+// enumerate adds the legal positions with the pieces on the given squares
+// and the rest of the pieces anywhere.
+func (g *generator) enumerate(squares []int) {
+	if len(squares) == len(g.pieces) {
+		for _, turn := range []bool{false, true} {
+			g.add(squares, turn)
+		}
+		return
+	}
+
+	for s := range 64 {
+		if slices.Contains(squares, s) || g.pieces[len(squares)]&7 == pawn && (s < 8 || s >= 56) {
+			continue
+		}
+
+		g.enumerate(append(squares, s))
+	}
+}
+
+// key returns the key of the pieces on the given squares.
+func key(squares []int, black bool) int {
+	k := 0
+	for _, s := range squares {
+		k = k*64 + s
+	}
+
+	return 2*k + boolInt(black)
+}
+
+// add adds a position if it is legal.
+func (g *generator) add(squares []int, black bool) {
+	var pos position
+	for i, s := range squares {
+		pos.board[s] = g.pieces[i]
+	}
+	pos.black = black
+
+	game, err := chess.New(chess.WithParallelism(1), chess.WithFEN(positionFEN(&pos)))
+	if err != nil {
+		return
+	}
+
+	p := &generated{pos: pos, mated: game.IsCheckmate(), dtz: -1}
+	for _, m := range game.Moves() {
+		if m.IsCapture() || m.IsPromotion() {
+			require.NoError(g.t, game.PlayMove(m))
+			wdl, err := g.tb.ProbeWDL(game)
+			require.NoError(g.t, err)
+			game.UnmakeMove()
+
+			p.moves = append(p.moves, successor{key: -1, wdl: wdl, zeroing: true})
+			continue
+		}
+
+		next := slices.Clone(squares)
+		next[slices.Index(squares, gochess.BitIndex(m.From))] = gochess.BitIndex(m.To)
+		p.moves = append(p.moves, successor{
+			key:     key(next, !black),
+			zeroing: gochess.PieceType(m.Piece) == gochess.Pawn,
+		})
+	}
+
+	g.positions[key(squares, black)] = p
+}
+
+// solve computes the results and the distances to zeroing of the positions.
+func (g *generator) solve() {
+	const unknown = WDL(-3)
+	for _, p := range g.positions {
+		p.wdl = unknown
+		switch {
+		case p.mated:
+			p.wdl, p.dtz = Loss, 0
+		case len(p.moves) == 0:
+			p.wdl = Draw
+		}
+	}
+
+	// result returns the result after a move, or unknown.
+	result := func(s successor) WDL {
+		if s.key < 0 {
+			return s.wdl
+		}
+
+		return g.positions[s.key].wdl
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.positions {
+			if p.wdl != unknown {
+				continue
+			}
+
+			lost := true
+			for _, s := range p.moves {
+				r := result(s)
+				if r == Loss {
+					p.wdl, changed = Win, true
+					break
+				}
+
+				if r != Win {
+					lost = false
+				}
+			}
+
+			if p.wdl == unknown && lost {
+				p.wdl, changed = Loss, true
+			}
+		}
+	}
+
+	for _, p := range g.positions {
+		if p.wdl == unknown {
+			p.wdl = Draw
+		}
+	}
+
+	// The distances are computed by layers: the positions of a layer only
+	// depend on the positions of the previous ones.
+	for layer := 1; ; layer++ {
+		var solved []*generated
+		var pending bool
+		for _, p := range g.positions {
+			if p.wdl == Draw || p.dtz >= 0 {
+				continue
+			}
+
+			pending = true
+			if d, ok := g.distance(p); ok && d == layer {
+				solved = append(solved, p)
+			}
+		}
+
+		if !pending {
+			break
+		}
+
+		require.NotEmpty(g.t, solved, "positions without distance at layer %d", layer)
+		for _, p := range solved {
+			p.dtz = layer
+		}
+	}
+}
+
+// distance returns the distance to zeroing of a won or lost position, if
+// the distances of the positions it depends on are known.
+func (g *generator) distance(p *generated) (int, bool) {
+	// dist returns the distance of a move, or -1 if it is unknown.
+	dist := func(s successor) int {
+		if s.zeroing {
+			return 1
+		}
+
+		if next := g.positions[s.key]; next.dtz >= 0 {
+			return next.dtz + 1
+		}
+
+		return -1
+	}
+
+	if p.wdl == Win {
+		best := -1
+		for _, s := range p.moves {
+			if s.key >= 0 && g.positions[s.key].wdl != Loss || s.key < 0 && s.wdl != Loss {
+				continue
+			}
+
+			if d := dist(s); d > 0 && (best < 0 || d < best) {
+				best = d
+			}
+		}
+
+		return best, best > 0
+	}
+
+	worst := 0
+	for _, s := range p.moves {
+		d := dist(s)
+		if d < 0 {
+			return 0, false
+		}
+
+		worst = max(worst, d)
+	}
+
+	return worst, true
+}
+
+// verify checks that the positions probed from tb have the generated
+// results and distances.
+func (g *generator) verify(tb *Tablebase) {
+	for _, p := range g.positions {
+		game, err := chess.New(chess.WithParallelism(1), chess.WithFEN(positionFEN(&p.pos)))
+		require.NoError(g.t, err)
+
+		wdl, err := tb.ProbeWDL(game)
+		require.NoError(g.t, err)
+		require.Equal(g.t, p.wdl, wdl, positionFEN(&p.pos))
+
+		dtz, err := tb.ProbeDTZ(game)
+		require.NoError(g.t, err)
+
+		want := 0
+		switch {
+		case p.mated:
+		case p.wdl == Win:
+			want = p.dtz
+		case p.wdl == Loss:
+			want = -p.dtz
+		}
+		require.Equal(g.t, want, dtz, positionFEN(&p.pos))
+	}
+}
+
+// file returns the WDL or DTZ file of the table.
+func (g *generator) file(dtz bool) []byte {
+	magic, sides := wdlMagic, 2
+	if dtz {
+		magic, sides = dtzMagic, 1
+	}
+
+	files := 1
+	flags := byte(flagSplit)
+	if g.table.hasPawns {
+		files = 4
+		flags |= flagHasPawns
+	}
+
+	buf := append(magic[:], flags)
+	for range files {
+		// The leading group is encoded first.
+		buf = append(buf, 0)
+		for _, piece := range g.pieces {
+			buf = append(buf, byte(piece|piece<<4))
+		}
+	}
+
+	if len(buf)%2 != 0 {
+		buf = append(buf, 0)
+	}
+
+	var sizes, sparse, lengths, blocks [][]byte
+	for file := range files {
+		for side := range sides {
+			values := g.values(side, file, dtz)
+			pairsFlags := 0
+			if dtz {
+				pairsFlags = flagWinPlies | flagLossPlies
+			}
+
+			c := compress(values, pairsFlags)
+			sizes = append(sizes, c.sizes)
+			sparse = append(sparse, c.sparse)
+			lengths = append(lengths, c.lengths)
+			blocks = append(blocks, c.blocks)
+		}
+	}
+
+	for _, part := range slices.Concat(sizes, sparse, lengths) {
+		buf = append(buf, part...)
+	}
+
+	for _, part := range blocks {
+		buf = pad(buf, 64)
+		buf = append(buf, part...)
+	}
+
+	buf = pad(buf, 64)
+	return append(buf, make([]byte, 16)...)
+}
+
+// pad pads buf with zeros to a multiple of n bytes.
+func pad(buf []byte, n int) []byte {
+	for len(buf)%n != 0 {
+		buf = append(buf, 0)
+	}
+
+	return buf
+}
+
+// values returns the values of the positions of a side to move and a file,
+// in the order of the table, and -1 for the indexes without positions.
+func (g *generator) values(side, file int, dtz bool) []int {
+	d := g.table.pairs[side][file]
+	values := make([]int, d.groupIdx[d.groups()])
+	for i := range values {
+		values[i] = -1
+	}
+
+	for _, p := range g.positions {
+		pd, idx, _ := g.table.index(&p.pos, false)
+		if pd != d {
+			continue
+		}
+
+		v := int(p.wdl) + 2
+		if dtz {
+			if p.wdl == Draw {
+				continue
+			}
+			v = max(p.dtz-1, 0)
+		}
+
+		if values[idx] >= 0 && values[idx] != v {
+			g.t.Fatalf("positions with different values at index %d: %s", idx, positionFEN(&p.pos))
+		}
+
+		values[idx] = v
+	}
+
+	return values
+}
+
+// compressed are the parts of the data of a side to move and a file.
+type compressed struct {
+	sizes, sparse, lengths, blocks []byte
+}
+
+// Parameters of the compression.
+const (
+	blockBits = 6
+	spanBits  = 10
+	// maxPairs is the maximum number of pairs of symbols.
+	maxPairs = 64
+	// minPairCount is the minimum number of occurrences of a pair of
+	// symbols to replace it by a new symbol.
+	minPairCount = 16
+)
+
+// compress compresses values with recursive pairing and a canonical Huffman
+// code. The unknown values, which are -1, take the most frequent value.
+func compress(values []int, flags int) compressed {
+	freqs := make(map[int]int)
+	for _, v := range values {
+		if v >= 0 {
+			freqs[v]++
+		}
+	}
+
+	common := 0
+	for v, f := range freqs {
+		if f > freqs[common] || f == freqs[common] && v < common {
+			common = v
+		}
+	}
+
+	for i, v := range values {
+		if v < 0 {
+			values[i] = common
+			freqs[common]++
+		}
+	}
+
+	if len(freqs) == 1 {
+		return compressed{sizes: []byte{byte(flags | flagSingleValue), byte(common)}}
+	}
+
+	// The symbols are the values and the pairs of symbols that replace the
+	// most frequent pairs of adjacent symbols. The children of a value are
+	// the value and -1.
+	var children [][2]int
+	var sizes []int
+	leaves := make(map[int]int)
+	seq := make([]int, len(values))
+	for i, v := range values {
+		s, ok := leaves[v]
+		if !ok {
+			s = len(children)
+			leaves[v] = s
+			children = append(children, [2]int{v, -1})
+			sizes = append(sizes, 1)
+		}
+		seq[i] = s
+	}
+
+	for range maxPairs {
+		counts := make(map[[2]int]int)
+		for i := 1; i < len(seq); i++ {
+			counts[[2]int{seq[i-1], seq[i]}]++
+		}
+
+		var best [2]int
+		bestCount := minPairCount - 1
+		for p, n := range counts {
+			if n > bestCount || n == bestCount && (p[0] < best[0] || p[0] == best[0] && p[1] < best[1]) {
+				best, bestCount = p, n
+			}
+		}
+
+		if bestCount < minPairCount {
+			break
+		}
+
+		s := len(children)
+		children = append(children, best)
+		sizes = append(sizes, sizes[best[0]]+sizes[best[1]])
+
+		// The pair is replaced from left to right.
+		out := seq[:0]
+		for i := 0; i < len(seq); i++ {
+			if i+1 < len(seq) && seq[i] == best[0] && seq[i+1] == best[1] {
+				out = append(out, s)
+				i++
+				continue
+			}
+			out = append(out, seq[i])
+		}
+		seq = out
+	}
+
+	counts := make([]int, len(children))
+	for _, s := range seq {
+		counts[s]++
+	}
+
+	// The symbols are numbered by the length of their codes from the
+	// longest to the shortest.
+	lengths := codeLengths(counts)
+	symbols := make([]int, len(children))
+	for i := range symbols {
+		symbols[i] = i
+	}
+
+	slices.SortFunc(symbols, func(a, b int) int {
+		if lengths[a] != lengths[b] {
+			return lengths[b] - lengths[a]
+		}
+		return a - b
+	})
+
+	ids := make([]int, len(symbols))
+	for id, s := range symbols {
+		ids[s] = id
+	}
+
+	minLen, maxLen := lengths[symbols[len(symbols)-1]], lengths[symbols[0]]
+	lowest := make([]int, maxLen+1)
+	base := make([]int, maxLen+1)
+	next := 0
+	for l := maxLen; l >= minLen; l-- {
+		lowest[l] = next
+		for _, s := range symbols {
+			if lengths[s] == l {
+				next++
+			}
+		}
+
+		if l < maxLen {
+			base[l] = (base[l+1] + lowest[l] - lowest[l+1]) / 2
+		}
+	}
+
+	codes := make([][2]int, len(symbols))
+	for id, s := range symbols {
+		l := lengths[s]
+		codes[s] = [2]int{base[l] + id - lowest[l], l}
+	}
+
+	// Pack the codes in blocks. A symbol is not split between blocks.
+	var blocks []byte
+	var firsts, lens []int
+	var block []byte
+	var bits, count, pos int
+	flush := func() {
+		blocks = append(blocks, block...)
+		blocks = append(blocks, make([]byte, 1<<blockBits-len(block))...)
+		lens = append(lens, count-1)
+		block, bits, count = nil, 0, 0
+	}
+
+	for _, s := range seq {
+		code := codes[s]
+		if bits+code[1] > 8<<blockBits || count+sizes[s] > 1<<16 {
+			flush()
+		}
+
+		if count == 0 {
+			firsts = append(firsts, pos)
+		}
+
+		for b := code[1] - 1; b >= 0; b-- {
+			if bits%8 == 0 {
+				block = append(block, 0)
+			}
+
+			if code[0]>>b&1 != 0 {
+				block[bits/8] |= 0x80 >> (bits % 8)
+			}
+			bits++
+		}
+		count += sizes[s]
+		pos += sizes[s]
+	}
+	flush()
+
+	var c compressed
+	c.blocks = blocks
+
+	// The block lengths are padded with an entry for the sparse index
+	// entries beyond the last position.
+	numBlocks := len(lens)
+	for _, l := range append(lens, 0) {
+		c.lengths = binary.LittleEndian.AppendUint16(c.lengths, uint16(l))
+	}
+
+	span := 1 << spanBits
+	for k := 0; k*span < len(values); k++ {
+		i := k*span + span/2
+		b, offset := numBlocks, i-len(values)
+		if i < len(values) {
+			b, _ = slices.BinarySearch(firsts, i+1)
+			b--
+			offset = i - firsts[b]
+		}
+
+		c.sparse = binary.LittleEndian.AppendUint32(c.sparse, uint32(b))
+		c.sparse = binary.LittleEndian.AppendUint16(c.sparse, uint16(offset))
+	}
+
+	c.sizes = []byte{byte(flags), blockBits, spanBits, 1}
+	c.sizes = binary.LittleEndian.AppendUint32(c.sizes, uint32(numBlocks))
+	c.sizes = append(c.sizes, byte(maxLen), byte(minLen))
+	for l := minLen; l <= maxLen; l++ {
+		c.sizes = binary.LittleEndian.AppendUint16(c.sizes, uint16(lowest[l]))
+	}
+
+	// The leaves of the pairing tree hold a value and 0xfff.
+	c.sizes = binary.LittleEndian.AppendUint16(c.sizes, uint16(len(symbols)))
+	for _, s := range symbols {
+		left, right := children[s][0], 0xfff
+		if children[s][1] >= 0 {
+			left, right = ids[children[s][0]], ids[children[s][1]]
+		}
+
+		c.sizes = append(c.sizes, byte(left), byte(left>>8&0xf|right<<4), byte(right>>4))
+	}
+
+	if len(symbols)%2 != 0 {
+		c.sizes = append(c.sizes, 0)
+	}
+
+	return c
+}
+
+// codeLengths returns the lengths of the Huffman codes of symbols with the
+// given frequencies.
+func codeLengths(freqs []int) []int {
+	type node struct {
+		freq    int
+		symbols []int
+	}
+
+	nodes := make([]node, len(freqs))
+	for i, f := range freqs {
+		nodes[i] = node{freq: f, symbols: []int{i}}
+	}
+
+	lengths := make([]int, len(freqs))
+	for len(nodes) > 1 {
+		slices.SortStableFunc(nodes, func(a, b node) int { return a.freq - b.freq })
+		a, b := nodes[0], nodes[1]
+		for _, s := range slices.Concat(a.symbols, b.symbols) {
+			lengths[s]++
+		}
+
+		nodes = append(nodes[2:], node{freq: a.freq + b.freq, symbols: slices.Concat(a.symbols, b.symbols)})
+	}
+
+	return lengths
+}
+
+// positionFEN returns the FEN string of a position.
+func positionFEN(p *position) string {
+	var sb strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := range 8 {
+			piece := p.board[rank*8+file]
+			if piece == 0 {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				fmt.Fprint(&sb, empty)
+				empty = 0
+			}
+
+			letter := pieceLetters[piece&7]
+			if piece&black != 0 {
+				letter = strings.ToLower(letter)
+			}
+			sb.WriteString(letter)
+		}
+
+		if empty > 0 {
+			fmt.Fprint(&sb, empty)
+		}
+
+		if rank > 0 {
+			sb.WriteString("/")
+		}
+	}
+
+	turn := "w"
+	if p.black {
+		turn = "b"
+	}
+
+	return sb.String() + " " + turn + " - - 0 1"
+}
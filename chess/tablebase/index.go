@@ -0,0 +1,396 @@
+package tablebase
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// maxPieces is the maximum number of pieces of a table.
+const maxPieces = 7
+
+// Squares used by the encoding of the positions.
+const (
+	squareB1 = 1
+	squareD4 = 27
+)
+
+var (
+	// mapB1H1H7 maps the squares below the a1-h8 diagonal to 0..27.
+	mapB1H1H7 [64]int
+	// mapA1D1D4 maps the squares of the a1-d1-d4 triangle to 0..9. The
+	// squares of the diagonal are the last ones.
+	mapA1D1D4 [64]int
+	// mapKK maps the 462 placements of two kings that are legal and not
+	// mirrors of each other to 0..461. It is indexed by the mapA1D1D4 value
+	// of the first king, which is in the a1-d1-d4 triangle, and the square
+	// of the second one.
+	mapKK [10][64]int
+	// binomial holds the binomial coefficients: binomial[k][n] is the number
+	// of ways to choose k of n elements.
+	binomial [6][64]uint64
+	// mapPawns maps the squares of the ranks 2 to 7 to 0..47. The squares
+	// nearer to the edges and, in the same file, to the 2nd rank have higher
+	// values, so the leading pawn is the one with the highest value.
+	mapPawns [64]int
+	// leadPawnIdx is the index of the placements of the leading pawns,
+	// indexed by their count and the square of the leading one.
+	leadPawnIdx [6][64]uint64
+	// leadPawnsSize is the number of placements of the leading pawns,
+	// indexed by their count and the file of the leading one.
+	leadPawnsSize [6][4]uint64
+)
+
+func init() {
+	var code int
+	for s := range 64 {
+		if offDiagonal(s) < 0 {
+			mapB1H1H7[s] = code
+			code++
+		}
+	}
+
+	code = 0
+	var diagonal []int
+	for s := 0; s <= squareD4; s++ {
+		switch {
+		case offDiagonal(s) < 0 && s%8 <= 3:
+			mapA1D1D4[s] = code
+			code++
+		case offDiagonal(s) == 0 && s%8 <= 3:
+			diagonal = append(diagonal, s)
+		}
+	}
+
+	for _, s := range diagonal {
+		mapA1D1D4[s] = code
+		code++
+	}
+
+	// The placements with both kings on the diagonal are the last ones.
+	code = 0
+	var bothOnDiagonal [][2]int
+	for idx := range 10 {
+		for s1 := 0; s1 <= squareD4; s1++ {
+			if mapA1D1D4[s1] != idx || idx == 0 && s1 != squareB1 {
+				continue
+			}
+
+			for s2 := range 64 {
+				switch {
+				case distance(s1, s2) <= 1:
+				case offDiagonal(s1) == 0 && offDiagonal(s2) > 0:
+				case offDiagonal(s1) == 0 && offDiagonal(s2) == 0:
+					bothOnDiagonal = append(bothOnDiagonal, [2]int{idx, s2})
+				default:
+					mapKK[idx][s2] = code
+					code++
+				}
+			}
+		}
+	}
+
+	for _, p := range bothOnDiagonal {
+		mapKK[p[0]][p[1]] = code
+		code++
+	}
+
+	binomial[0][0] = 1
+	for n := 1; n < 64; n++ {
+		for k := 0; k < len(binomial) && k <= n; k++ {
+			if k > 0 {
+				binomial[k][n] += binomial[k-1][n-1]
+			}
+			if k < n {
+				binomial[k][n] += binomial[k][n-1]
+			}
+		}
+	}
+
+	available := 47
+	for n := 1; n < len(leadPawnIdx); n++ {
+		for file := range 4 {
+			var idx uint64
+			for rank := 1; rank <= 6; rank++ {
+				s := rank*8 + file
+				if n == 1 {
+					mapPawns[s] = available
+					mapPawns[s^7] = available - 1
+					available -= 2
+				}
+
+				leadPawnIdx[n][s] = idx
+				idx += binomial[n-1][mapPawns[s]]
+			}
+
+			leadPawnsSize[n][file] = idx
+		}
+	}
+}
+
+// offDiagonal returns the distance of a square to the a1-h8 diagonal:
+// negative below it and positive above it.
+func offDiagonal(s int) int {
+	return s/8 - s%8
+}
+
+// distance returns the number of king moves between two squares.
+func distance(s1, s2 int) int {
+	return max(abs(s1%8-s2%8), abs(s1/8-s2/8))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// Colors and pieces of the tables, which encode the pieces with the type in
+// the lower three bits and the color in the fourth one.
+const (
+	white      = 0
+	black      = 8
+	pawn       = 1
+	king       = 6
+	colorPiece = 8
+)
+
+// pieceLetters are the letters of the pieces in the names of the tables,
+// indexed by piece type.
+var pieceLetters = [...]string{"", "P", "N", "B", "R", "Q", "K"}
+
+// position is a position in the layout of the tables: the squares are the
+// gochess.BitIndex of the coordinates and the pieces are encoded as in the
+// tables.
+type position struct {
+	board [64]int
+	// black is true if black is to move.
+	black bool
+}
+
+// newPosition returns the position of a game.
+func newPosition(game *chess.Chess) position {
+	var p position
+	for _, color := range []gochess.Piece{gochess.White, gochess.Black} {
+		for pt := gochess.Pawn; pt <= gochess.King; pt++ {
+			bb := game.Pieces(color | pt)
+			for ; bb != 0; bb &= bb - 1 {
+				p.board[bitIndex(bb)] = tablePiece(color | pt)
+			}
+		}
+	}
+
+	p.black = game.Turn() == gochess.Black
+	return p
+}
+
+// tablePiece returns the table encoding of a colored piece.
+func tablePiece(p gochess.Piece) int {
+	piece := int(gochess.PieceType(p))
+	if gochess.PieceColor(p) == gochess.Black {
+		piece |= black
+	}
+
+	return piece
+}
+
+// material returns the pieces of white and black in the notation of the
+// table names (e.g. "KQ" and "K").
+func (p *position) material() (string, string) {
+	var counts [16]int
+	for _, piece := range p.board {
+		counts[piece]++
+	}
+
+	var w, b strings.Builder
+	for pt := king; pt >= pawn; pt-- {
+		w.WriteString(strings.Repeat(pieceLetters[pt], counts[white|pt]))
+		b.WriteString(strings.Repeat(pieceLetters[pt], counts[black|pt]))
+	}
+
+	return w.String(), b.String()
+}
+
+// index returns the pairs data of a position in the table and the index of
+// the position in it. The position is flipped when its colors are the
+// opposite of the ones of the table name (e.g. a KvKQ position in KQvK).
+//
+// It returns false if the table does not store the side to move of the
+// position, which only happens in DTZ tables.
+func (t *table) index(p *position, flipped bool) (*pairsData, uint64, bool) {
+	flip := flipped || p.black && t.symmetric
+	var flipColor, flipSquares int
+	if flip {
+		flipColor, flipSquares = colorPiece, 56
+	}
+
+	stm := 0
+	if p.black != flip {
+		stm = 1
+	}
+
+	var squares, pieces [maxPieces]int
+	var size, leadPawnsCount, file int
+	var leadPawns uint64
+
+	// The tables of positions with pawns are split by the file of the
+	// leading pawn, which is the one with the highest mapPawns value.
+	if t.hasPawns {
+		lead := t.pairs[0][0].pieces[0] ^ flipColor
+		for s, piece := range p.board {
+			if piece == lead {
+				leadPawns |= 1 << s
+				squares[size] = s ^ flipSquares
+				size++
+			}
+		}
+
+		leadPawnsCount = size
+		for i := 1; i < leadPawnsCount; i++ {
+			if mapPawns[squares[i]] > mapPawns[squares[0]] {
+				squares[0], squares[i] = squares[i], squares[0]
+			}
+		}
+
+		file = min(squares[0]%8, 7-squares[0]%8)
+	}
+
+	if t.dtz && !t.storesSide(stm, file) {
+		return nil, 0, false
+	}
+
+	for s, piece := range p.board {
+		if piece == 0 || leadPawns&(1<<s) != 0 {
+			continue
+		}
+
+		squares[size] = s ^ flipSquares
+		pieces[size] = piece ^ flipColor
+		size++
+	}
+
+	d := t.get(stm, file)
+
+	// Sort the pieces in the order of the table.
+	for i := leadPawnsCount; i < size-1; i++ {
+		for j := i + 1; j < size; j++ {
+			if d.pieces[i] == pieces[j] {
+				pieces[i], pieces[j] = pieces[j], pieces[i]
+				squares[i], squares[j] = squares[j], squares[i]
+				break
+			}
+		}
+	}
+
+	// Mirror the board so the leading piece is in the files a to d.
+	if squares[0]%8 > 3 {
+		for i := range size {
+			squares[i] ^= 7
+		}
+	}
+
+	var idx uint64
+	if t.hasPawns {
+		idx = leadPawnIdx[leadPawnsCount][squares[0]]
+		lead := squares[1:leadPawnsCount]
+		slices.SortStableFunc(lead, func(a, b int) int {
+			return mapPawns[a] - mapPawns[b]
+		})
+
+		for i, s := range lead {
+			idx += binomial[i+1][mapPawns[s]]
+		}
+	} else {
+		idx = t.pieceIndex(d, squares[:size])
+	}
+
+	idx *= d.groupIdx[0]
+
+	// Encode the rest of the groups, mapping the squares down to skip the
+	// squares of the previous groups.
+	remainingPawns := t.hasPawns && t.pawnCount[1] > 0
+	start := d.groupLen[0]
+	for next := 1; d.groupLen[next] != 0; next++ {
+		group := squares[start : start+d.groupLen[next]]
+		slices.Sort(group)
+
+		var n uint64
+		for i, s := range group {
+			adjust := 0
+			for _, prev := range squares[:start] {
+				if s > prev {
+					adjust++
+				}
+			}
+
+			if remainingPawns {
+				adjust += 8
+			}
+
+			n += binomial[i+1][s-adjust]
+		}
+
+		remainingPawns = false
+		idx += n * d.groupIdx[next]
+		start += d.groupLen[next]
+	}
+
+	return d, idx, true
+}
+
+// pieceIndex returns the index of the leading group of a position without
+// pawns. The squares are mirrored so the leading piece is in the a1-d1-d4
+// triangle and the first piece of the group out of the diagonal is below
+// it.
+func (t *table) pieceIndex(d *pairsData, squares []int) uint64 {
+	if squares[0]/8 > 3 {
+		for i := range squares {
+			squares[i] ^= 56
+		}
+	}
+
+	for i := range d.groupLen[0] {
+		if offDiagonal(squares[i]) == 0 {
+			continue
+		}
+
+		if offDiagonal(squares[i]) > 0 {
+			for j := i; j < len(squares); j++ {
+				squares[j] = (squares[j]>>3 | squares[j]<<3) & 63
+			}
+		}
+		break
+	}
+
+	if !t.hasUniquePieces {
+		return uint64(mapKK[mapA1D1D4[squares[0]]][squares[1]])
+	}
+
+	// The three unique pieces are encoded together.
+	s0, s1, s2 := squares[0], squares[1], squares[2]
+	adjust1 := boolInt(s1 > s0)
+	adjust2 := boolInt(s2 > s0) + boolInt(s2 > s1)
+
+	switch {
+	case offDiagonal(s0) != 0:
+		return uint64((mapA1D1D4[s0]*63+s1-adjust1)*62 + s2 - adjust2)
+	case offDiagonal(s1) != 0:
+		return uint64((6*63+s0/8*28+mapB1H1H7[s1])*62 + s2 - adjust2)
+	case offDiagonal(s2) != 0:
+		return uint64(6*63*62 + 4*28*62 + s0/8*7*28 + (s1/8-adjust1)*28 + mapB1H1H7[s2])
+	default:
+		return uint64(6*63*62 + 4*28*62 + 4*7*28 + s0/8*7*6 + (s1/8-adjust1)*6 + s2/8 - adjust2)
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
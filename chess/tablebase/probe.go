@@ -0,0 +1,244 @@
+package tablebase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/RchrdHndrcks/gochess/v2"
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// prober probes the positions reached from a copy of a position.
+type prober struct {
+	tb   *Tablebase
+	game *chess.Chess
+}
+
+// newProber returns a prober of the current position of a game and the
+// fifty-move counter of the position.
+//
+// The copy has no history and a zeroed counter, so its moves can't end the
+// game by the seventy-five-move rule or a repetition.
+func (tb *Tablebase) newProber(game *chess.Chess) (*prober, int, error) {
+	fields := strings.Fields(game.FEN())
+	if len(fields) != 6 {
+		return nil, 0, fmt.Errorf("invalid position: %s", game.FEN())
+	}
+
+	if fields[2] != "-" {
+		return nil, 0, ErrCastling
+	}
+
+	halfMoves, err := strconv.Atoi(fields[4])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid position: %s", game.FEN())
+	}
+
+	fields[4], fields[5] = "0", "1"
+	g, err := chess.New(chess.WithParallelism(1), chess.WithFEN(strings.Join(fields, " ")))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to copy the position: %w", err)
+	}
+
+	return &prober{tb: tb, game: g}, halfMoves, nil
+}
+
+// search returns the result of the position. It also returns true if the
+// best move is a zeroing move, in which case the DTZ tables can't be probed.
+//
+// The tables may store any value in the positions where the side to move
+// wins with a capture, and a loss in the ones where it draws with a capture,
+// so the captures are searched too. The positions with en passant rights are
+// stored without them, which the search of the captures also corrects. When
+// zeroing is true, the pawn moves are searched too.
+func (p *prober) search(zeroing bool) (WDL, bool, error) {
+	// The positions with insufficient material are draws, and no moves can
+	// be played in them.
+	if p.game.IsInsufficientMaterial() {
+		return Draw, false, nil
+	}
+
+	moves := p.game.Moves()
+	best, count := Loss, 0
+	for _, m := range moves {
+		if !m.IsCapture() && (!zeroing || gochess.PieceType(m.Piece) != gochess.Pawn) {
+			continue
+		}
+
+		count++
+		if err := p.game.PlayMove(m); err != nil {
+			return Draw, false, err
+		}
+
+		v, _, err := p.search(false)
+		p.game.UnmakeMove()
+		if err != nil {
+			return Draw, false, err
+		}
+
+		if -v > best {
+			best = -v
+			if best == Win {
+				return Win, true, nil
+			}
+		}
+	}
+
+	// When every move was searched, the stored value is not needed.
+	all := count > 0 && count == len(moves)
+	value := best
+	if !all {
+		v, err := p.probeWDL()
+		if err != nil {
+			return Draw, false, err
+		}
+		value = v
+	}
+
+	if best >= value {
+		return best, best > Draw || all, nil
+	}
+
+	return value, false, nil
+}
+
+// dtz returns the distance to zeroing of the position.
+func (p *prober) dtz() (int, error) {
+	wdl, zeroing, err := p.search(true)
+	if err != nil || wdl == Draw {
+		return 0, err
+	}
+
+	if zeroing {
+		return dtzBeforeZeroing(wdl), nil
+	}
+
+	dtz, ok, err := p.probeDTZ(wdl)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		if wdl == CursedWin || wdl == BlessedLoss {
+			dtz += 100
+		}
+
+		return dtz * sign(int(wdl)), nil
+	}
+
+	// The table stores the other side to move, so the distance is the best
+	// one of the moves.
+	best := 0xffff
+	for _, m := range p.game.Moves() {
+		d, err := p.rootDTZ(m)
+		if err != nil {
+			return 0, err
+		}
+
+		if d < best && sign(d) == sign(int(wdl)) {
+			best = d
+		}
+	}
+
+	// Without legal moves the position is a checkmate.
+	if best == 0xffff {
+		return -1, nil
+	}
+
+	return best, nil
+}
+
+// rootDTZ returns the distance to zeroing after a move for the side that
+// plays it, counting the move.
+func (p *prober) rootDTZ(m chess.Move) (int, error) {
+	if err := p.game.PlayMove(m); err != nil {
+		return 0, err
+	}
+	defer p.game.UnmakeMove()
+
+	// The distance of zeroing moves only depends on the result after them.
+	if m.IsCapture() || gochess.PieceType(m.Piece) == gochess.Pawn {
+		wdl, _, err := p.search(false)
+		return dtzBeforeZeroing(-wdl), err
+	}
+
+	if p.game.IsCheckmate() {
+		return 1, nil
+	}
+
+	dtz, err := p.dtz()
+	if err != nil {
+		return 0, err
+	}
+
+	return -dtz - sign(dtz), nil
+}
+
+// probeWDL returns the result of the position stored in the WDL table.
+func (p *prober) probeWDL() (WDL, error) {
+	pos := newPosition(p.game)
+	t, flipped, err := p.tb.table(p.tb.wdl, &pos)
+	if err != nil {
+		return Draw, err
+	}
+
+	d, idx, _ := t.index(&pos, flipped)
+	v, err := t.decompress(d, idx)
+	if err != nil {
+		return Draw, err
+	}
+
+	return WDL(v - 2), nil
+}
+
+// probeDTZ returns the distance to zeroing of the position stored in the DTZ
+// table, given its result. It returns false if the table stores the other
+// side to move.
+func (p *prober) probeDTZ(wdl WDL) (int, bool, error) {
+	pos := newPosition(p.game)
+	t, flipped, err := p.tb.table(p.tb.dtz, &pos)
+	if err != nil {
+		return 0, false, err
+	}
+
+	d, idx, ok := t.index(&pos, flipped)
+	if !ok {
+		return 0, false, nil
+	}
+
+	v, err := t.decompress(d, idx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return t.mapScore(d, v, wdl), true, nil
+}
+
+// dtzBeforeZeroing returns the distance to zeroing of a position whose best
+// move is a zeroing move with the given result.
+func dtzBeforeZeroing(wdl WDL) int {
+	switch wdl {
+	case Win:
+		return 1
+	case CursedWin:
+		return 101
+	case BlessedLoss:
+		return -101
+	case Loss:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
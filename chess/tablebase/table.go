@@ -0,0 +1,713 @@
+package tablebase
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Magic numbers of the table files.
+var (
+	wdlMagic = [4]byte{0x71, 0xe8, 0x23, 0x5d}
+	dtzMagic = [4]byte{0xd7, 0x66, 0x0c, 0xa5}
+)
+
+// Flags of the tables.
+const (
+	flagSplit    = 1
+	flagHasPawns = 2
+)
+
+// Flags of the pairs data.
+const (
+	// flagSTM is the side to move stored by a DTZ table.
+	flagSTM = 1
+	// flagMapped is set when the DTZ values are mapped.
+	flagMapped = 2
+	// flagWinPlies is set when the DTZ values of wins are stored in plies
+	// instead of moves.
+	flagWinPlies = 4
+	// flagLossPlies is set when the DTZ values of losses are stored in
+	// plies instead of moves.
+	flagLossPlies = 8
+	// flagWide is set when the DTZ map has 16-bit values.
+	flagWide = 16
+	// flagSingleValue is set when all the positions have the same value.
+	flagSingleValue = 128
+)
+
+// errCorrupted is returned when a table file is not valid.
+var errCorrupted = errors.New("corrupted table")
+
+// pairsData is the data of the positions of a table with a side to move
+// and, in tables with pawns, a file of the leading pawn.
+//
+// The values are compressed with recursive pairing, which replaces the most
+// frequent pairs of adjacent symbols by new symbols, and a canonical Huffman
+// code. They are stored in blocks of a fixed size.
+type pairsData struct {
+	flags int
+	// pieces are the pieces of the table in the order of the encoding.
+	pieces [maxPieces]int
+	// groupLen is the number of pieces of every group of equal pieces,
+	// ending with 0.
+	groupLen [maxPieces + 1]int
+	// groupIdx is the factor of the index of every group. The last one is
+	// the number of positions.
+	groupIdx [maxPieces + 1]uint64
+
+	// singleValue is the value of all the positions if flagSingleValue is
+	// set.
+	singleValue int
+	blockSize   int64
+	// span is the number of positions between the entries of sparseIndex.
+	span uint64
+	// numBlocks is the number of blocks of data.
+	numBlocks int
+	// minSymLen is the length in bits of the shortest symbols.
+	minSymLen int
+	// lowestSym is the lowest symbol of every length from minSymLen.
+	lowestSym []uint16
+	// base64 is the lowest code of every length from minSymLen, padded to
+	// 64 bits.
+	base64 []uint64
+	// symLen is the number of values minus one every symbol expands to.
+	symLen []int
+	// btree holds the left and right symbols every symbol expands to, in
+	// three bytes by symbol.
+	btree []byte
+	// sparseIndex holds, every span positions, the block and the offset in
+	// it of the position, in six bytes by entry.
+	sparseIndex []byte
+	// blockLength is the number of positions minus one of every block.
+	blockLength []uint16
+	// data is the offset of the first block in the file.
+	data int64
+	// mapIdx is the offset in the DTZ map of the values of wins, losses,
+	// cursed wins and blessed losses.
+	mapIdx [4]int
+}
+
+// table is a WDL or DTZ table file.
+type table struct {
+	// name is the name of the table (e.g. "KQvK").
+	name string
+	path string
+	dtz  bool
+
+	// symmetric is true when both sides have the same pieces.
+	symmetric       bool
+	hasPawns        bool
+	hasUniquePieces bool
+	pieceCount      int
+	// pawnCount is the number of pawns of the leading color and of the
+	// other one.
+	pawnCount [2]int
+
+	once sync.Once
+	err  error
+	file *os.File
+	size int64
+	// pairs are the pairs data indexed by side to move and file.
+	pairs [2][4]*pairsData
+	// dtzMap holds the values of mapped DTZ tables.
+	dtzMap []byte
+}
+
+// newTable returns the table of a file. It returns false if the name is not
+// the name of a table.
+func newTable(name, path string, dtz bool) (*table, bool) {
+	w, b, ok := strings.Cut(name, "v")
+	if !ok || !validMaterial(w) || !validMaterial(b) || len(w)+len(b) > maxPieces {
+		return nil, false
+	}
+
+	t := &table{
+		name:       name,
+		path:       path,
+		dtz:        dtz,
+		symmetric:  w == b,
+		pieceCount: len(w) + len(b),
+	}
+
+	for _, side := range []string{w, b} {
+		for _, letter := range pieceLetters[pawn:king] {
+			if strings.Count(side, letter) == 1 {
+				t.hasUniquePieces = true
+			}
+		}
+	}
+
+	// The leading color is the one with fewer pawns, but at least one.
+	wp, bp := strings.Count(w, "P"), strings.Count(b, "P")
+	t.hasPawns = wp+bp > 0
+	t.pawnCount = [2]int{wp, bp}
+	if wp == 0 || bp > 0 && bp < wp {
+		t.pawnCount = [2]int{bp, wp}
+	}
+
+	return t, true
+}
+
+// validMaterial returns true if a side of a table name is a king followed
+// by other pieces.
+func validMaterial(side string) bool {
+	if !strings.HasPrefix(side, "K") {
+		return false
+	}
+
+	return strings.Trim(side[1:], "QRBNP") == ""
+}
+
+// get returns the pairs data of a side to move and a file.
+func (t *table) get(stm, file int) *pairsData {
+	if t.dtz || t.symmetric {
+		stm = 0
+	}
+
+	if !t.hasPawns {
+		file = 0
+	}
+
+	return t.pairs[stm][file]
+}
+
+// storesSide returns true if a DTZ table stores the positions of a side to
+// move.
+func (t *table) storesSide(stm, file int) bool {
+	return t.get(0, file).flags&flagSTM == stm || t.symmetric && !t.hasPawns
+}
+
+// load opens and reads the headers of the table file the first time it is
+// called.
+func (t *table) load() error {
+	t.once.Do(func() {
+		t.err = t.open()
+		if t.err != nil {
+			t.err = fmt.Errorf("failed to load table %s: %w", t.name, t.err)
+		}
+	})
+
+	return t.err
+}
+
+// close closes the table file, if it was opened.
+func (t *table) close() error {
+	if t.file == nil {
+		return nil
+	}
+
+	return t.file.Close()
+}
+
+// open opens the table file and reads its headers.
+func (t *table) open() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	t.file, t.size = f, info.Size()
+	if err := t.readHeaders(); err != nil {
+		f.Close()
+		t.file = nil
+		return err
+	}
+
+	return nil
+}
+
+// readHeaders reads the headers of the table file: the pieces, the
+// compression parameters and the indexes of the blocks.
+func (t *table) readHeaders() error {
+	// The files end with a 16-byte checksum after the 64-byte aligned data.
+	if t.size%64 != 16 {
+		return errCorrupted
+	}
+
+	r := &reader{r: t.file, size: t.size}
+	magic := wdlMagic
+	if t.dtz {
+		magic = dtzMagic
+	}
+
+	if [4]byte(r.bytes(4)) != magic {
+		return fmt.Errorf("%w: invalid magic number", errCorrupted)
+	}
+
+	flags := r.byte()
+	if flags&flagSplit != 0 == t.symmetric || flags&flagHasPawns != 0 != t.hasPawns {
+		return fmt.Errorf("%w: flags do not match the pieces", errCorrupted)
+	}
+
+	sides := 2
+	if t.dtz || t.symmetric {
+		sides = 1
+	}
+
+	files := 1
+	if t.hasPawns {
+		files = 4
+	}
+
+	pp := t.hasPawns && t.pawnCount[1] > 0
+	for file := range files {
+		order := [2][2]int{{0, 0xf}, {0, 0xf}}
+		b := r.byte()
+		order[0][0], order[1][0] = b&0xf, b>>4
+		if pp {
+			b := r.byte()
+			order[0][1], order[1][1] = b&0xf, b>>4
+		}
+
+		for i := range sides {
+			t.pairs[i][file] = &pairsData{}
+		}
+
+		for k := range t.pieceCount {
+			b := r.byte()
+			t.pairs[0][file].pieces[k] = b & 0xf
+			if sides == 2 {
+				t.pairs[1][file].pieces[k] = b >> 4
+			}
+		}
+
+		for i := range sides {
+			if err := t.pairs[i][file].setGroups(t, order[i], file); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.align(2)
+	for file := range files {
+		for i := range sides {
+			t.pairs[i][file].readSizes(r)
+		}
+	}
+
+	if t.dtz {
+		t.readMap(r, files)
+	}
+
+	for file := range files {
+		for i := range sides {
+			d := t.pairs[i][file]
+			n := 0
+			if d.flags&flagSingleValue == 0 {
+				n = int((d.groupIdx[d.groups()] + d.span - 1) / d.span)
+			}
+
+			d.sparseIndex = r.bytes(6 * n)
+		}
+	}
+
+	for file := range files {
+		for i := range sides {
+			d := t.pairs[i][file]
+			d.blockLength = r.uint16s(len(d.blockLength))
+		}
+	}
+
+	for file := range files {
+		for i := range sides {
+			d := t.pairs[i][file]
+			r.align(64)
+			d.data = r.off
+			r.off += int64(d.numBlocks) * d.blockSize
+		}
+	}
+
+	if r.err != nil || r.off > t.size {
+		return errCorrupted
+	}
+
+	return nil
+}
+
+// setGroups sets the groups of the pieces of the pairs data and their
+// factors in the index. order are the positions in the encoding of the
+// leading group and of the pawns of the other color.
+func (d *pairsData) setGroups(t *table, order [2]int, file int) error {
+	firstLen := 2
+	switch {
+	case t.hasPawns:
+		firstLen = 0
+	case t.hasUniquePieces:
+		firstLen = 3
+	}
+
+	n := 0
+	d.groupLen[0] = 1
+	for i := 1; i < t.pieceCount; i++ {
+		firstLen--
+		if firstLen > 0 || d.pieces[i] == d.pieces[i-1] {
+			d.groupLen[n]++
+		} else {
+			n++
+			d.groupLen[n] = 1
+		}
+	}
+
+	n++
+	d.groupLen[n] = 0
+
+	pp := t.hasPawns && t.pawnCount[1] > 0
+	next := 1
+	free := 64 - d.groupLen[0]
+	if pp {
+		next = 2
+		free -= d.groupLen[1]
+	}
+
+	idx := uint64(1)
+	for k := 0; next < n || k == order[0] || k == order[1]; k++ {
+		switch {
+		case k == order[0]:
+			d.groupIdx[0] = idx
+			switch {
+			case t.hasPawns:
+				idx *= leadPawnsSize[d.groupLen[0]][file]
+			case t.hasUniquePieces:
+				idx *= 31332
+			default:
+				idx *= 462
+			}
+		case k == order[1]:
+			d.groupIdx[1] = idx
+			idx *= binomial[d.groupLen[1]][48-d.groupLen[0]]
+		default:
+			if next >= n || d.groupLen[next] >= len(binomial) {
+				return fmt.Errorf("%w: invalid piece order", errCorrupted)
+			}
+
+			d.groupIdx[next] = idx
+			idx *= binomial[d.groupLen[next]][free]
+			free -= d.groupLen[next]
+			next++
+		}
+	}
+
+	d.groupIdx[n] = idx
+	return nil
+}
+
+// groups returns the number of groups of the pairs data.
+func (d *pairsData) groups() int {
+	n := 0
+	for d.groupLen[n] != 0 {
+		n++
+	}
+
+	return n
+}
+
+// readSizes reads the compression parameters of the pairs data.
+func (d *pairsData) readSizes(r *reader) {
+	d.flags = r.byte()
+	if d.flags&flagSingleValue != 0 {
+		d.singleValue = r.byte()
+		d.span = 1
+		return
+	}
+
+	blockBits, spanBits, padding := r.byte(), r.byte(), r.byte()
+	d.numBlocks = int(r.uint32())
+	maxSymLen := r.byte()
+	d.minSymLen = r.byte()
+	if blockBits > 30 || spanBits > 30 {
+		r.err = errCorrupted
+		return
+	}
+
+	d.blockSize = 1 << blockBits
+	d.span = 1 << spanBits
+	if int64(d.numBlocks)*d.blockSize > r.size || maxSymLen < d.minSymLen || maxSymLen > 32 {
+		r.err = errCorrupted
+		return
+	}
+
+	d.blockLength = make([]uint16, d.numBlocks+padding)
+	d.lowestSym = r.uint16s(maxSymLen - d.minSymLen + 1)
+
+	// The canonical Huffman codes of a length are consecutive and longer
+	// codes have lower values, so base64[i] is the lowest code of length
+	// minSymLen+i padded to 64 bits and the codes of that length are in
+	// [base64[i], base64[i-1]).
+	d.base64 = make([]uint64, len(d.lowestSym))
+	for i := len(d.base64) - 2; i >= 0; i-- {
+		d.base64[i] = (d.base64[i+1] + uint64(d.lowestSym[i]) - uint64(d.lowestSym[i+1])) / 2
+	}
+
+	for i := range d.base64 {
+		d.base64[i] <<= 64 - i - d.minSymLen
+	}
+
+	numSyms := int(r.uint16())
+	d.btree = r.bytes(3 * numSyms)
+	if r.err != nil {
+		return
+	}
+
+	d.symLen = make([]int, numSyms)
+	visited := make([]bool, numSyms)
+	for s := range numSyms {
+		if !visited[s] && !d.setSymLen(s, visited) {
+			r.err = errCorrupted
+			return
+		}
+	}
+
+	r.off += int64(numSyms & 1)
+}
+
+// setSymLen sets the number of values a symbol expands to. It returns false
+// if the symbols are not valid.
+func (d *pairsData) setSymLen(s int, visited []bool) bool {
+	visited[s] = true
+	left, right := d.children(s)
+	if right == 0xfff {
+		return true
+	}
+
+	for _, child := range []int{left, right} {
+		if child >= len(d.symLen) {
+			return false
+		}
+
+		if !visited[child] && !d.setSymLen(child, visited) {
+			return false
+		}
+	}
+
+	d.symLen[s] = d.symLen[left] + d.symLen[right] + 1
+	return true
+}
+
+// children returns the left and right symbols a symbol expands to. The right
+// symbol is 0xfff and the left one is the value when the symbol is a leaf.
+func (d *pairsData) children(s int) (int, int) {
+	w := d.btree[3*s : 3*s+3]
+	return int(w[1]&0xf)<<8 | int(w[0]), int(w[2])<<4 | int(w[1]>>4)
+}
+
+// readMap reads the map of the values of a DTZ table.
+func (t *table) readMap(r *reader, files int) {
+	start := r.off
+	for file := range files {
+		d := t.pairs[0][file]
+		if d.flags&flagMapped == 0 {
+			continue
+		}
+
+		if d.flags&flagWide != 0 {
+			r.align(2)
+			for i := range d.mapIdx {
+				d.mapIdx[i] = int(r.off-start) + 2
+				r.off += 2 * int64(r.uint16())
+			}
+			continue
+		}
+
+		for i := range d.mapIdx {
+			d.mapIdx[i] = int(r.off-start) + 1
+			r.off += int64(r.byte())
+		}
+	}
+
+	end := r.off
+	r.off = start
+	t.dtzMap = r.bytes(int(end - start))
+	r.align(2)
+}
+
+// decompress returns the value of the position with the given index.
+func (t *table) decompress(d *pairsData, idx uint64) (int, error) {
+	if d.flags&flagSingleValue != 0 {
+		return d.singleValue, nil
+	}
+
+	// The sparse index holds the block and the offset in it of the
+	// positions k*span + span/2, so the block of the position is found
+	// from the nearest entry moving by whole blocks.
+	k := idx / d.span
+	if int(6*k+6) > len(d.sparseIndex) {
+		return 0, errCorrupted
+	}
+
+	entry := d.sparseIndex[6*k : 6*k+6]
+	block := int(binary.LittleEndian.Uint32(entry))
+	offset := int(binary.LittleEndian.Uint16(entry[4:])) + int(idx%d.span) - int(d.span/2)
+
+	for offset < 0 {
+		block--
+		if block < 0 {
+			return 0, errCorrupted
+		}
+		offset += int(d.blockLength[block]) + 1
+	}
+
+	for block < len(d.blockLength) && offset > int(d.blockLength[block]) {
+		offset -= int(d.blockLength[block]) + 1
+		block++
+	}
+
+	if block >= d.numBlocks {
+		return 0, errCorrupted
+	}
+
+	// The codes can be read beyond the block while they are refilled.
+	buf := make([]byte, d.blockSize+8)
+	n, err := t.file.ReadAt(buf, d.data+int64(block)*d.blockSize)
+	if err != nil && (!errors.Is(err, io.EOF) || int64(n) < d.blockSize) {
+		return 0, fmt.Errorf("failed to read table %s: %w", t.name, err)
+	}
+
+	// Find the symbol that holds the value of the offset.
+	buf64 := binary.BigEndian.Uint64(buf)
+	bufSize := 64
+	next := 8
+	var sym int
+	for {
+		l := 0
+		for buf64 < d.base64[l] {
+			l++
+		}
+
+		sym = int((buf64-d.base64[l])>>(64-l-d.minSymLen)) + int(d.lowestSym[l])
+		if sym >= len(d.symLen) {
+			return 0, errCorrupted
+		}
+
+		if offset < d.symLen[sym]+1 {
+			break
+		}
+
+		offset -= d.symLen[sym] + 1
+		l += d.minSymLen
+		buf64 <<= l
+		bufSize -= l
+
+		if bufSize <= 32 {
+			if next+4 > len(buf) {
+				return 0, errCorrupted
+			}
+
+			bufSize += 32
+			buf64 |= uint64(binary.BigEndian.Uint32(buf[next:])) << (64 - bufSize)
+			next += 4
+		}
+	}
+
+	// Expand the symbol to the value of the offset.
+	for d.symLen[sym] != 0 {
+		left, right := d.children(sym)
+		if offset < d.symLen[left]+1 {
+			sym = left
+		} else {
+			offset -= d.symLen[left] + 1
+			sym = right
+		}
+	}
+
+	value, _ := d.children(sym)
+	return value, nil
+}
+
+// mapScore returns the DTZ in plies of a value of a DTZ table.
+func (t *table) mapScore(d *pairsData, value int, wdl WDL) int {
+	if d.flags&flagMapped != 0 {
+		// The map offsets are indexed by win, loss, cursed win and blessed
+		// loss.
+		i := d.mapIdx[[...]int{1, 3, 0, 2, 0}[wdl-Loss]]
+		if d.flags&flagWide != 0 {
+			value = int(binary.LittleEndian.Uint16(t.dtzMap[i+2*value:]))
+		} else {
+			value = int(t.dtzMap[i+value])
+		}
+	}
+
+	if wdl == Win && d.flags&flagWinPlies == 0 ||
+		wdl == Loss && d.flags&flagLossPlies == 0 ||
+		wdl == CursedWin || wdl == BlessedLoss {
+		value *= 2
+	}
+
+	return value + 1
+}
+
+// reader reads the headers of a table file. It stops reading after the
+// first error.
+type reader struct {
+	r    io.ReaderAt
+	size int64
+	off  int64
+	err  error
+}
+
+// bytes reads n bytes.
+func (r *reader) bytes(n int) []byte {
+	buf := make([]byte, n)
+	if r.err != nil {
+		return buf
+	}
+
+	if n < 0 || r.off+int64(n) > r.size {
+		r.err = errCorrupted
+		return make([]byte, max(n, 0))
+	}
+
+	if _, err := r.r.ReadAt(buf, r.off); err != nil {
+		r.err = err
+	}
+
+	r.off += int64(n)
+	return buf
+}
+
+// byte reads a byte.
+func (r *reader) byte() int {
+	return int(r.bytes(1)[0])
+}
+
+// uint16 reads a little endian 16-bit number.
+func (r *reader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.bytes(2))
+}
+
+// uint32 reads a little endian 32-bit number.
+func (r *reader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+// uint16s reads n little endian 16-bit numbers.
+func (r *reader) uint16s(n int) []uint16 {
+	buf := r.bytes(2 * n)
+	values := make([]uint16, n)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint16(buf[2*i:])
+	}
+
+	return values
+}
+
+// align moves the offset to the next multiple of n.
+func (r *reader) align(n int64) {
+	r.off = (r.off + n - 1) / n * n
+}
+
+// bitIndex returns the index of the lowest bit set of a bitboard.
+func bitIndex(bb uint64) int {
+	return bits.TrailingZeros64(bb)
+}
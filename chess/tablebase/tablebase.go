@@ -0,0 +1,316 @@
+// Package tablebase probes Syzygy endgame tablebases.
+//
+// Syzygy tablebases hold the result with perfect play of every position
+// with few pieces in WDL files (.rtbw), and the distance to zeroing, which is
+// the number of plies to the next capture or pawn move that keeps the result,
+// in DTZ files (.rtbz). The files are named by the pieces of both sides (e.g.
+// KQvK.rtbw). The positions with castling rights are not in the tables.
+package tablebase
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// File extensions of the tables.
+const (
+	wdlExtension = ".rtbw"
+	dtzExtension = ".rtbz"
+)
+
+var (
+	// ErrMissingTable is returned when the table of a position is not in
+	// the tablebase.
+	ErrMissingTable = errors.New("missing table")
+	// ErrCastling is returned when a position has castling rights.
+	ErrCastling = errors.New("positions with castling rights are not in tablebases")
+)
+
+// WDL is the result of a position for the side to move.
+type WDL int
+
+const (
+	// Loss is a loss.
+	Loss WDL = -2
+	// BlessedLoss is a loss that the fifty-move rule turns into a draw.
+	BlessedLoss WDL = -1
+	// Draw is a draw.
+	Draw WDL = 0
+	// CursedWin is a win that the fifty-move rule turns into a draw.
+	CursedWin WDL = 1
+	// Win is a win.
+	Win WDL = 2
+)
+
+// String returns the name of the result.
+func (w WDL) String() string {
+	switch w {
+	case Loss:
+		return "loss"
+	case BlessedLoss:
+		return "blessed loss"
+	case Draw:
+		return "draw"
+	case CursedWin:
+		return "cursed win"
+	case Win:
+		return "win"
+	default:
+		return fmt.Sprintf("WDL(%d)", int(w))
+	}
+}
+
+// Result is the result of a position for the side to move.
+type Result struct {
+	// WDL is the result with the fifty-move counter of the position.
+	WDL WDL
+	// DTZ is the distance to zeroing in plies: positive when the side to
+	// move wins, negative when it loses and 0 in draws and checkmates. It
+	// is greater than 100 in absolute value when the win or the loss is a
+	// draw by the fifty-move rule from a zeroed counter.
+	//
+	// The tables may store the distances in moves, so the value can be
+	// one ply more than the real distance.
+	DTZ int
+}
+
+// RootMove is a legal move of a position and its result.
+type RootMove struct {
+	// Move is the move.
+	Move chess.Move
+	// WDL is the result of the move for the side that plays it, with the
+	// fifty-move counter of the position.
+	WDL WDL
+	// DTZ is the distance to zeroing after the move, counting the move,
+	// for the side that plays it.
+	DTZ int
+}
+
+// Tablebase is a set of Syzygy table files.
+//
+// The files are opened the first time they are probed. A Tablebase is safe
+// for concurrent use by multiple goroutines.
+type Tablebase struct {
+	wdl       map[string]*table
+	dtz       map[string]*table
+	maxPieces int
+}
+
+// Open returns the tablebase of the table files of the given directories.
+// When a table is in more than one directory, the first one is used.
+//
+// The tablebase must be closed when it is no longer used.
+func Open(dirs ...string) (*Tablebase, error) {
+	tb := &Tablebase{wdl: make(map[string]*table), dtz: make(map[string]*table)}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tablebase: %w", err)
+		}
+
+		for _, e := range entries {
+			ext := filepath.Ext(e.Name())
+			if e.IsDir() || ext != wdlExtension && ext != dtzExtension {
+				continue
+			}
+
+			tables := tb.wdl
+			if ext == dtzExtension {
+				tables = tb.dtz
+			}
+
+			name := strings.TrimSuffix(e.Name(), ext)
+			if _, ok := tables[name]; ok {
+				continue
+			}
+
+			t, ok := newTable(name, filepath.Join(dir, e.Name()), ext == dtzExtension)
+			if !ok {
+				continue
+			}
+
+			tables[name] = t
+			if !t.dtz {
+				tb.maxPieces = max(tb.maxPieces, t.pieceCount)
+			}
+		}
+	}
+
+	return tb, nil
+}
+
+// Close closes the table files.
+func (tb *Tablebase) Close() error {
+	var errs []error
+	for _, tables := range []map[string]*table{tb.wdl, tb.dtz} {
+		for _, t := range tables {
+			if err := t.close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// MaxPieces returns the number of pieces, kings included, of the largest
+// WDL tables of the tablebase.
+func (tb *Tablebase) MaxPieces() int {
+	return tb.maxPieces
+}
+
+// ProbeWDL returns the result of the current position of the game for the
+// side to move, as if its fifty-move counter was zero. Only the WDL tables
+// are needed.
+//
+// It returns ErrMissingTable if the tables of the position or of the
+// positions reached by captures are not in the tablebase, and ErrCastling
+// if the position has castling rights.
+func (tb *Tablebase) ProbeWDL(game *chess.Chess) (WDL, error) {
+	p, _, err := tb.newProber(game)
+	if err != nil {
+		return Draw, err
+	}
+
+	wdl, _, err := p.search(false)
+	if err != nil {
+		return Draw, err
+	}
+
+	return wdl, nil
+}
+
+// ProbeDTZ returns the distance to zeroing of the current position of the
+// game in plies, as described in Result.
+//
+// It returns ErrMissingTable if the tables of the position or of the
+// positions reached by captures are not in the tablebase, and ErrCastling
+// if the position has castling rights.
+func (tb *Tablebase) ProbeDTZ(game *chess.Chess) (int, error) {
+	p, _, err := tb.newProber(game)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.game.IsCheckmate() {
+		return 0, nil
+	}
+
+	return p.dtz()
+}
+
+// Probe returns the result and the distance to zeroing of the current
+// position of the game, taking into account its fifty-move counter: a win
+// or a loss is cursed or blessed when the counter reaches 100 before the
+// zeroing move.
+//
+// It returns ErrMissingTable if the tables of the position or of the
+// positions reached by captures are not in the tablebase, and ErrCastling
+// if the position has castling rights.
+func (tb *Tablebase) Probe(game *chess.Chess) (Result, error) {
+	p, halfMoves, err := tb.newProber(game)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if p.game.IsCheckmate() {
+		return Result{WDL: Loss}, nil
+	}
+
+	dtz, err := p.dtz()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{WDL: fiftyMoveWDL(dtz, halfMoves), DTZ: dtz}, nil
+}
+
+// RootMoves returns the legal moves of the current position of the game and
+// their results, taking into account its fifty-move counter. The moves are
+// sorted from the best to the worst: the wins from the fastest to the
+// slowest, then the cursed wins, the draws, the blessed losses and the
+// losses from the slowest to the fastest.
+//
+// It always returns a non nil slice if the error is nil. It returns
+// ErrMissingTable if the tables of the positions are not in the tablebase,
+// and ErrCastling if the position has castling rights.
+func (tb *Tablebase) RootMoves(game *chess.Chess) ([]RootMove, error) {
+	p, halfMoves, err := tb.newProber(game)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := p.game.Moves()
+	roots := make([]RootMove, 0, len(moves))
+	for _, m := range moves {
+		dtz, err := p.rootDTZ(m)
+		if err != nil {
+			return nil, err
+		}
+
+		roots = append(roots, RootMove{Move: m, WDL: fiftyMoveWDL(dtz, halfMoves), DTZ: dtz})
+	}
+
+	slices.SortStableFunc(roots, func(a, b RootMove) int {
+		return cmp.Compare(b.rank(), a.rank())
+	})
+
+	return roots, nil
+}
+
+// rank returns the rank of a root move. Better moves have higher ranks.
+func (m RootMove) rank() int {
+	const maxRank = 1 << 16
+	switch m.WDL {
+	case Win:
+		return maxRank - m.DTZ
+	case CursedWin:
+		return maxRank/2 - m.DTZ
+	case BlessedLoss:
+		return -maxRank/2 - m.DTZ
+	case Loss:
+		return -maxRank - m.DTZ
+	default:
+		return 0
+	}
+}
+
+// fiftyMoveWDL returns the result of a position with a distance to zeroing
+// and a fifty-move counter. The side that can't zero the counter before it
+// reaches 100 can claim a draw.
+func fiftyMoveWDL(dtz, halfMoves int) WDL {
+	switch {
+	case dtz > 0 && dtz+halfMoves <= 100:
+		return Win
+	case dtz > 0:
+		return CursedWin
+	case dtz < 0 && halfMoves-dtz <= 100:
+		return Loss
+	case dtz < 0:
+		return BlessedLoss
+	default:
+		return Draw
+	}
+}
+
+// table returns the table of a position and true if the colors of the
+// position are the opposite of the ones of the table name.
+func (tb *Tablebase) table(tables map[string]*table, p *position) (*table, bool, error) {
+	w, b := p.material()
+	if t, ok := tables[w+"v"+b]; ok {
+		return t, false, t.load()
+	}
+
+	if t, ok := tables[b+"v"+w]; ok {
+		return t, true, t.load()
+	}
+
+	return nil, false, fmt.Errorf("%w: %sv%s", ErrMissingTable, w, b)
+}
@@ -0,0 +1,258 @@
+package tablebase_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/tablebase"
+)
+
+// open opens the tablebase of the testdata directory, which has the KQvK,
+// KRvK and KPvK tables.
+func open(t *testing.T) *tablebase.Tablebase {
+	t.Helper()
+
+	tb, err := tablebase.Open("testdata")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tb.Close()) })
+	return tb
+}
+
+func newGame(t *testing.T, fen string) *chess.Chess {
+	t.Helper()
+
+	game, err := chess.New(chess.WithFEN(fen))
+	require.NoError(t, err)
+	return game
+}
+
+// wdlTests are positions with known results.
+var wdlTests = []struct {
+	name string
+	fen  string
+	want tablebase.WDL
+}{
+	{"queen to move", "4k3/8/8/8/8/8/8/3QK3 w - - 0 1", tablebase.Win},
+	{"king to move", "4k3/8/8/8/8/8/8/3QK3 b - - 0 1", tablebase.Loss},
+	{"black queen", "3qk3/8/8/8/8/8/8/4K3 w - - 0 1", tablebase.Loss},
+	{"black queen to move", "3qk3/8/8/8/8/8/8/4K3 b - - 0 1", tablebase.Win},
+	{"queen capture", "8/8/8/8/8/8/1q6/K6k w - - 0 1", tablebase.Draw},
+	{"stalemate", "k7/8/1QK5/8/8/8/8/8 b - - 0 1", tablebase.Draw},
+	{"rook", "8/8/3k4/8/8/8/8/R3K3 w - - 0 1", tablebase.Win},
+	{"pawn", "8/8/8/4K3/8/8/4P3/k7 w - - 0 1", tablebase.Win},
+	{"rook pawn", "k7/8/8/8/8/8/P7/K7 w - - 0 1", tablebase.Draw},
+	{"pawn on the seventh", "4k3/4P3/4K3/8/8/8/8/8 w - - 0 1", tablebase.Win},
+	{"pawn stalemate", "4k3/4P3/4K3/8/8/8/8/8 b - - 0 1", tablebase.Draw},
+	{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", tablebase.Win},
+	{"black pawn", "k7/4p3/8/8/8/8/8/4K3 b - - 0 1", tablebase.Win},
+	{"fifty-move counter ignored", "4k3/8/8/8/8/8/8/3QK3 w - - 99 60", tablebase.Win},
+}
+
+// dtzTests are positions with known distances to zeroing.
+var dtzTests = []struct {
+	name string
+	fen  string
+	want int
+}{
+	{"mate in one", "k7/8/1K6/8/8/8/8/6Q1 w - - 0 1", 1},
+	{"checkmate", "k6Q/8/1K6/8/8/8/8/8 b - - 0 1", 0},
+	{"mated in one", "k7/8/1K6/8/8/8/8/6Q1 b - - 0 1", -2},
+	{"promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", 1},
+	{"draw", "k7/8/8/8/8/8/P7/K7 w - - 0 1", 0},
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := tablebase.Open(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+
+	t.Run("max pieces", func(t *testing.T) {
+		assert.Equal(t, 3, open(t).MaxPieces())
+
+		tb, err := tablebase.Open(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 0, tb.MaxPieces())
+	})
+
+	t.Run("first directory wins", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "KQvK.rtbw"), make([]byte, 80), 0o644))
+
+		tb, err := tablebase.Open("testdata", dir)
+		require.NoError(t, err)
+		defer tb.Close()
+
+		wdl, err := tb.ProbeWDL(newGame(t, "4k3/8/8/8/8/8/8/3QK3 w - - 0 1"))
+		require.NoError(t, err)
+		assert.Equal(t, tablebase.Win, wdl)
+	})
+
+	t.Run("corrupted table", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "KQvK.rtbw"), make([]byte, 80), 0o644))
+
+		tb, err := tablebase.Open(dir)
+		require.NoError(t, err)
+		defer tb.Close()
+
+		_, err = tb.ProbeWDL(newGame(t, "4k3/8/8/8/8/8/8/3QK3 w - - 0 1"))
+		assert.Error(t, err)
+	})
+}
+
+func TestTablebaseProbeWDL(t *testing.T) {
+	tb := open(t)
+
+	for _, tt := range wdlTests {
+		t.Run(tt.name, func(t *testing.T) {
+			wdl, err := tb.ProbeWDL(newGame(t, tt.fen))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, wdl)
+		})
+	}
+
+	t.Run("castling rights", func(t *testing.T) {
+		_, err := tb.ProbeWDL(newGame(t, "r3k3/8/8/8/8/8/8/4K3 w q - 0 1"))
+		assert.ErrorIs(t, err, tablebase.ErrCastling)
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		_, err := tb.ProbeWDL(newGame(t, "4k3/8/8/8/8/8/8/RR2K3 w - - 0 1"))
+		assert.ErrorIs(t, err, tablebase.ErrMissingTable)
+	})
+}
+
+func TestTablebaseProbeDTZ(t *testing.T) {
+	tb := open(t)
+
+	for _, tt := range dtzTests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtz, err := tb.ProbeDTZ(newGame(t, tt.fen))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, dtz)
+		})
+	}
+}
+
+// TestSyzygyTables probes the known positions in the Syzygy tables of the
+// directory of the SYZYGY_PATH environment variable, which must have the
+// KQvK, KRvK and KPvK tables.
+func TestSyzygyTables(t *testing.T) {
+	dir := os.Getenv("SYZYGY_PATH")
+	if dir == "" {
+		t.Skip("set SYZYGY_PATH to the directory of the Syzygy tables")
+	}
+
+	tb, err := tablebase.Open(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tb.Close()) })
+	require.GreaterOrEqual(t, tb.MaxPieces(), 3)
+
+	for _, tt := range wdlTests {
+		t.Run(tt.name, func(t *testing.T) {
+			wdl, err := tb.ProbeWDL(newGame(t, tt.fen))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, wdl)
+		})
+	}
+
+	// The DTZ tables may store the distances in moves instead of plies, so
+	// they can be off by one ply.
+	for _, tt := range dtzTests {
+		t.Run("dtz "+tt.name, func(t *testing.T) {
+			dtz, err := tb.ProbeDTZ(newGame(t, tt.fen))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, dtz, 1)
+		})
+	}
+}
+
+func TestTablebaseProbe(t *testing.T) {
+	tb := open(t)
+
+	t.Run("checkmate", func(t *testing.T) {
+		r, err := tb.Probe(newGame(t, "k6Q/8/1K6/8/8/8/8/8 b - - 0 1"))
+		require.NoError(t, err)
+		assert.Equal(t, tablebase.Result{WDL: tablebase.Loss}, r)
+	})
+
+	t.Run("fifty-move counter", func(t *testing.T) {
+		r, err := tb.Probe(newGame(t, "8/8/3k4/8/8/8/8/R3K3 w - - 0 60"))
+		require.NoError(t, err)
+		assert.Equal(t, tablebase.Win, r.WDL)
+		require.Greater(t, r.DTZ, 2)
+
+		r, err = tb.Probe(newGame(t, "8/8/3k4/8/8/8/8/R3K3 w - - 98 60"))
+		require.NoError(t, err)
+		assert.Equal(t, tablebase.CursedWin, r.WDL)
+
+		r, err = tb.Probe(newGame(t, "8/8/3k4/8/8/8/8/R3K3 b - - 98 60"))
+		require.NoError(t, err)
+		assert.Equal(t, tablebase.BlessedLoss, r.WDL)
+		assert.Less(t, r.DTZ, 0)
+	})
+}
+
+func TestTablebaseRootMoves(t *testing.T) {
+	tb := open(t)
+
+	t.Run("best moves reach mate", func(t *testing.T) {
+		game := newGame(t, "8/8/3k4/8/8/8/8/R3K3 w - - 0 1")
+		r, err := tb.Probe(game)
+		require.NoError(t, err)
+
+		for ply := range r.DTZ {
+			roots, err := tb.RootMoves(game)
+			require.NoError(t, err)
+			require.NotEmpty(t, roots, "ply %d", ply)
+
+			want := tablebase.Win
+			if ply%2 != 0 {
+				want = tablebase.Loss
+			}
+			require.Equal(t, want, roots[0].WDL)
+			require.NoError(t, game.PlayMove(roots[0].Move))
+		}
+
+		assert.True(t, game.IsCheckmate())
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		roots, err := tb.RootMoves(newGame(t, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1"))
+		require.NoError(t, err)
+		require.NotEmpty(t, roots)
+
+		assert.Equal(t, "e7e8q", roots[0].Move.String())
+		assert.Equal(t, tablebase.RootMove{Move: roots[0].Move, WDL: tablebase.Win, DTZ: 1}, roots[0])
+		for i := 1; i < len(roots); i++ {
+			assert.GreaterOrEqual(t, roots[i-1].WDL, roots[i].WDL)
+		}
+	})
+
+	t.Run("checkmate", func(t *testing.T) {
+		roots, err := tb.RootMoves(newGame(t, "k6Q/8/1K6/8/8/8/8/8 b - - 0 1"))
+		require.NoError(t, err)
+		assert.NotNil(t, roots)
+		assert.Empty(t, roots)
+	})
+
+	t.Run("castling rights", func(t *testing.T) {
+		_, err := tb.RootMoves(newGame(t, "r3k3/8/8/8/8/8/8/4K3 w q - 0 1"))
+		assert.ErrorIs(t, err, tablebase.ErrCastling)
+	})
+}
+
+func TestWDLString(t *testing.T) {
+	assert.Equal(t, "win", tablebase.Win.String())
+	assert.Equal(t, "cursed win", tablebase.CursedWin.String())
+	assert.Equal(t, "draw", tablebase.Draw.String())
+	assert.Equal(t, "blessed loss", tablebase.BlessedLoss.String())
+	assert.Equal(t, "loss", tablebase.Loss.String())
+	assert.Equal(t, "WDL(5)", tablebase.WDL(5).String())
+}
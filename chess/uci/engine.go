@@ -0,0 +1,425 @@
+package uci
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// ErrClosed is returned when the output of the engine ends while waiting
+// for a response.
+var ErrClosed = errors.New("engine closed")
+
+const (
+	// quitTimeout is the time a process has to exit after the quit command
+	// before it is killed.
+	quitTimeout = 5 * time.Second
+	// stopTimeout is the time an engine has to send its best move after the
+	// stop command before it is no longer waited for.
+	stopTimeout = time.Second
+)
+
+// SearchResult is the result of a search.
+type SearchResult struct {
+	// BestMove is the move chosen by the engine, in UCI notation. It is
+	// "0000" or empty if the position has no legal moves.
+	BestMove string
+	// Ponder is the move the engine expects as a reply, if it sent one.
+	Ponder string
+	// Lines are the last infos with a pv of every line, ordered by their
+	// multipv number.
+	Lines []Info
+}
+
+// Engine is a client of a UCI engine.
+//
+// Commands that wait for a response are serialized, while Stop and
+// PonderHit can be called from any goroutine during a search.
+type Engine struct {
+	// Name and Author are the values of the id command of the engine.
+	Name, Author string
+	// Options are the options declared by the engine, in order.
+	Options []Option
+
+	// mu serializes the commands that read the output of the engine.
+	mu sync.Mutex
+	// writeMu serializes the writes to the engine.
+	writeMu sync.Mutex
+	w       io.Writer
+	closer  io.Closer
+	cmd     *exec.Cmd
+	// stale is the number of best moves of abandoned searches that are still
+	// to be sent by the engine. They are skipped by readLine.
+	stale int
+
+	// lines receives the lines of the engine. It is closed when the output
+	// ends, after setting readErr.
+	lines   chan string
+	readErr error
+	// done is closed to stop reading the lines of the engine.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEngine returns a client of the engine reached through rw and sends
+// the uci command, waiting for uciok.
+//
+// If rw is also an io.Closer, Close closes it.
+func NewEngine(ctx context.Context, rw io.ReadWriter) (*Engine, error) {
+	e := newEngine(rw, rw)
+	if c, ok := rw.(io.Closer); ok {
+		e.closer = c
+	}
+
+	if err := e.handshake(ctx); err != nil {
+		_ = e.release()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// StartEngine starts the engine process and sends the uci command, waiting
+// for uciok. The standard input and output of cmd must not be set.
+//
+// The process is killed if the handshake fails.
+func StartEngine(ctx context.Context, cmd *exec.Cmd) (*Engine, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start engine: %w", err)
+	}
+
+	e := newEngine(stdout, stdin)
+	e.closer, e.cmd = stdin, cmd
+
+	if err := e.handshake(ctx); err != nil {
+		_ = e.release()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// newEngine returns a client reading from r and writing to w, and starts
+// reading the lines of r until its end or the release of the client.
+func newEngine(r io.Reader, w io.Writer) *Engine {
+	e := &Engine{w: w, lines: make(chan string, 256), done: make(chan struct{})}
+
+	go func() {
+		defer close(e.lines)
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case e.lines <- strings.TrimSpace(scanner.Text()):
+			case <-e.done:
+				return
+			}
+		}
+
+		e.readErr = scanner.Err()
+	}()
+
+	return e
+}
+
+// release closes the connection to the engine, if it is an io.Closer, and
+// stops reading its lines. A reader that is not an io.Closer is no longer
+// read after its next line.
+func (e *Engine) release() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.done)
+		if e.closer != nil {
+			err = e.closer.Close()
+		}
+	})
+
+	return err
+}
+
+// handshake sends the uci command and reads the id and option lines until
+// uciok.
+func (e *Engine) handshake(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read uciok: %w", err)
+		}
+
+		switch command, args, _ := strings.Cut(line, " "); command {
+		case "uciok":
+			return nil
+		case "id":
+			switch key, value, _ := strings.Cut(args, " "); key {
+			case "name":
+				e.Name = value
+			case "author":
+				e.Author = value
+			}
+		case "option":
+			o, err := ParseOption(line)
+			if err != nil {
+				return err
+			}
+			e.Options = append(e.Options, o)
+		}
+	}
+}
+
+// IsReady sends the isready command and waits for readyok.
+func (e *Engine) IsReady(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.isReady(ctx)
+}
+
+// isReady sends the isready command and waits for readyok, discarding any
+// other line.
+func (e *Engine) isReady(ctx context.Context) error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read readyok: %w", err)
+		}
+
+		if line == "readyok" {
+			return nil
+		}
+	}
+}
+
+// SetOption sends the setoption command. The value is omitted for button
+// options.
+func (e *Engine) SetOption(name, value string) error {
+	if value == "" {
+		return e.send("setoption name " + name)
+	}
+
+	return e.send("setoption name " + name + " value " + value)
+}
+
+// NewGame sends the ucinewgame command and waits for the engine to be
+// ready.
+func (e *Engine) NewGame(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("ucinewgame"); err != nil {
+		return err
+	}
+
+	return e.isReady(ctx)
+}
+
+// Position sends the position command with the given FEN and moves in UCI
+// notation. An empty FEN is the starting position.
+func (e *Engine) Position(fen string, moves ...string) error {
+	command := "position startpos"
+	if fen != "" {
+		command = "position fen " + fen
+	}
+
+	if len(moves) > 0 {
+		command += " moves " + strings.Join(moves, " ")
+	}
+
+	return e.send(command)
+}
+
+// PositionFromGame sends the position command with the starting position
+// and the moves of the game.
+func (e *Engine) PositionFromGame(game *chess.Chess) error {
+	history := game.History()
+	moves := make([]string, len(history))
+	for i, m := range history {
+		moves[i] = m.UCI()
+	}
+
+	return e.Position(game.StartingFEN(), moves...)
+}
+
+// Go starts a search with the given limits and waits for the best move.
+// onInfo, if not nil, is called with every info line the engine sends.
+//
+// If ctx is done before the engine answers, the stop command is sent and
+// the best move is returned with the error of the context. The best move is
+// waited for up to stopTimeout, after which the error of the context is
+// returned alone.
+func (e *Engine) Go(ctx context.Context, limits Limits, onInfo func(Info)) (SearchResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send(strings.TrimSpace("go " + limits.String())); err != nil {
+		return SearchResult{}, err
+	}
+
+	var result SearchResult
+	var ctxErr error
+	for {
+		line, err := e.readLine(ctx)
+		if err != nil && ctxErr == nil && ctx.Err() != nil {
+			// The search is stopped, and the best move is still read.
+			ctxErr = ctx.Err()
+			if err := e.send("stop"); err != nil {
+				return result, err
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), stopTimeout)
+			defer cancel()
+			continue
+		}
+		if err != nil && ctxErr != nil && ctx.Err() != nil {
+			// The best move will be sent later, and skipped then.
+			e.stale++
+			return result, ctxErr
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read bestmove: %w", err)
+		}
+
+		command, args, _ := strings.Cut(line, " ")
+		switch command {
+		case "info":
+			info, err := ParseInfo(line)
+			if err != nil {
+				continue
+			}
+			result.addLine(info)
+			if onInfo != nil {
+				onInfo(info)
+			}
+		case "bestmove":
+			fields := strings.Fields(args)
+			if len(fields) > 0 {
+				result.BestMove = fields[0]
+			}
+			if len(fields) > 2 && fields[1] == "ponder" {
+				result.Ponder = fields[2]
+			}
+			return result, ctxErr
+		}
+	}
+}
+
+// addLine keeps the info as the last one of its line if it has a pv.
+func (r *SearchResult) addLine(info Info) {
+	if len(info.PV) == 0 {
+		return
+	}
+
+	i := max(info.MultiPV, 1) - 1
+	for len(r.Lines) <= i {
+		r.Lines = append(r.Lines, Info{})
+	}
+
+	r.Lines[i] = info
+}
+
+// Stop sends the stop command, so the running search returns as soon as
+// possible.
+func (e *Engine) Stop() error {
+	return e.send("stop")
+}
+
+// PonderHit sends the ponderhit command, telling the engine that the
+// expected move was played and the pondering search goes on as a normal
+// search.
+func (e *Engine) PonderHit() error {
+	return e.send("ponderhit")
+}
+
+// Close sends the quit command and releases the engine. A process started
+// with StartEngine is killed if it does not exit in time.
+func (e *Engine) Close() error {
+	quitErr := e.send("quit")
+	closeErr := e.release()
+
+	if e.cmd == nil {
+		return errors.Join(quitErr, closeErr)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- e.cmd.Wait()
+	}()
+
+	var waitErr error
+	select {
+	case waitErr = <-exited:
+	case <-time.After(quitTimeout):
+		_ = e.cmd.Process.Kill()
+		<-exited
+		waitErr = fmt.Errorf("engine did not quit in %s and was killed", quitTimeout)
+	}
+
+	return errors.Join(quitErr, closeErr, waitErr)
+}
+
+// send writes a command to the engine.
+func (e *Engine) send(command string) error {
+	e.writeMu.Lock()
+	defer e.writeMu.Unlock()
+
+	if _, err := io.WriteString(e.w, command+"\n"); err != nil {
+		return fmt.Errorf("failed to send %q: %w", command, err)
+	}
+
+	return nil
+}
+
+// readLine returns the next non empty line of the engine. The best moves of
+// abandoned searches are skipped, whatever command is waiting.
+func (e *Engine) readLine(ctx context.Context) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case line, ok := <-e.lines:
+			if !ok {
+				if e.readErr != nil {
+					return "", fmt.Errorf("%w: %w", ErrClosed, e.readErr)
+				}
+				return "", ErrClosed
+			}
+			if e.stale > 0 && (line == "bestmove" || strings.HasPrefix(line, "bestmove ")) {
+				e.stale--
+				continue
+			}
+			if line != "" {
+				return line, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package uci_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngineEnv makes the test binary run as a fake engine, for the tests
+// that start a process.
+const fakeEngineEnv = "GOCHESS_FAKE_UCI_ENGINE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEngineEnv) == "1" {
+		script(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// script answers the commands read from r as a small engine would. A go
+// command sends two infos and the best move, unless it is infinite, which
+// waits for stop. It returns after quit or when r ends.
+func script(r io.Reader, w io.Writer) {
+	send := func(lines ...string) {
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	searching := false
+	for scanner.Scan() {
+		command := scanner.Text()
+		switch {
+		case command == "uci":
+			send("id name Fake Engine 1.0", "id author Gopher",
+				"option name Hash type spin default 16 min 1 max 1024",
+				"option name Clear Hash type button",
+				"uciok")
+		case command == "isready":
+			send("readyok")
+		case strings.HasPrefix(command, "go"):
+			send("info depth 1 score cp 20 pv e2e4",
+				"info depth 2 multipv 1 score cp 30 wdl 100 850 50 pv e2e4 e7e5")
+			searching = strings.Contains(command, "infinite") || strings.Contains(command, "ponder")
+			if !searching {
+				send("bestmove e2e4 ponder e7e5")
+			}
+		case command == "stop", command == "ponderhit":
+			if searching {
+				searching = false
+				send("bestmove d2d4")
+			}
+		case command == "quit":
+			return
+		}
+	}
+}
+
+// fakeEngine is an engine running script in a goroutine and recording the
+// commands it receives.
+type fakeEngine struct {
+	clientR *io.PipeReader
+	clientW *io.PipeWriter
+
+	mu       sync.Mutex
+	commands []string
+}
+
+// newFakeEngine starts a scripted engine and returns it with a client
+// connected to it.
+func newFakeEngine(t *testing.T) (*fakeEngine, *uci.Engine) {
+	t.Helper()
+
+	engineR, clientW := io.Pipe()
+	clientR, engineW := io.Pipe()
+	f := &fakeEngine{clientR: clientR, clientW: clientW}
+
+	go func() {
+		script(engineR, engineW)
+		engineW.Close()
+	}()
+
+	e, err := uci.NewEngine(context.Background(), f)
+	require.NoError(t, err)
+	t.Cleanup(func() { e.Close() })
+
+	return f, e
+}
+
+// Read reads the output of the engine.
+func (f *fakeEngine) Read(p []byte) (int, error) {
+	return f.clientR.Read(p)
+}
+
+// Write records the commands and sends them to the engine.
+func (f *fakeEngine) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	for _, command := range strings.Split(strings.TrimSpace(string(p)), "\n") {
+		f.commands = append(f.commands, command)
+	}
+	f.mu.Unlock()
+
+	return f.clientW.Write(p)
+}
+
+// Close closes the connection with the engine.
+func (f *fakeEngine) Close() error {
+	return f.clientW.Close()
+}
+
+// last returns the last command received.
+func (f *fakeEngine) last() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.commands[len(f.commands)-1]
+}
+
+func TestEngine(t *testing.T) {
+	t.Run("Handshake", func(t *testing.T) {
+		_, e := newFakeEngine(t)
+
+		assert.Equal(t, "Fake Engine 1.0", e.Name)
+		assert.Equal(t, "Gopher", e.Author)
+		assert.Equal(t, []uci.Option{
+			{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 1024},
+			{Name: "Clear Hash", Type: "button"},
+		}, e.Options)
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		require.NoError(t, e.SetOption("Hash", "64"))
+		assert.Equal(t, "setoption name Hash value 64", f.last())
+
+		require.NoError(t, e.SetOption("Clear Hash", ""))
+		assert.Equal(t, "setoption name Clear Hash", f.last())
+
+		require.NoError(t, e.NewGame(context.Background()))
+		assert.Equal(t, "isready", f.last())
+
+		require.NoError(t, e.Position("", "e2e4", "e7e5"))
+		assert.Equal(t, "position startpos moves e2e4 e7e5", f.last())
+
+		require.NoError(t, e.Position("8/8/8/8/8/8/8/K1k5 w - - 0 1"))
+		assert.Equal(t, "position fen 8/8/8/8/8/8/8/K1k5 w - - 0 1", f.last())
+	})
+
+	t.Run("Position from a game", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		game, err := chess.New(chess.WithFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"))
+		require.NoError(t, err)
+		require.NoError(t, game.MakeMove("e2e4"))
+		require.NoError(t, game.MakeMove("e8d7"))
+
+		require.NoError(t, e.PositionFromGame(game))
+		assert.Equal(t, "position fen 4k3/8/8/8/8/8/4P3/4K3 w - - 0 1 moves e2e4 e8d7", f.last())
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		var infos []uci.Info
+		result, err := e.Go(context.Background(), uci.Limits{Depth: 2, MoveTime: time.Second}, func(info uci.Info) {
+			infos = append(infos, info)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "go depth 2 movetime 1000", f.last())
+		assert.Equal(t, "e2e4", result.BestMove)
+		assert.Equal(t, "e7e5", result.Ponder)
+		assert.Len(t, infos, 2)
+		require.Len(t, result.Lines, 1)
+		assert.Equal(t, uci.Info{
+			Depth:   2,
+			MultiPV: 1,
+			Score:   &uci.Score{CP: 30},
+			WDL:     &uci.WDL{Win: 100, Draw: 850, Loss: 50},
+			PV:      []string{"e2e4", "e7e5"},
+		}, result.Lines[0])
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		result, err := e.Go(context.Background(), uci.Limits{Infinite: true}, func(info uci.Info) {
+			if info.Depth == 2 {
+				assert.NoError(t, e.Stop())
+			}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", result.BestMove)
+		assert.Equal(t, "stop", f.last())
+	})
+
+	t.Run("Ponder hit", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		result, err := e.Go(context.Background(), uci.Limits{Ponder: true}, func(info uci.Info) {
+			if info.Depth == 2 {
+				assert.NoError(t, e.PonderHit())
+			}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", result.BestMove)
+		assert.Equal(t, "ponderhit", f.last())
+	})
+
+	t.Run("Context cancellation stops the search", func(t *testing.T) {
+		f, e := newFakeEngine(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		result, err := e.Go(ctx, uci.Limits{Infinite: true}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "d2d4", result.BestMove)
+		assert.Equal(t, "stop", f.last())
+	})
+
+	t.Run("Context cancellation does not wait forever", func(t *testing.T) {
+		// The engine finishes the handshake and ignores the rest of the
+		// commands.
+		engineR, clientW := io.Pipe()
+		clientR, engineW := io.Pipe()
+		go func() {
+			scanner := bufio.NewScanner(engineR)
+			for scanner.Scan() {
+				if scanner.Text() == "uci" {
+					fmt.Fprintln(engineW, "uciok")
+				}
+			}
+		}()
+
+		e, err := uci.NewEngine(context.Background(), struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		require.NoError(t, err)
+		defer e.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		result, err := e.Go(ctx, uci.Limits{Infinite: true}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, result.BestMove)
+
+		// The best move of the abandoned search is skipped.
+		go fmt.Fprint(engineW, "bestmove e2e4\nbestmove d2d4\n")
+		result, err = e.Go(context.Background(), uci.Limits{Depth: 1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", result.BestMove)
+	})
+
+	t.Run("Late best move read by IsReady", func(t *testing.T) {
+		// The engine ignores the stop command and sends the best move of the
+		// abandoned search just before readyok.
+		engineR, clientW := io.Pipe()
+		clientR, engineW := io.Pipe()
+		go func() {
+			scanner := bufio.NewScanner(engineR)
+			for scanner.Scan() {
+				switch scanner.Text() {
+				case "uci":
+					fmt.Fprintln(engineW, "uciok")
+				case "isready":
+					fmt.Fprint(engineW, "bestmove e2e4\nreadyok\n")
+				case "go depth 1":
+					fmt.Fprintln(engineW, "bestmove d2d4")
+				}
+			}
+		}()
+
+		e, err := uci.NewEngine(context.Background(), struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		require.NoError(t, err)
+		defer e.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = e.Go(ctx, uci.Limits{Infinite: true}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, e.IsReady(context.Background()))
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := e.Go(ctx, uci.Limits{Depth: 1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "d2d4", result.BestMove)
+	})
+
+	t.Run("Failed handshake closes the connection", func(t *testing.T) {
+		engineR, clientW := io.Pipe()
+		clientR, _ := io.Pipe()
+		go func() { _, _ = io.Copy(io.Discard, engineR) }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := uci.NewEngine(ctx, struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = clientW.Write([]byte("uci\n"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("Closed engine", func(t *testing.T) {
+		_, e := newFakeEngine(t)
+		require.NoError(t, e.Close())
+
+		_, err := e.Go(context.Background(), uci.Limits{Depth: 1}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestStartEngine(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), fakeEngineEnv+"=1")
+
+	e, err := uci.StartEngine(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "Fake Engine 1.0", e.Name)
+
+	require.NoError(t, e.IsReady(context.Background()))
+	require.NoError(t, e.Position("", "e2e4"))
+
+	result, err := e.Go(context.Background(), uci.Limits{Depth: 1}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "e2e4", result.BestMove)
+
+	assert.NoError(t, e.Close())
+}
@@ -0,0 +1,491 @@
+package uci
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+)
+
+// Searcher searches the best move of a position for a Server.
+type Searcher interface {
+	// Search searches the game, which the searcher may modify, within the
+	// limits and returns the best move. It should report its progress with
+	// info and return as soon as possible when ctx is done, with the best
+	// move found so far.
+	//
+	// Limits with Ponder or Infinite set have no end: the search runs
+	// until ctx is done.
+	Search(ctx context.Context, game *chess.Chess, limits Limits, info func(Info)) (SearchResult, error)
+}
+
+// NewGamer is implemented by the searchers that reset their state, such as
+// their caches, when the GUI starts a new game.
+type NewGamer interface {
+	NewGame()
+}
+
+// ServerOption is a function that configures a server.
+type ServerOption func(*Server)
+
+// WithOption declares an option of the engine. set is called with the value
+// of every setoption command for the option, and its errors are reported
+// to the GUI as info strings.
+func WithOption(o Option, set func(value string) error) ServerOption {
+	return func(s *Server) {
+		s.options = append(s.options, serverOption{Option: o, set: set})
+	}
+}
+
+// WithGameOptions sets the options used to create the game of every
+// position command (e.g. chess.WithParallelism).
+func WithGameOptions(opts ...chess.Option) ServerOption {
+	return func(s *Server) {
+		s.gameOpts = append(s.gameOpts, opts...)
+	}
+}
+
+// serverOption is an option declared by a server.
+type serverOption struct {
+	Option
+	set func(value string) error
+}
+
+// chess960Option is the standard option that enables the Chess960 rules.
+const chess960Option = "UCI_Chess960"
+
+// Server runs the engine side of the UCI protocol on top of a Searcher.
+//
+// It keeps the position set by the GUI, runs one search at a time in its own
+// goroutine and answers isready, stop and quit while searching.
+type Server struct {
+	name, author string
+	searcher     Searcher
+	options      []serverOption
+	gameOpts     []chess.Option
+
+	// writeMu serializes the writes to the GUI.
+	writeMu sync.Mutex
+	w       io.Writer
+
+	// fen and moves are the position set by the GUI.
+	fen   string
+	moves []string
+	// debug is read by the searches to report their errors.
+	debug    atomic.Bool
+	chess960 bool
+
+	// search is the running search, or nil.
+	search *search
+}
+
+// search is a search running in the background.
+type search struct {
+	cancel context.CancelFunc
+	// hold is true if the best move has to wait for stop or ponderhit,
+	// as the limits are infinite or pondering.
+	hold bool
+	// released is closed when the best move can be sent.
+	released chan struct{}
+	// discard is true if the best move must not be sent. It is set before
+	// closing released.
+	discard bool
+	// done is closed when the best move has been sent.
+	done   chan struct{}
+	limits Limits
+}
+
+// NewServer returns a server for the engine with the given name and author,
+// which searches with searcher.
+func NewServer(name, author string, searcher Searcher, opts ...ServerOption) *Server {
+	s := &Server{name: name, author: author, searcher: searcher}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Serve reads the commands of the GUI from r and writes the responses to w
+// until the quit command, the end of r or ctx being done. A running search
+// is stopped and its best move is sent before returning.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	s.fen, s.moves = initialFEN, nil
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	defer s.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				return <-readErr
+			}
+
+			if quit := s.handle(ctx, line); quit {
+				return nil
+			}
+		}
+	}
+}
+
+// handle runs a command of the GUI and returns true if it is quit.
+func (s *Server) handle(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+
+	command, args := fields[0], fields[1:]
+	switch command {
+	case "uci":
+		s.send("id name " + s.name)
+		if s.author != "" {
+			s.send("id author " + s.author)
+		}
+		for _, o := range s.options {
+			s.send(o.String())
+		}
+		s.send("uciok")
+	case "debug":
+		s.debug.Store(len(args) > 0 && args[0] == "on")
+	case "isready":
+		s.send("readyok")
+	case "setoption":
+		s.setOption(args)
+	case "ucinewgame":
+		s.stop()
+		if ng, ok := s.searcher.(NewGamer); ok {
+			ng.NewGame()
+		}
+		s.fen, s.moves = initialFEN, nil
+	case "position":
+		s.stop()
+		if err := s.position(args); err != nil {
+			s.debugf("%v", err)
+		}
+	case "go":
+		s.stop()
+		limits, err := ParseLimits(args)
+		if err != nil {
+			// The GUI waits for a best move after every go command.
+			s.debugf("%v", err)
+			s.sendBestMove(SearchResult{})
+			return false
+		}
+		s.start(ctx, limits)
+	case "stop":
+		s.stop()
+	case "ponderhit":
+		s.ponderHit(ctx)
+	case "quit":
+		return true
+	default:
+		s.debugf("unknown command: %s", command)
+	}
+
+	return false
+}
+
+// setOption runs a setoption command.
+func (s *Server) setOption(args []string) {
+	// The name and the value may have spaces.
+	line := strings.Join(args, " ")
+	name, value, _ := strings.Cut(strings.TrimPrefix(line, "name "), " value ")
+	name = strings.TrimSpace(name)
+
+	for _, o := range s.options {
+		if !strings.EqualFold(o.Name, name) {
+			continue
+		}
+
+		if strings.EqualFold(name, chess960Option) {
+			s.chess960 = value == "true"
+		}
+
+		if o.set != nil {
+			if err := o.set(value); err != nil {
+				s.debugf("failed to set option %s: %v", name, err)
+			}
+		}
+		return
+	}
+
+	s.debugf("unknown option: %s", name)
+}
+
+// gameOptions returns the options used to create games.
+func (s *Server) gameOptions() []chess.Option {
+	opts := s.gameOpts
+	if s.chess960 {
+		opts = append(opts[:len(opts):len(opts)], chess.WithChess960())
+	}
+
+	return opts
+}
+
+// position runs a position command. The position is kept if the command is
+// invalid.
+func (s *Server) position(args []string) error {
+	fen := initialFEN
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+		args = args[1:]
+	case len(args) > 0 && args[0] == "fen":
+		i := 1
+		for i < len(args) && args[i] != "moves" {
+			i++
+		}
+		fen = strings.Join(args[1:i], " ")
+		args = args[i:]
+	default:
+		return fmt.Errorf("invalid position command: %s", strings.Join(args, " "))
+	}
+
+	var moves []string
+	if len(args) > 0 {
+		if args[0] != "moves" {
+			return fmt.Errorf("invalid position command: unexpected %s", args[0])
+		}
+		moves = args[1:]
+	}
+
+	if _, err := s.newGame(fen, moves); err != nil {
+		return err
+	}
+
+	s.fen, s.moves = fen, moves
+	return nil
+}
+
+// newGame returns a game with the given moves played from the FEN.
+func (s *Server) newGame(fen string, moves []string) (*chess.Chess, error) {
+	game, err := chess.New(s.gameOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+
+	if err := game.LoadPosition(fen); err != nil {
+		return nil, err
+	}
+
+	for _, m := range moves {
+		if err := game.MakeMove(m); err != nil {
+			return nil, fmt.Errorf("invalid move %s: %w", m, err)
+		}
+	}
+
+	return game, nil
+}
+
+// start starts a search of the current position in the background.
+func (s *Server) start(ctx context.Context, limits Limits) {
+	// Every search gets its own game, so the searcher can modify it.
+	game, err := s.newGame(s.fen, s.moves)
+	if err != nil {
+		s.debugf("%v", err)
+		s.sendBestMove(SearchResult{})
+		return
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	srch := &search{
+		cancel:   cancel,
+		hold:     limits.Ponder || limits.Infinite,
+		released: make(chan struct{}),
+		done:     make(chan struct{}),
+		limits:   limits,
+	}
+	if !srch.hold {
+		close(srch.released)
+	}
+	s.search = srch
+
+	go func() {
+		defer close(srch.done)
+
+		result, err := s.searcher.Search(searchCtx, game, limits, func(info Info) {
+			s.send(info.String())
+		})
+		if err != nil && searchCtx.Err() == nil {
+			s.debugf("search failed: %v", err)
+		}
+
+		// The best move of an infinite or pondering search is only sent
+		// after stop or ponderhit.
+		<-srch.released
+		if !srch.discard {
+			s.sendBestMove(result)
+		}
+	}()
+}
+
+// ponderHit runs a ponderhit command. The pondering search is stopped
+// without sending its best move, and a normal search with the same limits
+// starts, so the time limits apply from now on.
+func (s *Server) ponderHit(ctx context.Context) {
+	srch := s.search
+	if srch == nil || !srch.limits.Ponder {
+		return
+	}
+
+	srch.discard = true
+	srch.cancel()
+	close(srch.released)
+	<-srch.done
+
+	limits := srch.limits
+	limits.Ponder = false
+	s.start(ctx, limits)
+}
+
+// stop stops the running search and waits for its best move to be sent.
+func (s *Server) stop() {
+	srch := s.search
+	if srch == nil {
+		return
+	}
+
+	srch.cancel()
+	if srch.hold {
+		close(srch.released)
+	}
+	<-srch.done
+	s.search = nil
+}
+
+// sendBestMove sends the best move of a search.
+func (s *Server) sendBestMove(result SearchResult) {
+	best := result.BestMove
+	if best == "" {
+		best = "0000"
+	}
+
+	if result.Ponder != "" {
+		s.send("bestmove " + best + " ponder " + result.Ponder)
+		return
+	}
+
+	s.send("bestmove " + best)
+}
+
+// debugf sends an info string if the debug mode is on.
+func (s *Server) debugf(format string, args ...any) {
+	if s.debug.Load() {
+		s.send("info string " + fmt.Sprintf(format, args...))
+	}
+}
+
+// send writes a line to the GUI.
+func (s *Server) send(line string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, _ = io.WriteString(s.w, line+"\n")
+}
+
+// ParseLimits parses the arguments of a go command (e.g.
+// "wtime 60000 btime 60000 movestogo 40"). Unknown arguments are ignored.
+//
+// Negative times, which GUIs send when a clock is overdrawn, are read as
+// zero. Clock is set when wtime or btime is present, so a zero time is not
+// taken for a search without time limit.
+func ParseLimits(args []string) (Limits, error) {
+	var l Limits
+	for i := 0; i < len(args); i++ {
+		switch name := args[i]; name {
+		case "ponder":
+			l.Ponder = true
+		case "infinite":
+			l.Infinite = true
+		case "searchmoves":
+			for i+1 < len(args) && isMove(args[i+1]) {
+				i++
+				l.SearchMoves = append(l.SearchMoves, args[i])
+			}
+		case "wtime", "btime", "winc", "binc", "movetime":
+			if i+1 >= len(args) {
+				return Limits{}, fmt.Errorf("missing value of %s", name)
+			}
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil {
+				return Limits{}, fmt.Errorf("invalid value of %s: %s", name, args[i])
+			}
+			l.setTime(name, time.Duration(max(n, 0))*time.Millisecond)
+		case "movestogo", "depth", "nodes", "mate":
+			if i+1 >= len(args) {
+				return Limits{}, fmt.Errorf("missing value of %s", name)
+			}
+			i++
+			n, err := strconv.ParseUint(args[i], 10, 64)
+			if err != nil {
+				return Limits{}, fmt.Errorf("invalid value of %s: %s", name, args[i])
+			}
+			l.set(name, n)
+		}
+	}
+
+	return l, nil
+}
+
+// setTime sets the time limit with the given go argument name.
+func (l *Limits) setTime(name string, d time.Duration) {
+	switch name {
+	case "wtime":
+		l.WhiteTime, l.Clock = d, true
+	case "btime":
+		l.BlackTime, l.Clock = d, true
+	case "winc":
+		l.WhiteIncrement = d
+	case "binc":
+		l.BlackIncrement = d
+	case "movetime":
+		l.MoveTime = d
+	}
+}
+
+// set sets the limit with the given go argument name.
+func (l *Limits) set(name string, n uint64) {
+	switch name {
+	case "movestogo":
+		l.MovesToGo = int(n)
+	case "depth":
+		l.Depth = int(n)
+	case "nodes":
+		l.Nodes = n
+	case "mate":
+		l.Mate = int(n)
+	}
+}
+
+// isMove returns true if s looks like a move in UCI notation.
+func isMove(s string) bool {
+	return (len(s) == 4 || len(s) == 5) && s[0] >= 'a' && s[0] <= 'h' && s[1] >= '1' && s[1] <= '8'
+}
+
+// initialFEN is the FEN string of the initial position.
+const initialFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
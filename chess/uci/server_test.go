@@ -0,0 +1,283 @@
+package uci_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// search is a search received by a fakeSearcher.
+type search struct {
+	fen    string
+	limits uci.Limits
+}
+
+// fakeSearcher plays the first legal move of the position. Searches without
+// an end wait for their context to be done.
+type fakeSearcher struct {
+	mu       sync.Mutex
+	searches []search
+	newGames int
+}
+
+// Search records the search and returns the first legal move.
+func (f *fakeSearcher) Search(ctx context.Context, game *chess.Chess, limits uci.Limits, info func(uci.Info)) (uci.SearchResult, error) {
+	f.mu.Lock()
+	f.searches = append(f.searches, search{fen: game.FEN(), limits: limits})
+	f.mu.Unlock()
+
+	moves := game.AvailableMoves()
+	if len(moves) == 0 {
+		return uci.SearchResult{}, nil
+	}
+
+	info(uci.Info{Depth: 1, Score: &uci.Score{CP: 10}, PV: moves[:1]})
+	if limits.Infinite || limits.Ponder {
+		<-ctx.Done()
+	}
+
+	return uci.SearchResult{BestMove: moves[0]}, nil
+}
+
+// NewGame counts the new games.
+func (f *fakeSearcher) NewGame() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.newGames++
+}
+
+// serve runs the server with the given commands and returns its output
+// lines.
+func serve(t *testing.T, s *uci.Server, commands ...string) []string {
+	t.Helper()
+
+	var out strings.Builder
+	err := s.Serve(context.Background(), strings.NewReader(strings.Join(commands, "\n")+"\n"), &out)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Handshake", func(t *testing.T) {
+		s := uci.NewServer("Gopher 1.0", "Gophers", &fakeSearcher{},
+			uci.WithOption(uci.Option{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 64}, nil),
+		)
+
+		assert.Equal(t, []string{
+			"id name Gopher 1.0",
+			"id author Gophers",
+			"option name Hash type spin default 16 min 1 max 64",
+			"uciok",
+			"readyok",
+		}, serve(t, s, "uci", "isready"))
+	})
+
+	t.Run("Position and go", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s,
+			"ucinewgame",
+			"position startpos moves e2e4 e7e5",
+			"go wtime 60000 btime 50000 winc 1000 binc 1000 depth 3",
+		)
+
+		assert.Equal(t, []string{"info depth 1 score cp 10 pv a2a3", "bestmove a2a3"}, out)
+		assert.Equal(t, 1, f.newGames)
+		require.Len(t, f.searches, 1)
+		assert.Equal(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2", f.searches[0].fen)
+		assert.Equal(t, uci.Limits{
+			WhiteTime:      time.Minute,
+			BlackTime:      50 * time.Second,
+			Clock:          true,
+			WhiteIncrement: time.Second,
+			BlackIncrement: time.Second,
+			Depth:          3,
+		}, f.searches[0].limits)
+	})
+
+	t.Run("Position from a FEN", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s, "position fen 4k3/8/8/8/8/8/4P3/4K3 w - - 0 1 moves e2e4", "go movetime 100")
+
+		assert.Equal(t, "bestmove e8d7", out[len(out)-1])
+		assert.Equal(t, "4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1", f.searches[0].fen)
+	})
+
+	t.Run("Overdrawn clock", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s, "position startpos", "go wtime -50 btime 1000", "stop")
+
+		assert.Equal(t, "bestmove a2a3", out[len(out)-1])
+		require.Len(t, f.searches, 1)
+		assert.Equal(t, uci.Limits{BlackTime: time.Second, Clock: true}, f.searches[0].limits)
+	})
+
+	t.Run("Invalid go command", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s, "position startpos", "go depth x")
+
+		assert.Equal(t, []string{"bestmove 0000"}, out)
+		assert.Empty(t, f.searches)
+	})
+
+	t.Run("Stop ends an infinite search", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s, "position startpos", "go infinite", "isready", "stop", "isready")
+
+		// The info of the search may come before or after the first readyok.
+		assert.ElementsMatch(t, []string{"info depth 1 score cp 10 pv a2a3", "readyok"}, out[:2])
+		assert.Equal(t, []string{"bestmove a2a3", "readyok"}, out[2:])
+	})
+
+	t.Run("Ponder hit", func(t *testing.T) {
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f)
+
+		out := serve(t, s, "position startpos", "go ponder wtime 1000 btime 1000", "ponderhit")
+
+		assert.Equal(t, "bestmove a2a3", out[len(out)-1])
+		assert.Equal(t, 1, strings.Count(strings.Join(out, "\n"), "bestmove"))
+		require.Len(t, f.searches, 2)
+		assert.True(t, f.searches[0].limits.Ponder)
+		assert.Equal(t, uci.Limits{WhiteTime: time.Second, BlackTime: time.Second, Clock: true}, f.searches[1].limits)
+	})
+
+	t.Run("Quit stops the search", func(t *testing.T) {
+		s := uci.NewServer("Gopher", "", &fakeSearcher{})
+
+		out := serve(t, s, "go infinite", "quit", "isready")
+
+		assert.Equal(t, "bestmove a2a3", out[len(out)-1])
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		var hash []string
+		f := &fakeSearcher{}
+		s := uci.NewServer("Gopher", "", f,
+			uci.WithOption(uci.Option{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 64}, func(value string) error {
+				hash = append(hash, value)
+				return nil
+			}),
+			uci.WithOption(uci.Option{Name: "UCI_Chess960", Type: "check", Default: "false"}, nil),
+		)
+
+		serve(t, s,
+			"setoption name Hash value 32",
+			"setoption name UCI_Chess960 value true",
+			"position fen bqnbrkrn/pppppppp/8/8/8/8/PPPPPPPP/BQNBRKRN w GEge - 0 1",
+			"go depth 1",
+		)
+
+		assert.Equal(t, []string{"32"}, hash)
+		assert.Equal(t, "bqnbrkrn/pppppppp/8/8/8/8/PPPPPPPP/BQNBRKRN w KQkq - 0 1", f.searches[0].fen)
+	})
+
+	t.Run("Debug mode reports errors", func(t *testing.T) {
+		s := uci.NewServer("Gopher", "", &fakeSearcher{},
+			uci.WithOption(uci.Option{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 64}, func(string) error {
+				return errors.New("too big")
+			}),
+		)
+
+		out := serve(t, s,
+			"position startpos moves e2e5",
+			"debug on",
+			"position startpos moves e2e5",
+			"setoption name Hash value 1000",
+			"foo",
+		)
+
+		require.Len(t, out, 3)
+		assert.True(t, strings.HasPrefix(out[0], "info string invalid move e2e5"))
+		assert.Equal(t, "info string failed to set option Hash: too big", out[1])
+		assert.Equal(t, "info string unknown command: foo", out[2])
+	})
+
+	t.Run("Engine client", func(t *testing.T) {
+		serverR, clientW := io.Pipe()
+		clientR, serverW := io.Pipe()
+		s := uci.NewServer("Gopher", "Gophers", &fakeSearcher{})
+
+		served := make(chan error, 1)
+		go func() {
+			served <- s.Serve(context.Background(), serverR, serverW)
+			serverW.Close()
+		}()
+
+		e, err := uci.NewEngine(context.Background(), struct {
+			io.Reader
+			io.WriteCloser
+		}{clientR, clientW})
+		require.NoError(t, err)
+		assert.Equal(t, "Gopher", e.Name)
+
+		require.NoError(t, e.NewGame(context.Background()))
+		require.NoError(t, e.Position("", "e2e4"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		result, err := e.Go(ctx, uci.Limits{Infinite: true}, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "a7a6", result.BestMove)
+
+		require.NoError(t, e.Close())
+		assert.NoError(t, <-served)
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		limits := uci.Limits{
+			SearchMoves:    []string{"e2e4", "e7e8q"},
+			Ponder:         true,
+			WhiteTime:      time.Minute,
+			BlackTime:      time.Second,
+			Clock:          true,
+			WhiteIncrement: 2 * time.Second,
+			BlackIncrement: 3 * time.Second,
+			MovesToGo:      10,
+			Depth:          8,
+			Nodes:          5000,
+			Mate:           2,
+			MoveTime:       250 * time.Millisecond,
+			Infinite:       true,
+		}
+
+		parsed, err := uci.ParseLimits(strings.Fields(limits.String()))
+		require.NoError(t, err)
+		assert.Equal(t, limits, parsed)
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		for _, args := range []string{"depth", "depth -5", "wtime x", "nodes x"} {
+			_, err := uci.ParseLimits(strings.Fields(args))
+			assert.Error(t, err, args)
+		}
+	})
+
+	t.Run("Negative times", func(t *testing.T) {
+		parsed, err := uci.ParseLimits(strings.Fields("wtime -50 btime 1000 winc -10"))
+		require.NoError(t, err)
+		assert.Equal(t, uci.Limits{BlackTime: time.Second, Clock: true}, parsed)
+	})
+}
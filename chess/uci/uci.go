@@ -0,0 +1,444 @@
+// Package uci implements the Universal Chess Interface protocol, used by
+// chess engines and graphical interfaces to talk to each other.
+//
+// Engine drives an external engine, started as a subprocess or reached
+// through any io.ReadWriter. Server runs the engine side of the protocol on
+// top of a Searcher, so an engine only has to choose moves.
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits are the limits of a search, sent with the go command.
+//
+// Zero values are not sent, except the times of the players when Clock is
+// set.
+type Limits struct {
+	// SearchMoves restricts the search to the given moves, in UCI notation.
+	SearchMoves []string
+	// Ponder starts the search in pondering mode.
+	Ponder bool
+	// WhiteTime and BlackTime are the remaining times of the players.
+	WhiteTime, BlackTime time.Duration
+	// Clock is true if the search is played with a clock, even if the time
+	// of the side to move is zero or overdrawn.
+	Clock bool
+	// WhiteIncrement and BlackIncrement are the increments per move.
+	WhiteIncrement, BlackIncrement time.Duration
+	// MovesToGo is the number of moves to the next time control.
+	MovesToGo int
+	// Depth is the maximum depth of the search, in plies.
+	Depth int
+	// Nodes is the maximum number of nodes to search.
+	Nodes uint64
+	// Mate searches for a mate in the given number of moves.
+	Mate int
+	// MoveTime is the exact time to search.
+	MoveTime time.Duration
+	// Infinite searches until the stop command.
+	Infinite bool
+}
+
+// String returns the arguments of the go command for the limits
+// (e.g. "wtime 60000 btime 60000 movestogo 40").
+func (l Limits) String() string {
+	var args []string
+	add := func(name string, value int64) {
+		if value > 0 {
+			args = append(args, name, strconv.FormatInt(value, 10))
+		}
+	}
+
+	if l.Ponder {
+		args = append(args, "ponder")
+	}
+	if l.Clock {
+		args = append(args,
+			"wtime", strconv.FormatInt(max(l.WhiteTime.Milliseconds(), 0), 10),
+			"btime", strconv.FormatInt(max(l.BlackTime.Milliseconds(), 0), 10))
+	} else {
+		add("wtime", l.WhiteTime.Milliseconds())
+		add("btime", l.BlackTime.Milliseconds())
+	}
+	add("winc", l.WhiteIncrement.Milliseconds())
+	add("binc", l.BlackIncrement.Milliseconds())
+	add("movestogo", int64(l.MovesToGo))
+	add("depth", int64(l.Depth))
+	add("nodes", int64(l.Nodes))
+	add("mate", int64(l.Mate))
+	add("movetime", l.MoveTime.Milliseconds())
+	if l.Infinite {
+		args = append(args, "infinite")
+	}
+	if len(l.SearchMoves) > 0 {
+		args = append(args, "searchmoves")
+		args = append(args, l.SearchMoves...)
+	}
+
+	return strings.Join(args, " ")
+}
+
+// Score is the evaluation of a position from the point of view of the side
+// to move.
+type Score struct {
+	// CP is the score in centipawns. It is 0 for mate scores.
+	CP int
+	// Mate is the number of moves to mate, negative if the side to move is
+	// getting mated. It is only meaningful if IsMate is true.
+	Mate int
+	// IsMate is true if the score is a mate score.
+	IsMate bool
+	// LowerBound and UpperBound are true if the score is only a bound.
+	LowerBound, UpperBound bool
+}
+
+// String returns the score as written in an info line (e.g. "cp 35" or
+// "mate -3").
+func (s Score) String() string {
+	str := "cp " + strconv.Itoa(s.CP)
+	if s.IsMate {
+		str = "mate " + strconv.Itoa(s.Mate)
+	}
+
+	switch {
+	case s.LowerBound:
+		str += " lowerbound"
+	case s.UpperBound:
+		str += " upperbound"
+	}
+
+	return str
+}
+
+// WDL are the win, draw and loss probabilities of the side to move, in
+// permille.
+type WDL struct {
+	Win, Draw, Loss int
+}
+
+// Info is the information an engine sends about its search.
+//
+// Fields that were not sent are left with their zero values.
+type Info struct {
+	// Depth is the depth of the search, in plies.
+	Depth int
+	// SelDepth is the selective depth of the search, in plies.
+	SelDepth int
+	// MultiPV is the number of the line when searching several lines,
+	// starting at 1.
+	MultiPV int
+	// Score is the evaluation of the line, or nil if it was not sent.
+	Score *Score
+	// WDL are the win, draw and loss probabilities, or nil if they were not
+	// sent.
+	WDL *WDL
+	// PV is the principal variation, in UCI notation.
+	PV []string
+	// Nodes is the number of nodes searched.
+	Nodes uint64
+	// NPS is the number of nodes searched per second.
+	NPS uint64
+	// Time is the time searched.
+	Time time.Duration
+	// HashFull is the usage of the hash table, in permille.
+	HashFull int
+	// TBHits is the number of tablebase hits.
+	TBHits uint64
+	// CurrMove is the move being searched, and CurrMoveNumber its number.
+	CurrMove       string
+	CurrMoveNumber int
+	// Text is the free text of an info string line.
+	Text string
+}
+
+// infoKeywords are the keywords of the info lines. They end the list of
+// moves of the pv.
+var infoKeywords = map[string]bool{
+	"depth": true, "seldepth": true, "time": true, "nodes": true, "pv": true,
+	"multipv": true, "score": true, "currmove": true, "currmovenumber": true,
+	"hashfull": true, "nps": true, "tbhits": true, "sbhits": true,
+	"cpuload": true, "string": true, "refutation": true, "currline": true,
+	"wdl": true,
+}
+
+// ParseInfo parses an info line sent by an engine (e.g.
+// "info depth 12 score cp 35 pv e2e4 e7e5").
+func ParseInfo(line string) (Info, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "info" {
+		return Info{}, fmt.Errorf("not an info line: %q", line)
+	}
+
+	var info Info
+	p := fieldParser{fields: fields[1:]}
+	for !p.done() {
+		switch keyword := p.next(); keyword {
+		case "depth":
+			info.Depth = p.int()
+		case "seldepth":
+			info.SelDepth = p.int()
+		case "multipv":
+			info.MultiPV = p.int()
+		case "nodes":
+			info.Nodes = p.uint()
+		case "nps":
+			info.NPS = p.uint()
+		case "tbhits":
+			info.TBHits = p.uint()
+		case "hashfull":
+			info.HashFull = p.int()
+		case "time":
+			info.Time = time.Duration(p.int()) * time.Millisecond
+		case "currmove":
+			info.CurrMove = p.next()
+		case "currmovenumber":
+			info.CurrMoveNumber = p.int()
+		case "pv":
+			info.PV = p.moves()
+		case "score":
+			info.Score = p.score()
+		case "wdl":
+			info.WDL = &WDL{Win: p.int(), Draw: p.int(), Loss: p.int()}
+		case "string":
+			info.Text = strings.Join(p.rest(), " ")
+		case "refutation", "currline":
+			p.moves()
+		default:
+			// Unknown keywords and the values of the ignored ones (sbhits,
+			// cpuload) are skipped.
+		}
+	}
+
+	if p.err != nil {
+		return Info{}, fmt.Errorf("invalid info line %q: %w", line, p.err)
+	}
+
+	return info, nil
+}
+
+// String returns the info as an info line.
+func (i Info) String() string {
+	var parts []string
+	add := func(name string, value uint64) {
+		if value > 0 {
+			parts = append(parts, name, strconv.FormatUint(value, 10))
+		}
+	}
+
+	add("depth", uint64(i.Depth))
+	add("seldepth", uint64(i.SelDepth))
+	add("multipv", uint64(i.MultiPV))
+	if i.Score != nil {
+		parts = append(parts, "score", i.Score.String())
+	}
+	if i.WDL != nil {
+		parts = append(parts, "wdl", fmt.Sprintf("%d %d %d", i.WDL.Win, i.WDL.Draw, i.WDL.Loss))
+	}
+	add("nodes", i.Nodes)
+	add("nps", i.NPS)
+	add("hashfull", uint64(i.HashFull))
+	add("tbhits", i.TBHits)
+	add("time", uint64(i.Time.Milliseconds()))
+	if i.CurrMove != "" {
+		parts = append(parts, "currmove", i.CurrMove)
+	}
+	add("currmovenumber", uint64(i.CurrMoveNumber))
+	if len(i.PV) > 0 {
+		parts = append(parts, "pv")
+		parts = append(parts, i.PV...)
+	}
+	if i.Text != "" {
+		parts = append(parts, "string", i.Text)
+	}
+
+	return "info " + strings.Join(parts, " ")
+}
+
+// fieldParser reads the fields of a command, keeping the first error.
+type fieldParser struct {
+	fields []string
+	err    error
+}
+
+// done returns true if there are no more fields.
+func (p *fieldParser) done() bool {
+	return len(p.fields) == 0
+}
+
+// next returns the next field, or an empty string if there are no more
+// fields.
+func (p *fieldParser) next() string {
+	if p.done() {
+		if p.err == nil {
+			p.err = fmt.Errorf("missing value")
+		}
+		return ""
+	}
+
+	field := p.fields[0]
+	p.fields = p.fields[1:]
+	return field
+}
+
+// int returns the next field as an integer.
+func (p *fieldParser) int() int {
+	field := p.next()
+	n, err := strconv.Atoi(field)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid number %q", field)
+	}
+
+	return n
+}
+
+// uint returns the next field as an unsigned integer.
+func (p *fieldParser) uint() uint64 {
+	field := p.next()
+	n, err := strconv.ParseUint(field, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("invalid number %q", field)
+	}
+
+	return n
+}
+
+// moves returns the fields up to the next info keyword.
+func (p *fieldParser) moves() []string {
+	var moves []string
+	for !p.done() && !infoKeywords[p.fields[0]] {
+		moves = append(moves, p.next())
+	}
+
+	return moves
+}
+
+// score returns the score that follows the score keyword.
+func (p *fieldParser) score() *Score {
+	var s Score
+	switch kind := p.next(); kind {
+	case "cp":
+		s.CP = p.int()
+	case "mate":
+		s.Mate, s.IsMate = p.int(), true
+	default:
+		if p.err == nil {
+			p.err = fmt.Errorf("invalid score type %q", kind)
+		}
+	}
+
+	for !p.done() {
+		switch p.fields[0] {
+		case "lowerbound":
+			s.LowerBound = true
+		case "upperbound":
+			s.UpperBound = true
+		default:
+			return &s
+		}
+		p.next()
+	}
+
+	return &s
+}
+
+// rest returns the remaining fields.
+func (p *fieldParser) rest() []string {
+	rest := p.fields
+	p.fields = nil
+	return rest
+}
+
+// Option is an option declared by an engine.
+type Option struct {
+	// Name is the name of the option.
+	Name string
+	// Type is the type of the option: "check", "spin", "combo", "button"
+	// or "string".
+	Type string
+	// Default is the default value of the option.
+	Default string
+	// Min and Max are the limits of a spin option.
+	Min, Max int
+	// Vars are the values of a combo option.
+	Vars []string
+}
+
+// ParseOption parses an option line sent by an engine (e.g.
+// "option name Hash type spin default 16 min 1 max 1024").
+func ParseOption(line string) (Option, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "option" {
+		return Option{}, fmt.Errorf("not an option line: %q", line)
+	}
+
+	// Values may have spaces, so every value lasts until the next keyword.
+	keywords := map[string]bool{"name": true, "type": true, "default": true, "min": true, "max": true, "var": true}
+	var o Option
+	for i := 1; i < len(fields); {
+		keyword := fields[i]
+		j := i + 1
+		for j < len(fields) && !keywords[fields[j]] {
+			j++
+		}
+		value := strings.Join(fields[i+1:j], " ")
+
+		var err error
+		switch keyword {
+		case "name":
+			o.Name = value
+		case "type":
+			o.Type = value
+		case "default":
+			o.Default = value
+		case "min":
+			o.Min, err = strconv.Atoi(value)
+		case "max":
+			o.Max, err = strconv.Atoi(value)
+		case "var":
+			o.Vars = append(o.Vars, value)
+		default:
+			err = fmt.Errorf("unexpected %q", keyword)
+		}
+		if err != nil {
+			return Option{}, fmt.Errorf("invalid option line %q: %w", line, err)
+		}
+
+		i = j
+	}
+
+	if o.Name == "" || o.Type == "" {
+		return Option{}, fmt.Errorf("invalid option line %q: missing name or type", line)
+	}
+
+	return o, nil
+}
+
+// String returns the option as an option line.
+func (o Option) String() string {
+	s := "option name " + o.Name + " type " + o.Type
+	switch o.Type {
+	case "button":
+		return s
+	case "string":
+		value := o.Default
+		if value == "" {
+			value = "<empty>"
+		}
+		s += " default " + value
+	default:
+		s += " default " + o.Default
+	}
+
+	if o.Type == "spin" {
+		s += fmt.Sprintf(" min %d max %d", o.Min, o.Max)
+	}
+	for _, v := range o.Vars {
+		s += " var " + v
+	}
+
+	return s
+}
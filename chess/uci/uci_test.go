@@ -0,0 +1,126 @@
+package uci_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInfo(t *testing.T) {
+	t.Run("Search info", func(t *testing.T) {
+		info, err := uci.ParseInfo("info depth 12 seldepth 18 multipv 2 score cp -35 upperbound wdl 120 700 180 " +
+			"nodes 123456 nps 1000000 hashfull 42 tbhits 3 time 123 pv e2e4 e7e5 g1f3")
+		require.NoError(t, err)
+
+		assert.Equal(t, uci.Info{
+			Depth:    12,
+			SelDepth: 18,
+			MultiPV:  2,
+			Score:    &uci.Score{CP: -35, UpperBound: true},
+			WDL:      &uci.WDL{Win: 120, Draw: 700, Loss: 180},
+			Nodes:    123456,
+			NPS:      1000000,
+			HashFull: 42,
+			TBHits:   3,
+			Time:     123 * time.Millisecond,
+			PV:       []string{"e2e4", "e7e5", "g1f3"},
+		}, info)
+	})
+
+	t.Run("Mate score", func(t *testing.T) {
+		info, err := uci.ParseInfo("info depth 5 score mate -3 pv e1e2 d8e7")
+		require.NoError(t, err)
+
+		assert.Equal(t, &uci.Score{Mate: -3, IsMate: true}, info.Score)
+		assert.Equal(t, []string{"e1e2", "d8e7"}, info.PV)
+	})
+
+	t.Run("Current move and string", func(t *testing.T) {
+		info, err := uci.ParseInfo("info currmove e2e4 currmovenumber 1 string hello engine world")
+		require.NoError(t, err)
+
+		assert.Equal(t, "e2e4", info.CurrMove)
+		assert.Equal(t, 1, info.CurrMoveNumber)
+		assert.Equal(t, "hello engine world", info.Text)
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		line := "info depth 10 seldepth 14 score mate 2 lowerbound nodes 500 time 7 pv a2a4 a7a5"
+
+		info, err := uci.ParseInfo(line)
+		require.NoError(t, err)
+		assert.Equal(t, line, info.String())
+	})
+
+	t.Run("Invalid lines", func(t *testing.T) {
+		for _, line := range []string{"bestmove e2e4", "info depth x", "info score", "info score foo 3"} {
+			_, err := uci.ParseInfo(line)
+			assert.Error(t, err, line)
+		}
+	})
+}
+
+func TestParseOption(t *testing.T) {
+	t.Run("Options", func(t *testing.T) {
+		tests := []struct {
+			line   string
+			option uci.Option
+		}{
+			{
+				"option name Hash type spin default 16 min 1 max 1024",
+				uci.Option{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 1024},
+			},
+			{
+				"option name Clear Hash type button",
+				uci.Option{Name: "Clear Hash", Type: "button"},
+			},
+			{
+				"option name Style type combo default Normal var Solid var Normal var Risky",
+				uci.Option{Name: "Style", Type: "combo", Default: "Normal", Vars: []string{"Solid", "Normal", "Risky"}},
+			},
+			{
+				"option name UCI_Chess960 type check default false",
+				uci.Option{Name: "UCI_Chess960", Type: "check", Default: "false"},
+			},
+		}
+
+		for _, tt := range tests {
+			option, err := uci.ParseOption(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.option, option)
+			assert.Equal(t, tt.line, option.String())
+		}
+	})
+
+	t.Run("Invalid lines", func(t *testing.T) {
+		for _, line := range []string{"id name Foo", "option name Hash", "option name Hash type spin min x"} {
+			_, err := uci.ParseOption(line)
+			assert.Error(t, err, line)
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	limits := uci.Limits{
+		SearchMoves:    []string{"e2e4", "d2d4"},
+		Ponder:         true,
+		WhiteTime:      time.Minute,
+		BlackTime:      50 * time.Second,
+		WhiteIncrement: time.Second,
+		BlackIncrement: time.Second,
+		MovesToGo:      20,
+		Depth:          12,
+		Nodes:          1000,
+		Mate:           3,
+		MoveTime:       500 * time.Millisecond,
+		Infinite:       true,
+	}
+
+	assert.Equal(t, "ponder wtime 60000 btime 50000 winc 1000 binc 1000 movestogo 20 depth 12 "+
+		"nodes 1000 mate 3 movetime 500 infinite searchmoves e2e4 d2d4", limits.String())
+	assert.Empty(t, uci.Limits{}.String())
+	assert.Equal(t, "wtime 0 btime 0", uci.Limits{WhiteTime: -time.Second, Clock: true}.String())
+}
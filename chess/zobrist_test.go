@@ -0,0 +1,78 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/RchrdHndrcks/gochess/v2/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	t.Run("Polyglot keys", func(t *testing.T) {
+		// Test vectors of the Polyglot book format specification.
+		tests := []struct {
+			moves []string
+			hash  uint64
+		}{
+			{nil, 0x463b96181691fc9c},
+			{[]string{"e2e4"}, 0x823c9b50fd114196},
+			{[]string{"e2e4", "d7d5"}, 0x0756b94461c50fb0},
+			{[]string{"e2e4", "d7d5", "e4e5"}, 0x662fafb965db29d4},
+			{[]string{"e2e4", "d7d5", "e4e5", "f7f5"}, 0x22a48b5a8e47ff78},
+			{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2"}, 0x652a607ca3f242c1},
+			{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2", "e8f7"}, 0x00fdd303c946bdd9},
+			{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4"}, 0x3c8123ea7b067637},
+			{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4", "b4c3", "a1a3"}, 0x5c3f9b829b279560},
+		}
+
+		for _, tt := range tests {
+			c, err := chess.New()
+			require.NoError(t, err)
+			for _, m := range tt.moves {
+				require.NoError(t, c.MakeMove(m))
+			}
+
+			assert.Equal(t, tt.hash, c.Hash(), tt.moves)
+
+			// The incremental hash matches the hash of the loaded position.
+			loaded, err := chess.New(chess.WithFEN(c.FEN()))
+			require.NoError(t, err)
+			assert.Equal(t, tt.hash, loaded.Hash(), c.FEN())
+		}
+	})
+
+	t.Run("Unmake move restores the hash", func(t *testing.T) {
+		c, err := chess.New(chess.WithFEN("r3k2r/pPpp1ppp/8/3Pp3/8/8/PPP2PPP/R3K2R w KQkq e6 0 1"))
+		require.NoError(t, err)
+		hash := c.Hash()
+
+		for _, m := range c.AvailableMoves() {
+			require.NoError(t, c.MakeMove(m))
+			loaded, err := chess.New(chess.WithFEN(c.FEN()))
+			require.NoError(t, err)
+			assert.Equal(t, loaded.Hash(), c.Hash(), m)
+
+			c.UnmakeMove()
+			assert.Equal(t, hash, c.Hash(), m)
+		}
+	})
+
+	t.Run("En passant square without capturing pawns", func(t *testing.T) {
+		withSquare, err := chess.New(chess.WithFEN("4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1"))
+		require.NoError(t, err)
+		withoutSquare, err := chess.New(chess.WithFEN("4k3/8/8/8/4P3/8/8/4K3 b - - 0 1"))
+		require.NoError(t, err)
+
+		assert.Equal(t, withoutSquare.Hash(), withSquare.Hash())
+	})
+
+	t.Run("Chess960 castling rights", func(t *testing.T) {
+		c, err := chess.New(chess.WithChess960Position(0))
+		require.NoError(t, err)
+		noCastles, err := chess.New(chess.WithChess960(), chess.WithFEN("bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w - - 0 1"))
+		require.NoError(t, err)
+
+		assert.NotEqual(t, noCastles.Hash(), c.Hash())
+	})
+}
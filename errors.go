@@ -10,3 +10,6 @@ var ErrInvalidSquare = errors.New("invalid square")
 
 // ErrInvalidCoordinate is returned when a coordinate is out of bounds.
 var ErrInvalidCoordinate = errors.New("invalid coordinate")
+
+// ErrInvalidPiece is returned when a piece is not a valid colored piece.
+var ErrInvalidPiece = errors.New("invalid piece")